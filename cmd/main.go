@@ -4,10 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"notes-project/internal/mailer"
 	"notes-project/internal/metrics"
+	"notes-project/internal/migrations"
+	"notes-project/internal/storage"
 	"notes-project/internal/ws"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"notes-project/internal/handlers"
@@ -35,33 +42,29 @@ import (
 // @in              header
 // @name            Authorization
 func main() {
-
-	dbHost := env("DB_HOST", "localhost")
-	dbPort := env("DB_PORT", "5433")
-	dbUser := env("DB_USER", "notes_user")
-	dbPassword := env("DB_PASSWORD", "notes_password")
-	dbName := env("DB_NAME", "notes_db")
-	sslMode := env("DB_SSLMODE", "disable")
-
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, dbName, sslMode,
-	)
-
-	db, err := sqlx.Connect("postgres", dsn)
-	if err != nil {
-		log.Fatalf("cannot connect to DB: %v", err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		runAdmin(os.Args[2:])
+		return
 	}
-	defer db.Close()
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db := connectDB()
+	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("db ping failed: %v", err)
+	if env("MIGRATE_ON_START", "true") == "true" {
+		migrator, err := migrations.NewMigrator(db)
+		if err != nil {
+			log.Fatalf("cannot load migrations: %v", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatalf("migrations failed: %v", err)
+		}
+		log.Printf("Applied %d migration(s)", len(applied))
 	}
-	log.Println("Connected to DB")
 
 	redisAddr := env("REDIS_ADDR", "localhost:6380")
 	rdb := redis.NewClient(&redis.Options{
@@ -84,20 +87,134 @@ func main() {
 	boardRepo := repository.NewBoardRepository(db)
 	listRepo := repository.NewListRepository(db)
 	cardRepo := repository.NewCardRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	labelRepo := repository.NewLabelRepository(db)
+	assigneeRepo := repository.NewAssigneeRepository(db)
+	checklistRepo := repository.NewChecklistRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	boardImportRepo := repository.NewBoardImportRepository(db)
+	cardExportRepo := repository.NewCardExportRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	accountTokenRepo := repository.NewAccountTokenRepository(db)
+
+	permissions := service.NewBoardPermissions(boardRepo)
+	cacheInvalidator := service.NewCacheInvalidator(rdb)
+	jwtSecret := []byte(os.Getenv("JWT_SECRET_KEY"))
+	appMailer := newMailer()
+	appBaseURL := env("APP_BASE_URL", "http://localhost:8080")
+	// Сервисы рассылают события через обёртку: она дублирует их в очередь вебхуков.
+	broadcaster := service.NewWebhookBroadcaster(hub, webhookRepo)
 
-	userService := service.NewUserService(userRepo)
-	boardService := service.NewBoardService(boardRepo, listRepo, cardRepo, userRepo, hub, rdb)
-	cacheInvalidator := boardService.InvalidateBoardCache
-	listService := service.NewListService(listRepo, boardRepo, hub, cacheInvalidator)
-	cardService := service.NewCardService(cardRepo, listRepo, boardRepo, hub, cacheInvalidator)
+	// Журнал действий создаётся первым: остальные сервисы пишут в него.
+	activityService := service.NewActivityService(activityRepo, cardRepo, listRepo, permissions, broadcaster)
+	invitationService := service.NewInvitationService(invitationRepo, boardRepo, userRepo, permissions, appMailer,
+		activityService, broadcaster, cacheInvalidator, jwtSecret, appBaseURL)
+	accessTokenTTL, err := time.ParseDuration(env("ACCESS_TOKEN_TTL", "15m"))
+	if err != nil || accessTokenTTL <= 0 {
+		log.Fatalf("invalid ACCESS_TOKEN_TTL: %q", os.Getenv("ACCESS_TOKEN_TTL"))
+	}
+	refreshTokenTTL, err := time.ParseDuration(env("REFRESH_TOKEN_TTL", "720h"))
+	if err != nil || refreshTokenTTL <= accessTokenTTL {
+		log.Fatalf("invalid REFRESH_TOKEN_TTL: %q, must be longer than ACCESS_TOKEN_TTL", os.Getenv("REFRESH_TOKEN_TTL"))
+	}
+	tokenService := service.NewTokenService(refreshTokenRepo, service.NewRedisTokenDenylist(rdb), jwtSecret,
+		accessTokenTTL, refreshTokenTTL)
+	accountService := service.NewAccountService(userRepo, accountTokenRepo, tokenService, invitationService, appMailer, appBaseURL)
+	blobStore := newBlobStore()
+	userService := service.NewUserService(userRepo, accountService, tokenService, blobStore, broadcaster, cacheInvalidator)
+	boardService := service.NewBoardService(boardRepo, listRepo, cardRepo, labelRepo, assigneeRepo, checklistRepo,
+		userRepo, permissions, invitationService, activityService, broadcaster, rdb)
+	listService := service.NewListService(listRepo, permissions, activityService, broadcaster, cacheInvalidator)
+	cardService := service.NewCardService(cardRepo, listRepo, permissions, activityService, broadcaster, cacheInvalidator)
+	commentService := service.NewCommentService(commentRepo, cardRepo, listRepo, permissions, activityService, broadcaster)
+	labelService := service.NewLabelService(labelRepo, cardRepo, listRepo, permissions, activityService, broadcaster, cacheInvalidator)
+	assigneeService := service.NewAssigneeService(assigneeRepo, boardRepo, cardRepo, listRepo, permissions, activityService, broadcaster, cacheInvalidator)
+	searchService := service.NewSearchService(searchRepo)
+	boardExportService := service.NewBoardExportService(boardRepo, listRepo, cardRepo, labelRepo, boardImportRepo,
+		permissions, invitationService, activityService)
+	cardExportService := service.NewCardExportService(cardExportRepo, permissions)
+	webhookService := service.NewWebhookService(webhookRepo, permissions)
+	checklistService := service.NewChecklistService(checklistRepo, cardRepo, listRepo, permissions, activityService, broadcaster, cacheInvalidator)
+
+	attachmentMaxBytes, err := strconv.ParseInt(env("ATTACHMENT_MAX_BYTES", "10485760"), 10, 64)
+	if err != nil || attachmentMaxBytes <= 0 {
+		log.Fatalf("invalid ATTACHMENT_MAX_BYTES: %q", os.Getenv("ATTACHMENT_MAX_BYTES"))
+	}
+	attachmentLimits := service.AttachmentLimits{
+		MaxBytes:     attachmentMaxBytes,
+		AllowedTypes: strings.Split(env("ATTACHMENT_ALLOWED_TYPES", "image/,application/pdf,text/plain,application/zip"), ","),
+	}
+	attachmentService := service.NewAttachmentService(attachmentRepo, blobStore, cardRepo, listRepo, permissions, broadcaster, attachmentLimits)
+
+	rebalanceInterval, err := time.ParseDuration(env("REBALANCE_INTERVAL", "10m"))
+	if err != nil {
+		log.Fatalf("invalid REBALANCE_INTERVAL: %v", err)
+	}
+	rebalancer := service.NewPositionRebalancer(cardRepo, listRepo, cacheInvalidator, rebalanceInterval)
+	go rebalancer.Run(context.Background())
+
+	dueCheckInterval, err := time.ParseDuration(env("DUE_CHECK_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("invalid DUE_CHECK_INTERVAL: %v", err)
+	}
+	dueSoonWindow, err := time.ParseDuration(env("DUE_SOON_WINDOW", "24h"))
+	if err != nil {
+		log.Fatalf("invalid DUE_SOON_WINDOW: %v", err)
+	}
+	dueScheduler := service.NewDueDateScheduler(cardRepo, broadcaster, dueCheckInterval, dueSoonWindow)
+	go dueScheduler.Run(context.Background())
+
+	archivePurgeInterval, err := time.ParseDuration(env("ARCHIVE_PURGE_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("invalid ARCHIVE_PURGE_INTERVAL: %v", err)
+	}
+	// Нулевой срок хранения удалял бы архив сразу, то есть возвращал бы жёсткое удаление.
+	archiveRetention, err := time.ParseDuration(env("ARCHIVE_RETENTION", "720h"))
+	if err != nil || archiveRetention <= 0 {
+		log.Fatalf("invalid ARCHIVE_RETENTION: %q", os.Getenv("ARCHIVE_RETENTION"))
+	}
+	archivePurger := service.NewArchivePurger(boardRepo, listRepo, cardRepo, blobStore, archivePurgeInterval, archiveRetention)
+	go archivePurger.Run(context.Background())
+
+	webhookPollInterval, err := time.ParseDuration(env("WEBHOOK_POLL_INTERVAL", "5s"))
+	if err != nil {
+		log.Fatalf("invalid WEBHOOK_POLL_INTERVAL: %v", err)
+	}
+	webhookTimeout, err := time.ParseDuration(env("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil || webhookTimeout <= 0 {
+		log.Fatalf("invalid WEBHOOK_TIMEOUT: %q", os.Getenv("WEBHOOK_TIMEOUT"))
+	}
+	webhookMaxAttempts, err := strconv.Atoi(env("WEBHOOK_MAX_ATTEMPTS", "10"))
+	if err != nil || webhookMaxAttempts < 1 {
+		log.Fatalf("invalid WEBHOOK_MAX_ATTEMPTS: %q", os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	}
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, webhookTimeout, webhookPollInterval, webhookMaxAttempts)
+	go webhookDispatcher.Run(context.Background())
 
 	userHandler := handlers.NewUserHandler(userService)
+	accountHandler := handlers.NewAccountHandler(accountService)
 	boardHandler := handlers.NewBoardHandler(boardService)
 	listHandler := handlers.NewListHandler(listService)
 	cardHandler := handlers.NewCardHandler(cardService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	assigneeHandler := handlers.NewAssigneeHandler(assigneeService)
+	checklistHandler := handlers.NewChecklistHandler(checklistService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, attachmentLimits.MaxBytes)
+	activityHandler := handlers.NewActivityHandler(activityService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	boardExportHandler := handlers.NewBoardExportHandler(boardExportService)
+	cardExportHandler := handlers.NewCardExportHandler(cardExportService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
 	wsHandler := ws.NewWsHandler(hub, boardService)
 
-	router := setupRouter(userHandler, boardHandler, listHandler, cardHandler, wsHandler, appMetrics)
+	router := setupRouter(userHandler, accountHandler, boardHandler, boardExportHandler, cardExportHandler, listHandler, cardHandler, commentHandler, labelHandler, assigneeHandler, checklistHandler,
+		attachmentHandler, activityHandler, searchHandler, webhookHandler, invitationHandler, wsHandler, tokenService, accountService, appMetrics)
 
 	port := env("PORT", "8080")
 	addr := fmt.Sprintf(":%s", port)
@@ -109,10 +226,24 @@ func main() {
 
 func setupRouter(
 	userHandler *handlers.UserHandler,
+	accountHandler *handlers.AccountHandler,
 	boardHandler *handlers.BoardHandler,
+	boardExportHandler *handlers.BoardExportHandler,
+	cardExportHandler *handlers.CardExportHandler,
 	listHandler *handlers.ListHandler,
 	cardHandler *handlers.CardHandler,
+	commentHandler *handlers.CommentHandler,
+	labelHandler *handlers.LabelHandler,
+	assigneeHandler *handlers.AssigneeHandler,
+	checklistHandler *handlers.ChecklistHandler,
+	attachmentHandler *handlers.AttachmentHandler,
+	activityHandler *handlers.ActivityHandler,
+	searchHandler *handlers.SearchHandler,
+	webhookHandler *handlers.WebhookHandler,
+	invitationHandler *handlers.InvitationHandler,
 	wsHandler *ws.WsHandler,
+	auth service.AccessTokenAuthenticator,
+	verification service.EmailVerificationChecker,
 	appMetrics *metrics.AppMetrics,
 ) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
@@ -120,6 +251,7 @@ func setupRouter(
 
 	r.Use(handlers.MetricsMiddleware(appMetrics))
 	r.Use(gin.Recovery())
+	r.Use(handlers.ErrorMiddleware())
 	r.Use(func(c *gin.Context) {})
 
 	r.GET("/health", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) })
@@ -128,21 +260,199 @@ func setupRouter(
 
 	api := r.Group("/api")
 	{
-		userHandler.RegisterPublicRoutes(api.Group("/users"))
+		publicUsers := api.Group("/users")
+		userHandler.RegisterPublicRoutes(publicUsers)
+		accountHandler.RegisterAccountPublicRoutes(publicUsers)
 		protectedRoutes := api.Group("/")
-		protectedRoutes.Use(handlers.AuthMiddleware())
+		protectedRoutes.Use(handlers.AuthMiddleware(auth))
 		{
+			// Управление своим аккаунтом доступно и до подтверждения почты.
 			userHandler.RegisterProtectedRoutes(protectedRoutes)
-			boardHandler.RegisterBoardRoutes(protectedRoutes)
-			listHandler.RegisterListRoutes(protectedRoutes)
-			cardHandler.RegisterCardRoutes(protectedRoutes)
-			wsHandler.RegisterWsRoutes(protectedRoutes)
+			accountHandler.RegisterAccountRoutes(protectedRoutes)
+			verifiedRoutes := protectedRoutes.Group("/")
+			verifiedRoutes.Use(handlers.VerifiedEmailMiddleware(verification))
+			{
+				boardHandler.RegisterBoardRoutes(verifiedRoutes)
+				boardExportHandler.RegisterBoardExportRoutes(verifiedRoutes)
+				cardExportHandler.RegisterCardExportRoutes(verifiedRoutes)
+				listHandler.RegisterListRoutes(verifiedRoutes)
+				cardHandler.RegisterCardRoutes(verifiedRoutes)
+				commentHandler.RegisterCommentRoutes(verifiedRoutes)
+				labelHandler.RegisterLabelRoutes(verifiedRoutes)
+				assigneeHandler.RegisterAssigneeRoutes(verifiedRoutes)
+				checklistHandler.RegisterChecklistRoutes(verifiedRoutes)
+				attachmentHandler.RegisterAttachmentRoutes(verifiedRoutes)
+				activityHandler.RegisterActivityRoutes(verifiedRoutes)
+				searchHandler.RegisterSearchRoutes(verifiedRoutes)
+				webhookHandler.RegisterWebhookRoutes(verifiedRoutes)
+				invitationHandler.RegisterInvitationRoutes(verifiedRoutes)
+				wsHandler.RegisterWsRoutes(verifiedRoutes)
+			}
 		}
 	}
 
 	return r
 }
 
+// newMailer выбирает реализацию почты по MAIL_DRIVER: smtp или log (по умолчанию).
+func newMailer() mailer.Mailer {
+	switch driver := env("MAIL_DRIVER", "log"); driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			env("SMTP_HOST", "localhost"),
+			env("SMTP_PORT", "587"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			env("SMTP_FROM", "no-reply@localhost"),
+		)
+	case "log":
+		return mailer.NewLogMailer()
+	default:
+		log.Fatalf("unknown MAIL_DRIVER %q, expected smtp or log", driver)
+		return nil
+	}
+}
+
+func connectDB() *sqlx.DB {
+	dbHost := env("DB_HOST", "localhost")
+	dbPort := env("DB_PORT", "5433")
+	dbUser := env("DB_USER", "notes_user")
+	dbPassword := env("DB_PASSWORD", "notes_password")
+	dbName := env("DB_NAME", "notes_db")
+	sslMode := env("DB_SSLMODE", "disable")
+
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		dbHost, dbPort, dbUser, dbPassword, dbName, sslMode,
+	)
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		log.Fatalf("cannot connect to DB: %v", err)
+	}
+
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(25)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("db ping failed: %v", err)
+	}
+	log.Println("Connected to DB")
+	return db
+}
+
+// runMigrate обрабатывает подкоманду `migrate up|down [N|all]|status`.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: migrate up|down [N|all]|status")
+	}
+
+	db := connectDB()
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("cannot load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("migrate up failed: %v", err)
+		}
+		for _, m := range applied {
+			log.Printf("applied %04d_%s", m.Version, m.Name)
+		}
+		log.Printf("Applied %d migration(s)", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = math.MaxInt
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps: %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("migrate down failed: %v", err)
+		}
+		for _, m := range reverted {
+			log.Printf("reverted %04d_%s", m.Version, m.Name)
+		}
+		log.Printf("Reverted %d migration(s)", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("migrate status failed: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		log.Fatalf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+}
+
+// runAdmin выдаёт или снимает флаг администратора системы: admin grant|revoke <email>.
+// Через API флаг меняют только сами администраторы, поэтому первого назначают отсюда.
+func runAdmin(args []string) {
+	if len(args) != 2 || (args[0] != "grant" && args[0] != "revoke") {
+		log.Fatal("usage: admin grant|revoke <email>")
+	}
+
+	db := connectDB()
+	defer db.Close()
+
+	ctx := context.Background()
+	userRepo := repository.NewUserRepository(db)
+	user, err := userRepo.GetByEmail(ctx, args[1])
+	if err != nil {
+		log.Fatalf("user %q not found: %v", args[1], err)
+	}
+	if err := userRepo.SetAdmin(ctx, user.ID, args[0] == "grant"); err != nil {
+		log.Fatalf("admin %s failed: %v", args[0], err)
+	}
+	log.Printf("admin %s: user %d (%s)", args[0], user.ID, user.Email)
+}
+
+// newBlobStore выбирает хранилище вложений по STORAGE_DRIVER: local (по умолчанию) или s3.
+func newBlobStore() storage.BlobStore {
+	switch driver := env("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		store, err := storage.NewLocalStore(env("STORAGE_LOCAL_DIR", "./data/attachments"))
+		if err != nil {
+			log.Fatalf("could not init local storage: %v", err)
+		}
+		return store
+	case "s3":
+		store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          env("S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}, &http.Client{Timeout: 5 * time.Minute})
+		if err != nil {
+			log.Fatalf("could not init s3 storage: %v", err)
+		}
+		return store
+	default:
+		log.Fatalf("unknown STORAGE_DRIVER %q, expected local or s3", driver)
+		return nil
+	}
+}
+
 func env(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,88 @@
+// Package apperr - ошибки предметной области. Вид ошибки определяет HTTP-статус,
+// Code и Message показываются клиенту, а причина (Cause) попадает только в лог.
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Виды ошибок. Проверяются через errors.Is: errors.Is(err, apperr.ErrNotFound).
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrTooLarge     = errors.New("payload too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+	ErrUnavailable  = errors.New("service unavailable")
+)
+
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Cause   error
+}
+
+func New(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, format string, args ...interface{}) *Error {
+	return New(ErrValidation, code, fmt.Sprintf(format, args...))
+}
+
+func Unauthorized(code, format string, args ...interface{}) *Error {
+	return New(ErrUnauthorized, code, fmt.Sprintf(format, args...))
+}
+
+func Forbidden(code, format string, args ...interface{}) *Error {
+	return New(ErrForbidden, code, fmt.Sprintf(format, args...))
+}
+
+func NotFound(code, format string, args ...interface{}) *Error {
+	return New(ErrNotFound, code, fmt.Sprintf(format, args...))
+}
+
+func Conflict(code, format string, args ...interface{}) *Error {
+	return New(ErrConflict, code, fmt.Sprintf(format, args...))
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Cause}
+}
+
+// Is сравнивает ошибки по виду и коду, а не по тексту: sentinel вроде ErrUserNotFound
+// совпадает и с ошибкой того же кода с подробным сообщением.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap возвращает копию ошибки с причиной; сам sentinel не меняется.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Cause = cause
+	return &wrapped
+}
+
+// NoRows заменяет sql.ErrNoRows из репозитория на notFound, остальные ошибки возвращает как есть.
+func NoRows(err error, notFound *Error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound.Wrap(err)
+	}
+	return err
+}
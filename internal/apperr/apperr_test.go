@@ -0,0 +1,35 @@
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_MatchesKindAndCode(t *testing.T) {
+	sentinel := NotFound("board_not_found", "board not found")
+	err := fmt.Errorf("loading board: %w", NotFound("board_not_found", "board with id %d not found", 7))
+
+	assert.ErrorIs(t, err, sentinel)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrForbidden)
+	assert.NotErrorIs(t, err, NotFound("card_not_found", "card not found"))
+	assert.NotErrorIs(t, err, Conflict("board_not_found", "board not found"))
+}
+
+func TestNoRows(t *testing.T) {
+	notFound := NotFound("card_not_found", "card not found")
+	dbErr := errors.New("connection refused")
+
+	err := NoRows(fmt.Errorf("cardRepository.GetByID: %w", sql.ErrNoRows), notFound)
+
+	assert.ErrorIs(t, err, notFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, "card not found", err.(*Error).Message)
+	assert.Nil(t, notFound.Cause, "sentinel must not be modified")
+	assert.Equal(t, dbErr, NoRows(dbErr, notFound))
+	assert.NoError(t, NoRows(nil, notFound))
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service service.AccountService
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func NewAccountHandler(s service.AccountService) *AccountHandler {
+	return &AccountHandler{service: s}
+}
+
+// RegisterAccountPublicRoutes - ссылки из писем работают без входа в аккаунт.
+func (h *AccountHandler) RegisterAccountPublicRoutes(rg *gin.RouterGroup) {
+	rg.POST("/verify-email", h.VerifyEmail)
+	rg.POST("/password/forgot", h.ForgotPassword)
+	rg.POST("/password/reset", h.ResetPassword)
+}
+
+func (h *AccountHandler) RegisterAccountRoutes(rg *gin.RouterGroup) {
+	rg.POST("/users/verify-email/resend", h.ResendVerification)
+	rg.POST("/users/me/password", h.ChangePassword)
+}
+
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var input VerifyEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+	if err := h.service.VerifyEmail(c.Request.Context(), input.Token); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.service.ResendVerification(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
+}
+
+// ChangePassword отвечает новой парой токенов: остальные сессии пользователя завершаются.
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var input ChangePasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+	tokens, err := h.service.ChangePassword(c.Request.Context(), userID, input.CurrentPassword, input.NewPassword)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// ForgotPassword всегда отвечает 202, зарегистрирован email или нет.
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var input ForgotPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+	if err := h.service.RequestPasswordReset(c.Request.Context(), input.Email); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var input ResetPasswordInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+	if err := h.service.ResetPassword(c.Request.Context(), input.Token, input.Password); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 100
+)
+
+type ActivityHandler struct {
+	service service.ActivityService
+}
+
+func NewActivityHandler(s service.ActivityService) *ActivityHandler {
+	return &ActivityHandler{service: s}
+}
+
+func (h *ActivityHandler) RegisterActivityRoutes(rg *gin.RouterGroup) {
+	rg.GET("/boards/:boardId/activity", h.ListBoardActivity)
+	rg.GET("/cards/:cardId/activity", h.ListCardActivity)
+}
+
+func (h *ActivityHandler) ListBoardActivity(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, ok := activityLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.ListForBoard(c.Request.Context(), boardID, userID, c.Query("cursor"), limit)
+	respondCursorPage(c, page, err)
+}
+
+func (h *ActivityHandler) ListCardActivity(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, ok := activityLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.ListForCard(c.Request.Context(), cardID, userID, c.Query("cursor"), limit)
+	respondCursorPage(c, page, err)
+}
+
+func activityLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultActivityLimit)))
+	if err != nil || limit < 1 || limit > maxActivityLimit {
+		c.Error(apperr.Validation("invalid_query", "limit must be between 1 and 100"))
+		return 0, false
+	}
+	return limit, true
+}
+
+// respondCursorPage отвечает страницей с курсором: журналом действий или журналом доставок вебхука.
+func respondCursorPage(c *gin.Context, page interface{}, err error) {
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
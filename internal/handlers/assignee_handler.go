@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AssigneeHandler struct {
+	service service.AssigneeService
+}
+
+type AssignInput struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+func NewAssigneeHandler(s service.AssigneeService) *AssigneeHandler {
+	return &AssigneeHandler{service: s}
+}
+
+func (h *AssigneeHandler) RegisterAssigneeRoutes(rg *gin.RouterGroup) {
+	cardAssignees := rg.Group("/cards/:cardId/assignees")
+	{
+		cardAssignees.POST("/", h.Assign)
+		cardAssignees.DELETE("/:userId", h.Unassign)
+	}
+
+	rg.GET("/users/me/cards", h.MyCards)
+}
+
+func (h *AssigneeHandler) Assign(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input AssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	assignees, err := h.service.Assign(c.Request.Context(), cardID, input.UserID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignees)
+}
+
+func (h *AssigneeHandler) Unassign(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	assigneeID, err := reqctx.PathInt(c, "userId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	assignees, err := h.service.Unassign(c.Request.Context(), cardID, assigneeID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignees)
+}
+
+func (h *AssigneeHandler) MyCards(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boards, err := h.service.CardsForUser(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, boards)
+}
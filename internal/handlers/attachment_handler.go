@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead - запас на заголовки multipart поверх максимального размера файла.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	service  service.AttachmentService
+	maxBytes int64
+}
+
+func NewAttachmentHandler(s service.AttachmentService, maxBytes int64) *AttachmentHandler {
+	return &AttachmentHandler{service: s, maxBytes: maxBytes}
+}
+
+func (h *AttachmentHandler) RegisterAttachmentRoutes(rg *gin.RouterGroup) {
+	cardAttachments := rg.Group("/cards/:cardId/attachments")
+	{
+		cardAttachments.GET("/", h.ListAttachments)
+		cardAttachments.POST("/", h.UploadAttachment)
+	}
+
+	attachments := rg.Group("/attachments")
+	{
+		attachments.GET("/:attachmentId/download", h.DownloadAttachment)
+		attachments.DELETE("/:attachmentId", h.DeleteAttachment)
+	}
+}
+
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	attachments, err := h.service.List(c.Request.Context(), cardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Error(service.ErrAttachmentTooLarge)
+			return
+		}
+		c.Error(apperr.Validation("file_required", "multipart field \"file\" is required"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(apperr.Validation("invalid_upload", "could not read uploaded file"))
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.Upload(c.Request.Context(), cardID, userID, fileHeader.Filename, fileHeader.Size, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment отдаёт файл через http.ServeContent, который сам обрабатывает Range и If-Modified-Since.
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	attachmentID, err := reqctx.PathInt(c, "attachmentId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	attachment, content, err := h.service.Open(c.Request.Context(), attachmentID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, attachment.Filename, attachment.CreatedAt, content)
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	attachmentID, err := reqctx.PathInt(c, "attachmentId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), attachmentID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted successfully"})
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes ограничивает размер импортируемого документа.
+const maxImportBytes = 20 << 20
+
+type BoardExportHandler struct {
+	service service.BoardExportService
+}
+
+func NewBoardExportHandler(s service.BoardExportService) *BoardExportHandler {
+	return &BoardExportHandler{service: s}
+}
+
+func (h *BoardExportHandler) RegisterBoardExportRoutes(rg *gin.RouterGroup) {
+	rg.GET("/boards/:boardId/export", h.ExportBoard)
+	rg.POST("/boards/import", h.ImportBoard)
+}
+
+// @Summary      Экспортировать доску
+// @Description  Возвращает версионированный JSON-документ с доской, списками, карточками, метками и участниками.
+// @Tags         Boards
+// @Produce      json
+// @Param        boardId           path      int   true   "ID Доски"
+// @Param        include_archived  query     bool  false  "Включить архивные списки и карточки"
+// @Success      200               {object}  models.BoardExport
+// @Failure      403               {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /boards/{boardId}/export [get]
+func (h *BoardExportHandler) ExportBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	includeArchived, ok := includeArchivedQuery(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.Export(c.Request.Context(), boardID, userID, includeArchived)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="board-%d.json"`, boardID))
+	c.JSON(http.StatusOK, export)
+}
+
+// @Summary      Импортировать доску
+// @Description  Создаёт новую доску из документа экспорта или из JSON-выгрузки Trello. Импорт выполняется одной транзакцией, участники из документа получают приглашения на email.
+// @Tags         Boards
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  models.BoardImportResult
+// @Failure      400  {object}  ErrorResponse
+// @Failure      413  {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /boards/import [post]
+func (h *BoardExportHandler) ImportBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	document, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Error(apperr.New(apperr.ErrTooLarge, "import_too_large", "import document is too large"))
+			return
+		}
+		c.Error(apperr.Validation(service.ErrInvalidImport.Code, "could not read import document"))
+		return
+	}
+
+	result, err := h.service.Import(c.Request.Context(), userID, document)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
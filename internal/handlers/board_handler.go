@@ -2,23 +2,30 @@ package handlers
 
 import (
 	"net/http"
+	"notes-project/internal/apperr"
 	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
 	"notes-project/internal/service"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
 type BoardHandler struct {
 	service service.BoardService
 }
 
 type AddMemberInput struct {
-	Email string `json:"email" binding:"required"`
+	Email string           `json:"email" binding:"required"`
+	Role  models.BoardRole `json:"role"`
+}
+
+type TransferBoardInput struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+type UpdateMemberRoleInput struct {
+	Role models.BoardRole `json:"role" binding:"required"`
 }
 
 func NewBoardHandler(s service.BoardService) *BoardHandler {
@@ -32,9 +39,15 @@ func (h *BoardHandler) RegisterBoardRoutes(rg *gin.RouterGroup) {
 		boards.GET("/", h.GetAllBoardsForUser)
 		boards.GET("/:boardId", h.GetBoardByID)
 		boards.PUT("/:boardId", h.UpdateBoard)
-		boards.DELETE("/:boardId", h.DeleteBoard)
+		boards.DELETE("/:boardId", h.ArchiveBoard)
+		boards.POST("/:boardId/restore", h.RestoreBoard)
 
+		boards.GET("/:boardId/members", h.GetBoardMembers)
 		boards.POST("/:boardId/members", h.AddMemberToBoard)
+		boards.PUT("/:boardId/members/:userId/role", h.UpdateMemberRole)
+		boards.DELETE("/:boardId/members/:userId", h.RemoveMemberFromBoard)
+		boards.POST("/:boardId/leave", h.LeaveBoard)
+		boards.POST("/:boardId/transfer", h.TransferBoard)
 	}
 }
 
@@ -51,21 +64,21 @@ func (h *BoardHandler) RegisterBoardRoutes(rg *gin.RouterGroup) {
 // @Router       /boards [post]
 
 func (h *BoardHandler) CreateBoard(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var input models.Board
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
 	// Теперь мы уверены, что userID не nil.
-	if err := h.service.Create(c.Request.Context(), &input, userID.(int)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create board"})
+	if err := h.service.Create(c.Request.Context(), &input, userID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -76,21 +89,26 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 // @Description  Возвращает список всех досок, где пользователь является владельцем или участником.
 // @Tags         Boards
 // @Produce      json
+// @Param        include_archived  query     bool  false  "Включить архивные доски"
 // @Success      200 {array}   models.Board
 // @Failure      401 {object}  ErrorResponse
 // @Security     ApiKeyAuth
 // @Router       /boards [get]
 func (h *BoardHandler) GetAllBoardsForUser(c *gin.Context) {
-	userID, exists := c.Get("userId")
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
 
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	includeArchived, ok := includeArchivedQuery(c)
+	if !ok {
 		return
 	}
 
-	boards, err := h.service.GetAllForUser(c.Request.Context(), userID.(int))
+	boards, err := h.service.GetAllForUser(c.Request.Context(), userID, includeArchived)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not fetch boards"})
+		c.Error(err)
 		return
 	}
 
@@ -101,43 +119,49 @@ func (h *BoardHandler) GetAllBoardsForUser(c *gin.Context) {
 // @Description  Возвращает полную информацию о доске, включая списки и карточки.
 // @Tags         Boards
 // @Produce      json
-// @Param        boardId  path      int  true  "ID Доски"
+// @Param        boardId           path      int   true   "ID Доски"
+// @Param        include_archived  query     bool  false  "Включить архивные списки и карточки"
 // @Success      200      {object}  models.Board
 // @Failure      401      {object}  ErrorResponse
 // @Failure      404      {object}  ErrorResponse
 // @Security     ApiKeyAuth
 // @Router       /boards/{boardId} [get]
 func (h *BoardHandler) GetBoardByID(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	boardID, err := strconv.Atoi(c.Param("boardId"))
+	boardID, err := reqctx.PathInt(c, "boardId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board id"})
+		c.Error(err)
+		return
+	}
+
+	includeArchived, ok := includeArchivedQuery(c)
+	if !ok {
 		return
 	}
 
-	board, err := h.service.GetByID(c.Request.Context(), boardID, userID.(int))
+	board, err := h.service.GetByID(c.Request.Context(), boardID, userID, includeArchived)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, board)
 }
 
 func (h *BoardHandler) UpdateBoard(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	boardID, err := strconv.Atoi(c.Param("boardId"))
+	boardID, err := reqctx.PathInt(c, "boardId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board id"})
+		c.Error(err)
 		return
 	}
 
@@ -145,59 +169,233 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 		Name string `json:"name" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
-	if err := h.service.Update(c.Request.Context(), boardID, userID.(int), input.Name); err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	if err := h.service.Update(c.Request.Context(), boardID, userID, input.Name); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "board updated successfully"})
 }
 
-func (h *BoardHandler) DeleteBoard(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+// ArchiveBoard обслуживает DELETE: доска уходит в архив и удаляется окончательно только после срока хранения.
+func (h *BoardHandler) ArchiveBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	boardID, err := strconv.Atoi(c.Param("boardId"))
+	boardID, err := reqctx.PathInt(c, "boardId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board id"})
+		c.Error(err)
 		return
 	}
 
-	if err := h.service.Delete(c.Request.Context(), boardID, userID.(int)); err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	if err := h.service.Archive(c.Request.Context(), boardID, userID); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "board deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "board archived successfully"})
+}
+
+func (h *BoardHandler) RestoreBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Restore(c.Request.Context(), boardID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "board restored successfully"})
+}
+
+// includeArchivedQuery разбирает ?include_archived; при ошибке ответ уже отправлен.
+func includeArchivedQuery(c *gin.Context) (bool, bool) {
+	includeArchived, err := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+	if err != nil {
+		c.Error(apperr.Validation("invalid_query", "include_archived must be a boolean"))
+		return false, false
+	}
+	return includeArchived, true
 }
 
 func (h *BoardHandler) AddMemberToBoard(c *gin.Context) {
-	inviterID, exists := c.Get("userId")
-	if !exists {
+	inviterID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	boardID, err := strconv.Atoi(c.Param("boardId"))
+	boardID, err := reqctx.PathInt(c, "boardId")
 	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	var input AddMemberInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		c.Error(invalidInput(err))
 		return
 	}
 
-	err = h.service.AddMember(c.Request.Context(), boardID, inviterID.(int), input.Email)
+	if input.Role == "" {
+		input.Role = models.RoleMember
+	}
+
+	invitation, err := h.service.AddMember(c.Request.Context(), boardID, inviterID, input.Email, input.Role)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	if invitation != nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "invitation sent", "invitation": invitation})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "member added successfully"})
 }
+
+func (h *BoardHandler) GetBoardMembers(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	members, err := h.service.GetMembers(c.Request.Context(), boardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *BoardHandler) UpdateMemberRole(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	memberID, err := reqctx.PathInt(c, "userId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input UpdateMemberRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	if err := h.service.UpdateMemberRole(c.Request.Context(), boardID, userID, memberID, input.Role); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member role updated successfully"})
+}
+
+func (h *BoardHandler) RemoveMemberFromBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	memberID, err := reqctx.PathInt(c, "userId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), boardID, userID, memberID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed successfully"})
+}
+
+func (h *BoardHandler) LeaveBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Leave(c.Request.Context(), boardID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "left the board successfully"})
+}
+
+func (h *BoardHandler) TransferBoard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input TransferBoardInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	if err := h.service.TransferOwnership(c.Request.Context(), boardID, userID, input.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "board transferred successfully"})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/models"
+	"notes-project/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBoardTestRouter(mockService *MockBoardService) *gin.Engine {
+	return newTestRouter(1, func(rg *gin.RouterGroup) {
+		NewBoardHandler(mockService).RegisterBoardRoutes(rg)
+	})
+}
+
+func TestBoardHandler_InvalidBoardID(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockBoardService)
+	r := newBoardTestRouter(mockService)
+
+	// --- ACT & ASSERT ---
+	for _, path := range []string{"/api/boards/abc", "/api/boards/0", "/api/boards/-3", "/api/boards/99999999999999999999"} {
+		w, resp := doRequest(r, http.MethodGet, path, "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		assert.Equal(t, "invalid_id", resp.Code, path)
+		assert.Equal(t, "invalid board id", resp.Error, path)
+	}
+	mockService.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBoardHandler_GetBoardByID_NotFound(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockBoardService)
+	r := newBoardTestRouter(mockService)
+	mockService.On("GetByID", mock.Anything, 7, 1, false).Return(nil, service.ErrBoardNotFound)
+
+	// --- ACT ---
+	w, resp := doRequest(r, http.MethodGet, "/api/boards/7", "")
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "board_not_found", resp.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBoardHandler_AddMemberToBoard_MalformedRequests(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockBoardService)
+	r := newBoardTestRouter(mockService)
+
+	// --- ACT & ASSERT ---
+	for _, tc := range []struct{ path, body, code string }{
+		{"/api/boards/x/members", `{"email": "bob@example.com"}`, "invalid_id"},
+		{"/api/boards/1/members", `{"email": `, "invalid_input"},
+		{"/api/boards/1/members", `{}`, "invalid_input"},
+	} {
+		w, resp := doRequest(r, http.MethodPost, tc.path, tc.body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc.body)
+		assert.Equal(t, tc.code, resp.Code, tc.body)
+	}
+	mockService.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBoardHandler_AddMemberToBoard_DefaultsToMemberRole(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockBoardService)
+	r := newBoardTestRouter(mockService)
+	mockService.On("AddMember", mock.Anything, 3, 1, "bob@example.com", models.RoleMember).Return(nil, nil).Once()
+
+	// --- ACT ---
+	w, _ := doRequest(r, http.MethodPost, "/api/boards/3/members", `{"email": "bob@example.com"}`)
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CardExportHandler struct {
+	service service.CardExportService
+}
+
+func NewCardExportHandler(s service.CardExportService) *CardExportHandler {
+	return &CardExportHandler{service: s}
+}
+
+func (h *CardExportHandler) RegisterCardExportRoutes(rg *gin.RouterGroup) {
+	rg.GET("/boards/:boardId/cards.csv", h.ExportCardsCSV)
+}
+
+// csvAttachment выставляет заголовки ответа при первой записи, чтобы ошибку,
+// случившуюся до начала выгрузки, можно было вернуть обычным JSON.
+type csvAttachment struct {
+	c        *gin.Context
+	filename string
+}
+
+func (a *csvAttachment) Write(p []byte) (int, error) {
+	if !a.c.Writer.Written() {
+		a.c.Header("Content-Type", "text/csv; charset=utf-8")
+		a.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, a.filename))
+		a.c.Status(http.StatusOK)
+	}
+	return a.c.Writer.Write(p)
+}
+
+// @Summary      Выгрузить карточки доски в CSV
+// @Description  Построчно отдаёт карточки доски со списком, позицией, датами, метками и исполнителями.
+// @Tags         Cards
+// @Produce      text/csv
+// @Param        boardId           path      int     true   "ID Доски"
+// @Param        columns           query     string  false  "Колонки через запятую, по умолчанию все"
+// @Param        list_id           query     int     false  "Только карточки списка"
+// @Param        label_id          query     int     false  "Только карточки с меткой"
+// @Param        assignee_id       query     int     false  "Только карточки исполнителя"
+// @Param        due_from          query     string  false  "Срок не раньше (RFC 3339)"
+// @Param        due_to            query     string  false  "Срок не позже (RFC 3339)"
+// @Param        completed         query     bool    false  "Только выполненные или невыполненные"
+// @Param        include_archived  query     bool    false  "Включить архивные списки и карточки"
+// @Success      200               {string}  string  "CSV"
+// @Failure      400               {object}  ErrorResponse
+// @Failure      403               {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /boards/{boardId}/cards.csv [get]
+func (h *CardExportHandler) ExportCardsCSV(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var filter models.CardExportFilter
+	var ok bool
+	if filter.ListID, ok = optionalIntQuery(c, "list_id"); !ok {
+		return
+	}
+	if filter.LabelID, ok = optionalIntQuery(c, "label_id"); !ok {
+		return
+	}
+	if filter.AssigneeID, ok = optionalIntQuery(c, "assignee_id"); !ok {
+		return
+	}
+	if filter.DueFrom, ok = optionalTimeQuery(c, "due_from"); !ok {
+		return
+	}
+	if filter.DueTo, ok = optionalTimeQuery(c, "due_to"); !ok {
+		return
+	}
+	if filter.Completed, ok = optionalBoolQuery(c, "completed"); !ok {
+		return
+	}
+	if filter.IncludeArchived, ok = includeArchivedQuery(c); !ok {
+		return
+	}
+
+	var columns []string
+	if raw := c.Query("columns"); raw != "" {
+		columns = strings.Split(raw, ",")
+	}
+
+	out := &csvAttachment{c: c, filename: fmt.Sprintf("board-%d-cards.csv", boardID)}
+	err = h.service.ExportCSV(c.Request.Context(), boardID, userID, columns, filter, out)
+	switch {
+	case err == nil:
+	case c.Writer.Written():
+		// Статус уже отправлен, остаётся оборвать выгрузку.
+		log.Printf("CSV export of board %d failed mid-stream: %v", boardID, err)
+		c.Abort()
+	default:
+		c.Error(err)
+	}
+}
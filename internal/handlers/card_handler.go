@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"notes-project/internal/apperr"
 	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
 	"notes-project/internal/service"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,9 +15,28 @@ type CardHandler struct {
 	service service.CardService
 }
 
+// MoveCardInput: before_card_id/after_card_id ставят карточку рядом с указанной,
+// new_position задаёт позицию явно; без них карточка уходит в конец списка.
 type MoveCardInput struct {
-	NewListID   int     `json:"new_list_id" binding:"required"`
-	NewPosition float64 `json:"new_position" binding:"required"`
+	NewListID    int      `json:"new_list_id" binding:"required"`
+	NewPosition  *float64 `json:"new_position"`
+	BeforeCardID *int     `json:"before_card_id"`
+	AfterCardID  *int     `json:"after_card_id"`
+}
+
+// CardDatesInput: отсутствующее или null-поле очищает соответствующую дату.
+type CardDatesInput struct {
+	StartAt *time.Time `json:"start_at"`
+	DueAt   *time.Time `json:"due_at"`
+}
+
+type CardCompletedInput struct {
+	Completed *bool `json:"completed" binding:"required"`
+}
+
+type UpdateCardInput struct {
+	Title       *string `json:"title" binding:"omitempty,min=1"`
+	Description *string `json:"description"`
 }
 
 func NewCardHandler(s service.CardService) *CardHandler {
@@ -25,9 +46,18 @@ func NewCardHandler(s service.CardService) *CardHandler {
 func (h *CardHandler) RegisterCardRoutes(rg *gin.RouterGroup) {
 	cardsGroup := rg.Group("/cards")
 	{
+		cardsGroup.GET("/:cardId", h.GetCard)
+		cardsGroup.PATCH("/:cardId", h.UpdateCard)
+		cardsGroup.DELETE("/:cardId", h.ArchiveCard)
+		cardsGroup.POST("/:cardId/restore", h.RestoreCard)
 		cardsGroup.PUT("/:cardId/move", h.MoveCard)
+		cardsGroup.PUT("/:cardId/dates", h.SetCardDates)
+		cardsGroup.DELETE("/:cardId/dates", h.ClearCardDates)
+		cardsGroup.PUT("/:cardId/completed", h.SetCardCompleted)
 	}
 
+	rg.GET("/boards/:boardId/cards/due", h.GetDueCards)
+
 	listsGroup := rg.Group("/lists/:listId/cards")
 	{
 		listsGroup.POST("/", h.CreateCard)
@@ -35,26 +65,26 @@ func (h *CardHandler) RegisterCardRoutes(rg *gin.RouterGroup) {
 }
 
 func (h *CardHandler) CreateCard(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	listID, err := strconv.Atoi(c.Param("listId"))
+	listID, err := reqctx.PathInt(c, "listId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid list id"})
+		c.Error(err)
 		return
 	}
 
 	var input models.Card
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
-	if err := h.service.Create(c.Request.Context(), &input, listID, userID.(int)); err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	if err := h.service.Create(c.Request.Context(), &input, listID, userID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -62,29 +92,237 @@ func (h *CardHandler) CreateCard(c *gin.Context) {
 }
 
 func (h *CardHandler) MoveCard(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	cardID, err := strconv.Atoi(c.Param("cardId"))
+	cardID, err := reqctx.PathInt(c, "cardId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid card id"})
+		c.Error(err)
 		return
 	}
 
 	var input MoveCardInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input: " + err.Error()})
+		c.Error(invalidInput(err))
 		return
 	}
 
-	err = h.service.Move(c.Request.Context(), cardID, input.NewListID, input.NewPosition, userID.(int))
+	placement := service.Placement{BeforeID: input.BeforeCardID, AfterID: input.AfterCardID, Position: input.NewPosition}
+	err = h.service.Move(c.Request.Context(), cardID, input.NewListID, placement, userID)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "card moved successfully"})
 }
+
+func (h *CardHandler) GetCard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	includeArchived, ok := includeArchivedQuery(c)
+	if !ok {
+		return
+	}
+
+	card, err := h.service.GetByID(c.Request.Context(), cardID, userID, includeArchived)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *CardHandler) UpdateCard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input UpdateCardInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	card, err := h.service.Update(c.Request.Context(), cardID, userID, input.Title, input.Description)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// ArchiveCard обслуживает DELETE: карточка уходит в архив и удаляется окончательно только после срока хранения.
+func (h *CardHandler) ArchiveCard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Archive(c.Request.Context(), cardID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "card archived successfully"})
+}
+
+func (h *CardHandler) RestoreCard(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	card, err := h.service.Restore(c.Request.Context(), cardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *CardHandler) SetCardDates(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input CardDatesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	card, err := h.service.SetDates(c.Request.Context(), cardID, userID, input.StartAt, input.DueAt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *CardHandler) ClearCardDates(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	card, err := h.service.SetDates(c.Request.Context(), cardID, userID, nil, nil)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *CardHandler) SetCardCompleted(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input CardCompletedInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	card, err := h.service.SetCompleted(c.Request.Context(), cardID, userID, *input.Completed)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// GetDueCards возвращает просроченные карточки доски и те, срок которых наступит
+// в ближайшие ?within (по умолчанию 24h).
+func (h *CardHandler) GetDueCards(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	window, err := time.ParseDuration(c.DefaultQuery("within", "24h"))
+	if err != nil || window <= 0 {
+		c.Error(apperr.Validation("invalid_query", "within must be a positive duration like 24h"))
+		return
+	}
+
+	due, err := h.service.GetDue(c.Request.Context(), boardID, userID, window)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, due)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newCardTestRouter(mockService *MockCardService) *gin.Engine {
+	return newTestRouter(1, func(rg *gin.RouterGroup) {
+		NewCardHandler(mockService).RegisterCardRoutes(rg)
+	})
+}
+
+func TestCardHandler_MoveCard_MalformedRequests(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockCardService)
+	r := newCardTestRouter(mockService)
+
+	// --- ACT & ASSERT ---
+	for _, tc := range []struct{ path, body, code string }{
+		{"/api/cards/abc/move", `{"new_list_id": 2}`, "invalid_id"},
+		{"/api/cards/1/move", `not json`, "invalid_input"},
+		{"/api/cards/1/move", `{"new_position": 1.5}`, "invalid_input"},
+		{"/api/cards/1/move", `{"new_list_id": "two"}`, "invalid_input"},
+	} {
+		w, resp := doRequest(r, http.MethodPut, tc.path, tc.body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc.body)
+		assert.Equal(t, tc.code, resp.Code, tc.body)
+	}
+	mockService.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCardHandler_MoveCard_ServiceErrors(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockCardService)
+	r := newCardTestRouter(mockService)
+	mockService.On("Move", mock.Anything, 1, 404, mock.Anything, 1).Return(service.ErrListNotFound)
+	mockService.On("Move", mock.Anything, 2, 5, mock.Anything, 1).Return(service.ErrBoardAccessDenied)
+
+	// --- ACT ---
+	wNotFound, respNotFound := doRequest(r, http.MethodPut, "/api/cards/1/move", `{"new_list_id": 404}`)
+	wForbidden, respForbidden := doRequest(r, http.MethodPut, "/api/cards/2/move", `{"new_list_id": 5}`)
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusNotFound, wNotFound.Code)
+	assert.Equal(t, "list_not_found", respNotFound.Code)
+	assert.Equal(t, http.StatusForbidden, wForbidden.Code)
+	assert.Equal(t, "board_access_denied", respForbidden.Code)
+	mockService.AssertExpectations(t)
+}
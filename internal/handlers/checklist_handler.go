@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChecklistHandler struct {
+	service service.ChecklistService
+}
+
+type ChecklistInput struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type UpdateChecklistItemInput struct {
+	Title   *string `json:"title" binding:"omitempty,min=1"`
+	Checked *bool   `json:"checked"`
+}
+
+type MoveChecklistItemInput struct {
+	NewPosition  *float64 `json:"new_position"`
+	BeforeItemID *int     `json:"before_item_id"`
+	AfterItemID  *int     `json:"after_item_id"`
+}
+
+func NewChecklistHandler(s service.ChecklistService) *ChecklistHandler {
+	return &ChecklistHandler{service: s}
+}
+
+func (h *ChecklistHandler) RegisterChecklistRoutes(rg *gin.RouterGroup) {
+	cardChecklists := rg.Group("/cards/:cardId/checklists")
+	{
+		cardChecklists.GET("/", h.ListChecklists)
+		cardChecklists.POST("/", h.CreateChecklist)
+	}
+
+	checklists := rg.Group("/checklists")
+	{
+		checklists.PATCH("/:checklistId", h.RenameChecklist)
+		checklists.DELETE("/:checklistId", h.DeleteChecklist)
+		checklists.POST("/:checklistId/items", h.AddItem)
+	}
+
+	items := rg.Group("/checklist-items")
+	{
+		items.PATCH("/:itemId", h.UpdateItem)
+		items.PUT("/:itemId/move", h.MoveItem)
+		items.DELETE("/:itemId", h.DeleteItem)
+	}
+}
+
+func (h *ChecklistHandler) ListChecklists(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	checklists, err := h.service.List(c.Request.Context(), cardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklists)
+}
+
+func (h *ChecklistHandler) CreateChecklist(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input ChecklistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	checklist, err := h.service.Create(c.Request.Context(), cardID, userID, input.Title)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checklist)
+}
+
+func (h *ChecklistHandler) RenameChecklist(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	checklistID, err := reqctx.PathInt(c, "checklistId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input ChecklistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	checklist, err := h.service.Rename(c.Request.Context(), checklistID, userID, input.Title)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
+
+func (h *ChecklistHandler) DeleteChecklist(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	checklistID, err := reqctx.PathInt(c, "checklistId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), checklistID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "checklist deleted successfully"})
+}
+
+func (h *ChecklistHandler) AddItem(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	checklistID, err := reqctx.PathInt(c, "checklistId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input ChecklistInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	item, err := h.service.AddItem(c.Request.Context(), checklistID, userID, input.Title)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *ChecklistHandler) UpdateItem(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	itemID, err := reqctx.PathInt(c, "itemId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input UpdateChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	item, err := h.service.UpdateItem(c.Request.Context(), itemID, userID, input.Title, input.Checked)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ChecklistHandler) MoveItem(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	itemID, err := reqctx.PathInt(c, "itemId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input MoveChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	placement := service.Placement{BeforeID: input.BeforeItemID, AfterID: input.AfterItemID, Position: input.NewPosition}
+	item, err := h.service.MoveItem(c.Request.Context(), itemID, placement, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ChecklistHandler) DeleteItem(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	itemID, err := reqctx.PathInt(c, "itemId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.DeleteItem(c.Request.Context(), itemID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "checklist item deleted successfully"})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCommentLimit = 20
+	maxCommentLimit     = 100
+)
+
+type CommentHandler struct {
+	service service.CommentService
+}
+
+type CommentInput struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func NewCommentHandler(s service.CommentService) *CommentHandler {
+	return &CommentHandler{service: s}
+}
+
+func (h *CommentHandler) RegisterCommentRoutes(rg *gin.RouterGroup) {
+	cardsGroup := rg.Group("/cards/:cardId/comments")
+	{
+		cardsGroup.GET("/", h.ListComments)
+		cardsGroup.POST("/", h.CreateComment)
+	}
+
+	commentsGroup := rg.Group("/comments")
+	{
+		commentsGroup.PATCH("/:commentId", h.UpdateComment)
+		commentsGroup.DELETE("/:commentId", h.DeleteComment)
+	}
+}
+
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultCommentLimit)))
+	if err != nil || limit < 1 || limit > maxCommentLimit {
+		c.Error(apperr.Validation("invalid_query", "limit must be between 1 and 100"))
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.Error(apperr.Validation("invalid_query", "offset must be a non-negative integer"))
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), cardID, userID, limit, offset)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	comment, err := h.service.Create(c.Request.Context(), cardID, userID, input.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	commentID, err := reqctx.PathInt(c, "commentId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	comment, err := h.service.Update(c.Request.Context(), commentID, userID, input.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	commentID, err := reqctx.PathInt(c, "commentId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), commentID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comment deleted successfully"})
+}
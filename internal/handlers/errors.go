@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse - единый формат ошибки во всех ответах API. Code не меняется вместе с
+// текстом, по нему клиенты и различают ошибки.
+type ErrorResponse struct {
+	Error  string                  `json:"error"`
+	Code   string                  `json:"code"`
+	Fields []validation.FieldError `json:"fields,omitempty"`
+}
+
+var (
+	errInvalidInput = apperr.Validation("invalid_input", "invalid input")
+	errInternal     = ErrorResponse{Error: "internal server error", Code: "internal_error"}
+)
+
+var statusByKind = []struct {
+	kind   error
+	status int
+}{
+	{apperr.ErrValidation, http.StatusBadRequest},
+	{apperr.ErrUnauthorized, http.StatusUnauthorized},
+	{apperr.ErrForbidden, http.StatusForbidden},
+	{apperr.ErrNotFound, http.StatusNotFound},
+	{apperr.ErrConflict, http.StatusConflict},
+	{apperr.ErrTooLarge, http.StatusRequestEntityTooLarge},
+	{apperr.ErrUnsupported, http.StatusUnsupportedMediaType},
+	{apperr.ErrUnavailable, http.StatusServiceUnavailable},
+}
+
+// invalidInput сохраняет для клиента текст ошибки разбора тела запроса: он описывает
+// только присланные данные.
+func invalidInput(err error) error {
+	return apperr.Validation(errInvalidInput.Code, "invalid input: %v", err)
+}
+
+// ErrorMiddleware отрисовывает ошибку, которую обработчик передал через c.Error.
+// Обработчики сами ответ с ошибкой не пишут: статус определяется видом ошибки из apperr,
+// а текст неизвестных ошибок попадает только в лог.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		err := c.Errors.Last().Err
+		// Ответ уже начал отправляться, например при потоковой выгрузке: остаётся только лог.
+		if c.Writer.Written() {
+			log.Printf("%s %s: error after response was written: %v", c.Request.Method, c.Request.URL.Path, err)
+			return
+		}
+		status, body := renderError(err)
+		// Причину серверных ошибок клиент не видит, поэтому она обязательно пишется в лог.
+		if status >= http.StatusInternalServerError {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+		c.JSON(status, body)
+	}
+}
+
+func renderError(err error) (int, ErrorResponse) {
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		return http.StatusBadRequest, ErrorResponse{Error: "validation failed", Code: "validation_failed", Fields: fieldErrs}
+	}
+
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) {
+		return http.StatusInternalServerError, errInternal
+	}
+	for _, entry := range statusByKind {
+		if appErr.Kind == entry.kind {
+			return entry.status, ErrorResponse{Error: appErr.Message, Code: appErr.Code}
+		}
+	}
+	return http.StatusInternalServerError, errInternal
+}
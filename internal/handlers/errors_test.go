@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"notes-project/internal/apperr"
+	"notes-project/internal/validation"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveError(err error) (*httptest.ResponseRecorder, ErrorResponse) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorMiddleware())
+	r.GET("/", func(c *gin.Context) { c.Error(err) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var body ErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestErrorMiddleware_StatusByKind(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{apperr.Validation("invalid_id", "invalid board id"), http.StatusBadRequest, "invalid_id"},
+		{apperr.Unauthorized("invalid_token", "invalid token"), http.StatusUnauthorized, "invalid_token"},
+		{apperr.Forbidden("board_access_denied", "access denied"), http.StatusForbidden, "board_access_denied"},
+		{apperr.NotFound("board_not_found", "board not found"), http.StatusNotFound, "board_not_found"},
+		{apperr.Conflict("email_taken", "email is already registered"), http.StatusConflict, "email_taken"},
+		{apperr.New(apperr.ErrTooLarge, "attachment_too_large", "too large"), http.StatusRequestEntityTooLarge, "attachment_too_large"},
+		// Обёртка из сервиса не мешает найти вид ошибки.
+		{fmt.Errorf("moving card: %w", apperr.NotFound("list_not_found", "list not found")), http.StatusNotFound, "list_not_found"},
+	}
+	for _, tc := range cases {
+		w, body := serveError(tc.err)
+
+		assert.Equal(t, tc.status, w.Code, tc.code)
+		assert.Equal(t, tc.code, body.Code)
+		assert.NotEmpty(t, body.Error)
+	}
+}
+
+func TestErrorMiddleware_HidesInternalErrors(t *testing.T) {
+	w, body := serveError(errors.New(`pq: relation "boards" does not exist`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal_error", body.Code)
+	assert.NotContains(t, w.Body.String(), "pq:")
+}
+
+func TestErrorMiddleware_HidesCause(t *testing.T) {
+	notFound := apperr.NotFound("card_not_found", "card not found")
+
+	w, body := serveError(notFound.Wrap(errors.New("cardRepository.GetByID: sql: no rows in result set")))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "card not found", body.Error)
+	assert.NotContains(t, w.Body.String(), "cardRepository")
+}
+
+func TestErrorMiddleware_ValidationFields(t *testing.T) {
+	v := validation.New()
+	v.Add("email", "must be a valid email address")
+
+	w, body := serveError(v.Err())
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "validation_failed", body.Code)
+	assert.Equal(t, []validation.FieldError{{Field: "email", Message: "must be a valid email address"}}, body.Fields)
+}
+
+func TestErrorMiddleware_KeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		c.Error(errors.New("stream failed"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "partial", w.Body.String())
+}
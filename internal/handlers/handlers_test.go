@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestRouter собирает роутер как в main, но без gin.Recovery: паника в обработчике
+// роняет тест. userID = 0 - запрос без аутентификации.
+func newTestRouter(userID int, register func(rg *gin.RouterGroup)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorMiddleware())
+	r.Use(func(c *gin.Context) {
+		if userID != 0 {
+			reqctx.SetUser(c, &models.AccessClaims{UserID: userID})
+		}
+	})
+	register(r.Group("/api"))
+	return r
+}
+
+func doRequest(r *gin.Engine, method, path, body string) (*httptest.ResponseRecorder, ErrorResponse) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+// MockBoardService реализует только методы, которые вызывают тесты; вызов остальных
+// заканчивается паникой и проваливает тест.
+type MockBoardService struct {
+	service.BoardService
+	mock.Mock
+}
+
+func (m *MockBoardService) GetByID(ctx context.Context, boardID, userID int, includeArchived bool) (*models.Board, error) {
+	args := m.Called(ctx, boardID, userID, includeArchived)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Board), args.Error(1)
+}
+
+func (m *MockBoardService) AddMember(ctx context.Context, boardID, inviterID int, inviteeEmail string, role models.BoardRole) (*models.Invitation, error) {
+	args := m.Called(ctx, boardID, inviterID, inviteeEmail, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+type MockCardService struct {
+	service.CardService
+	mock.Mock
+}
+
+func (m *MockCardService) Move(ctx context.Context, cardID, newListID int, placement service.Placement, userID int) error {
+	args := m.Called(ctx, cardID, newListID, placement, userID)
+	return args.Error(0)
+}
+
+func TestHandlers_MissingUserIsUnauthorized(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoards := new(MockBoardService)
+	mockCards := new(MockCardService)
+	r := newTestRouter(0, func(rg *gin.RouterGroup) {
+		NewBoardHandler(mockBoards).RegisterBoardRoutes(rg)
+		NewCardHandler(mockCards).RegisterCardRoutes(rg)
+	})
+
+	// --- ACT & ASSERT ---
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/boards/1", ""},
+		{http.MethodPost, "/api/boards/1/members", `{"email": "bob@example.com"}`},
+		{http.MethodPut, "/api/cards/1/move", `{"new_list_id": 2}`},
+	} {
+		w, resp := doRequest(r, tc.method, tc.path, tc.body)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, tc.path)
+		assert.Equal(t, "unauthorized", resp.Code, tc.path)
+	}
+	mockBoards.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	service service.InvitationService
+}
+
+type AcceptInvitationInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func NewInvitationHandler(s service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{service: s}
+}
+
+func (h *InvitationHandler) RegisterInvitationRoutes(rg *gin.RouterGroup) {
+	boardInvitations := rg.Group("/boards/:boardId/invitations")
+	{
+		boardInvitations.GET("/", h.ListInvitations)
+		boardInvitations.DELETE("/:invitationId", h.RevokeInvitation)
+	}
+
+	rg.POST("/invitations/accept", h.AcceptInvitation)
+}
+
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	invitations, err := h.service.ListPending(c.Request.Context(), boardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	invitationID, err := reqctx.PathInt(c, "invitationId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), boardID, invitationID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked successfully"})
+}
+
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input AcceptInvitationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	invitation, err := h.service.Accept(c.Request.Context(), input.Token, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation accepted", "board_id": invitation.BoardID})
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	service service.LabelService
+}
+
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color" binding:"required"`
+}
+
+type UpdateLabelInput struct {
+	Name  *string `json:"name" binding:"omitempty,min=1"`
+	Color *string `json:"color"`
+}
+
+func NewLabelHandler(s service.LabelService) *LabelHandler {
+	return &LabelHandler{service: s}
+}
+
+func (h *LabelHandler) RegisterLabelRoutes(rg *gin.RouterGroup) {
+	boardLabels := rg.Group("/boards/:boardId/labels")
+	{
+		boardLabels.GET("/", h.ListLabels)
+		boardLabels.POST("/", h.CreateLabel)
+		boardLabels.PATCH("/:labelId", h.UpdateLabel)
+		boardLabels.DELETE("/:labelId", h.DeleteLabel)
+	}
+
+	cardLabels := rg.Group("/cards/:cardId/labels")
+	{
+		cardLabels.POST("/:labelId", h.AttachLabel)
+		cardLabels.DELETE("/:labelId", h.DetachLabel)
+	}
+}
+
+func (h *LabelHandler) ListLabels(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	labels, err := h.service.List(c.Request.Context(), boardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input CreateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	label, err := h.service.Create(c.Request.Context(), boardID, userID, input.Name, input.Color)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	labelID, err := reqctx.PathInt(c, "labelId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input UpdateLabelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	label, err := h.service.Update(c.Request.Context(), boardID, labelID, userID, input.Name, input.Color)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	labelID, err := reqctx.PathInt(c, "labelId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), boardID, labelID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "label deleted successfully"})
+}
+
+func (h *LabelHandler) AttachLabel(c *gin.Context) {
+	h.changeCardLabel(c, h.service.AttachToCard)
+}
+
+func (h *LabelHandler) DetachLabel(c *gin.Context) {
+	h.changeCardLabel(c, h.service.DetachFromCard)
+}
+
+func (h *LabelHandler) changeCardLabel(c *gin.Context, change func(ctx context.Context, cardID, labelID, userID int) ([]models.Label, error)) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	cardID, err := reqctx.PathInt(c, "cardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	labelID, err := reqctx.PathInt(c, "labelId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	labels, err := change(c.Request.Context(), cardID, labelID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
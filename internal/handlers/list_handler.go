@@ -3,8 +3,8 @@ package handlers
 import (
 	"net/http"
 	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
 	"notes-project/internal/service"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +13,18 @@ type ListHandler struct {
 	service service.ListService
 }
 
+type UpdateListInput struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// MoveListInput: before_list_id/after_list_id ставят список рядом с указанным,
+// new_position задаёт позицию явно; без них список уходит в конец доски.
+type MoveListInput struct {
+	NewPosition  *float64 `json:"new_position"`
+	BeforeListID *int     `json:"before_list_id"`
+	AfterListID  *int     `json:"after_list_id"`
+}
+
 func NewListHandler(s service.ListService) *ListHandler {
 	return &ListHandler{service: s}
 }
@@ -22,31 +34,139 @@ func (h *ListHandler) RegisterListRoutes(rg *gin.RouterGroup) {
 	{
 		lists.POST("/", h.CreateList)
 	}
+
+	listGroup := rg.Group("/lists")
+	{
+		listGroup.PUT("/:listId", h.UpdateList)
+		listGroup.PATCH("/:listId", h.UpdateList)
+		listGroup.DELETE("/:listId", h.ArchiveList)
+		listGroup.POST("/:listId/restore", h.RestoreList)
+		listGroup.PUT("/:listId/move", h.MoveList)
+	}
 }
 
 func (h *ListHandler) CreateList(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user id not found in context"})
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	boardID, err := strconv.Atoi(c.Param("boardId"))
+	boardID, err := reqctx.PathInt(c, "boardId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board id"})
+		c.Error(err)
 		return
 	}
 
 	var input models.List
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
 
-	if err := h.service.Create(c.Request.Context(), &input, boardID, userID.(int)); err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	if err := h.service.Create(c.Request.Context(), &input, boardID, userID); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, input)
 }
+
+func (h *ListHandler) UpdateList(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	listID, err := reqctx.PathInt(c, "listId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input UpdateListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	list, err := h.service.Update(c.Request.Context(), listID, userID, input.Title)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ListHandler) MoveList(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	listID, err := reqctx.PathInt(c, "listId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input MoveListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(invalidInput(err))
+		return
+	}
+
+	placement := service.Placement{BeforeID: input.BeforeListID, AfterID: input.AfterListID, Position: input.NewPosition}
+	if err := h.service.Move(c.Request.Context(), listID, placement, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "list moved successfully"})
+}
+
+func (h *ListHandler) ArchiveList(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	listID, err := reqctx.PathInt(c, "listId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Archive(c.Request.Context(), listID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "list archived successfully"})
+}
+
+func (h *ListHandler) RestoreList(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	listID, err := reqctx.PathInt(c, "listId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	list, err := h.service.Restore(c.Request.Context(), listID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
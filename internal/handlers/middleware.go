@@ -3,16 +3,20 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
+	"notes-project/internal/apperr"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware пропускает запрос с действующим access-токеном и кладёт в контекст
+// userId и tokenClaims. Если denylist недоступен, запрос отклоняется с 503: отозванный
+// токен не должен пройти только потому, что его не удалось проверить.
+func AuthMiddleware(auth service.AccessTokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		tokenString := ""
@@ -20,42 +24,63 @@ func AuthMiddleware() gin.HandlerFunc {
 		if authHeader != "" {
 			headerParts := strings.Split(authHeader, " ")
 			if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+				abortWithError(c, apperr.Unauthorized("invalid_auth_header", "invalid authorization header format"))
 				return
 			}
 			tokenString = headerParts[1]
 		} else {
 			tokenString = c.Query("auth")
 			if tokenString == "" {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization token not provided"})
+				abortWithError(c, apperr.Unauthorized("missing_token", "authorization token not provided"))
 				return
 			}
 		}
 
-		secretKey := os.Getenv("JWT_SECRET_KEY")
+		claims, err := auth.Authenticate(c.Request.Context(), tokenString)
+		switch {
+		case errors.Is(err, apperr.ErrUnauthorized):
+			abortWithError(c, err)
+			return
+		case err != nil:
+			abortWithError(c, apperr.New(apperr.ErrUnavailable, "auth_unavailable", "could not verify token").Wrap(err))
+			return
+		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secretKey), nil
-		})
+		reqctx.SetUser(c, claims)
+		c.Next()
+	}
+}
 
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+// VerifiedEmailMiddleware оставляет аккаунтам с неподтверждённой почтой только чтение.
+// Ставится после AuthMiddleware.
+func VerifiedEmailMiddleware(checker service.EmailVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			userIDFloat, ok := claims["sub"].(float64)
-			if !ok {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid user id in token"})
-				return
-			}
-			c.Set("userId", int(userIDFloat))
-			c.Next()
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		userID, err := reqctx.CurrentUser(c)
+		if err != nil {
+			abortWithError(c, err)
+			return
 		}
+		verified, err := checker.IsEmailVerified(c.Request.Context(), userID)
+		if err != nil {
+			abortWithError(c, apperr.New(apperr.ErrUnavailable, "auth_unavailable", "could not check email verification").Wrap(err))
+			return
+		}
+		if !verified {
+			abortWithError(c, apperr.Forbidden("email_not_verified", "email is not verified"))
+			return
+		}
+		c.Next()
 	}
 }
+
+// abortWithError останавливает цепочку обработчиков; ответ отрисует ErrorMiddleware.
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	service service.SearchService
+}
+
+func NewSearchHandler(s service.SearchService) *SearchHandler {
+	return &SearchHandler{service: s}
+}
+
+func (h *SearchHandler) RegisterSearchRoutes(rg *gin.RouterGroup) {
+	rg.GET("/search", h.Search)
+}
+
+// @Summary      Полнотекстовый поиск
+// @Description  Ищет по названиям досок и списков, карточкам и комментариям на доступных пользователю досках.
+// @Description  Фрагменты экранированы как HTML и размечены тегами <mark>.
+// @Tags         Search
+// @Produce      json
+// @Param        q            query     string  true   "Поисковый запрос"
+// @Param        board_id     query     int     false  "Только эта доска"
+// @Param        label_id     query     int     false  "Только карточки с меткой"
+// @Param        assignee_id  query     int     false  "Только карточки исполнителя"
+// @Param        due_from     query     string  false  "Срок не раньше (RFC 3339)"
+// @Param        due_to       query     string  false  "Срок не позже (RFC 3339)"
+// @Param        limit        query     int     false  "Число результатов, по умолчанию 20, не больше 100"
+// @Success      200          {array}   models.SearchResult
+// @Failure      400          {object}  ErrorResponse
+// @Security     ApiKeyAuth
+// @Router       /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filters := models.SearchFilters{Query: c.Query("q")}
+	var ok bool
+	if filters.BoardID, ok = optionalIntQuery(c, "board_id"); !ok {
+		return
+	}
+	if filters.LabelID, ok = optionalIntQuery(c, "label_id"); !ok {
+		return
+	}
+	if filters.AssigneeID, ok = optionalIntQuery(c, "assignee_id"); !ok {
+		return
+	}
+	if filters.DueFrom, ok = optionalTimeQuery(c, "due_from"); !ok {
+		return
+	}
+	if filters.DueTo, ok = optionalTimeQuery(c, "due_to"); !ok {
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.Error(apperr.Validation("invalid_query", "limit must be a positive integer"))
+			return
+		}
+		filters.Limit = n
+	}
+
+	results, err := h.service.Search(c.Request.Context(), userID, filters)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// optionalIntQuery и optionalTimeQuery возвращают nil для пустого параметра;
+// при ошибке разбора ответ уже отправлен.
+func optionalIntQuery(c *gin.Context, name string) (*int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		c.Error(apperr.Validation("invalid_query", "invalid %s", name))
+		return nil, false
+	}
+	return &value, true
+}
+
+func optionalTimeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.Error(apperr.Validation("invalid_query", "%s must be an RFC 3339 timestamp", name))
+		return nil, false
+	}
+	return &value, true
+}
+
+func optionalBoolQuery(c *gin.Context, name string) (*bool, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.Error(apperr.Validation("invalid_query", "%s must be a boolean", name))
+		return nil, false
+	}
+	return &value, true
+}
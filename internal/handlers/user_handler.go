@@ -3,8 +3,8 @@ package handlers
 import (
 	"net/http"
 	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
 	"notes-project/internal/service"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,11 +13,30 @@ type UserHandler struct {
 	service service.UserService
 }
 
+// RegisterInput - поля проверяет сервис, здесь только разбор JSON.
+type RegisterInput struct {
+	Name     string `json:"name"`
+	Age      *int   `json:"age"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
+// UpdateUserInput - частичное изменение профиля. is_admin может менять только администратор.
+type UpdateUserInput struct {
+	Name    *string `json:"name"`
+	Age     *int    `json:"age"`
+	IsAdmin *bool   `json:"is_admin"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 func NewUserHandler(s service.UserService) *UserHandler {
 	return &UserHandler{service: s}
 }
@@ -25,12 +44,19 @@ func NewUserHandler(s service.UserService) *UserHandler {
 func (h *UserHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
 	rg.POST("/register", h.Register)
 	rg.POST("/login", h.Login)
+	rg.POST("/refresh", h.Refresh)
 }
 
 func (h *UserHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
 	users := rg.Group("/users")
 	{
 		users.GET("/", h.GetAllUsers)
+		users.POST("/logout", h.Logout)
+		users.POST("/logout-all", h.LogoutAll)
+
+		users.GET("/me", h.GetMe)
+		users.PATCH("/me", h.UpdateMe)
+		users.DELETE("/me", h.DeleteMe)
 
 		users.GET("/:userId", h.GetUserByID)
 		users.PUT("/:userId", h.UpdateUser)
@@ -39,14 +65,15 @@ func (h *UserHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+	var input RegisterInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
 		return
 	}
+	user := models.User{Name: input.Name, Age: input.Age, Email: input.Email, Password: input.Password}
 	// Передаем контекст в сервис
 	if err := h.service.Register(c.Request.Context(), &user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not register user"})
+		c.Error(err)
 		return
 	}
 	user.Password = ""
@@ -56,75 +83,181 @@ func (h *UserHandler) Register(c *gin.Context) {
 func (h *UserHandler) Login(c *gin.Context) {
 	var input LoginInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		c.Error(errInvalidInput)
 		return
 	}
-	token, err := h.service.Login(c.Request.Context(), input.Email, input.Password)
+	tokens, err := h.service.Login(c.Request.Context(), input.Email, input.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh меняет refresh-токен на новую пару токенов. Каждый refresh-токен действует один раз.
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var input RefreshInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+	tokens, err := h.service.Refresh(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout завершает текущую сессию: access-токен запроса и его refresh-токены перестают действовать.
+func (h *UserHandler) Logout(c *gin.Context) {
+	claims, err := reqctx.Claims(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.service.Logout(c.Request.Context(), claims); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.service.LogoutAll(c.Request.Context(), userID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "logged out everywhere"})
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.service.GetAll(c.Request.Context())
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	users, err := h.service.GetAll(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, users)
 }
 
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.getUser(c, userID, userID)
+}
+
+// GetUserByID отдаёт полный профиль самому пользователю и администраторам, остальным - публичный.
 func (h *UserHandler) GetUserByID(c *gin.Context) {
-	idStr := c.Param("userId")
-	id, err := strconv.Atoi(idStr)
+	userID, err := reqctx.CurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		c.Error(err)
 		return
 	}
-	user, err := h.service.GetByID(c.Request.Context(), id)
+	id, err := reqctx.PathInt(c, "userId")
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		c.Error(err)
+		return
+	}
+	h.getUser(c, userID, id)
+}
+
+func (h *UserHandler) getUser(c *gin.Context, actorID, id int) {
+	user, full, err := h.service.GetByID(c.Request.Context(), actorID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !full {
+		c.JSON(http.StatusOK, user.Public())
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
 
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.updateUser(c, userID, userID)
+}
+
 func (h *UserHandler) UpdateUser(c *gin.Context) {
-	// Читаем правильный параметр
-	idStr := c.Param("userId")
-	id, err := strconv.Atoi(idStr)
+	userID, err := reqctx.CurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		c.Error(err)
 		return
 	}
+	id, err := reqctx.PathInt(c, "userId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.updateUser(c, userID, id)
+}
 
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (h *UserHandler) updateUser(c *gin.Context, actorID, id int) {
+	var input UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
 		return
 	}
-	user.ID = id
 
-	if err := h.service.Update(c.Request.Context(), &user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	user, err := h.service.Update(c.Request.Context(), actorID, id, models.UserUpdate{
+		Name:    input.Name,
+		Age:     input.Age,
+		IsAdmin: input.IsAdmin,
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, user)
 }
 
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.deleteUser(c, userID, userID)
+}
+
 func (h *UserHandler) DeleteUser(c *gin.Context) {
-	idStr := c.Param("userId")
-	id, err := strconv.Atoi(idStr)
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	id, err := reqctx.PathInt(c, "userId")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		c.Error(err)
 		return
 	}
+	h.deleteUser(c, userID, id)
+}
 
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// deleteUser отвечает тем, что стало с досками удалённого пользователя.
+func (h *UserHandler) deleteUser(c *gin.Context, actorID, id int) {
+	deletion, err := h.service.Delete(c.Request.Context(), actorID, id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "user deleted"})
+	c.JSON(http.StatusOK, gin.H{"message": "user deleted", "boards": deletion})
 }
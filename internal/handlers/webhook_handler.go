@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+type UpdateWebhookInput struct {
+	URL    *string   `json:"url" binding:"omitempty,min=1"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+func NewWebhookHandler(s service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: s}
+}
+
+func (h *WebhookHandler) RegisterWebhookRoutes(rg *gin.RouterGroup) {
+	webhooks := rg.Group("/boards/:boardId/webhooks")
+	{
+		webhooks.GET("/", h.ListWebhooks)
+		webhooks.POST("/", h.CreateWebhook)
+		webhooks.PATCH("/:webhookId", h.UpdateWebhook)
+		webhooks.DELETE("/:webhookId", h.DeleteWebhook)
+		webhooks.GET("/:webhookId/deliveries", h.ListDeliveries)
+		webhooks.POST("/:webhookId/deliveries/:deliveryId/redeliver", h.Redeliver)
+	}
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	webhooks, err := h.service.List(c.Request.Context(), boardID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook возвращает секрет подписи только в этом ответе.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input CreateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	webhook, err := h.service.Create(c.Request.Context(), boardID, userID, input.URL, input.Secret, input.Events)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	webhookID, err := reqctx.PathInt(c, "webhookId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var input UpdateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(errInvalidInput)
+		return
+	}
+
+	webhook, err := h.service.Update(c.Request.Context(), boardID, webhookID, userID, input.URL, input.Events, input.Active)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	webhookID, err := reqctx.PathInt(c, "webhookId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), boardID, webhookID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook deleted successfully"})
+}
+
+// ListDeliveries - журнал доставок подписки, от новых к старым, с курсором как у журнала действий.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	webhookID, err := reqctx.PathInt(c, "webhookId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	limit, ok := activityLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.ListDeliveries(c.Request.Context(), boardID, webhookID, userID, c.Query("cursor"), limit)
+	respondCursorPage(c, page, err)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	boardID, err := reqctx.PathInt(c, "boardId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	webhookID, err := reqctx.PathInt(c, "webhookId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	deliveryID, err := reqctx.PathInt64(c, "deliveryId")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Redeliver(c.Request.Context(), boardID, webhookID, userID, deliveryID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "delivery queued"})
+}
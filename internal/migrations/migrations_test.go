@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	// Версии должны строго возрастать, а у каждой миграции должны быть обе половины.
+	for i, m := range migrations {
+		assert.NotEmpty(t, m.Name)
+		assert.NotEmpty(t, m.Up, "version %d has empty up", m.Version)
+		assert.NotEmpty(t, m.Down, "version %d has empty down", m.Version)
+		if i > 0 {
+			assert.Greater(t, m.Version, migrations[i-1].Version)
+		}
+	}
+}
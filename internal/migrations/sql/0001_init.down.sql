@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS cards;
+DROP TABLE IF EXISTS lists;
+DROP TABLE IF EXISTS board_members;
+DROP TABLE IF EXISTS boards;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,51 @@
+-- IF NOT EXISTS позволяет базам, подготовленным вручную, принять историю миграций.
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    name          TEXT        NOT NULL DEFAULT '',
+    age           TEXT        NOT NULL DEFAULT '',
+    email         TEXT        NOT NULL UNIQUE,
+    password_hash TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS boards (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    owner_id   INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_boards_owner_id ON boards (owner_id);
+
+CREATE TABLE IF NOT EXISTS board_members (
+    board_id INTEGER NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    user_id  INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, board_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_members_board_id ON board_members (board_id);
+
+CREATE TABLE IF NOT EXISTS lists (
+    id         SERIAL PRIMARY KEY,
+    title      TEXT             NOT NULL,
+    "position" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    board_id   INTEGER          NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_lists_board_id_position ON lists (board_id, "position");
+
+CREATE TABLE IF NOT EXISTS cards (
+    id          SERIAL PRIMARY KEY,
+    title       TEXT             NOT NULL,
+    description TEXT             NOT NULL DEFAULT '',
+    "position"  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    list_id     INTEGER          NOT NULL REFERENCES lists (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cards_list_id_position ON cards (list_id, "position");
@@ -0,0 +1 @@
+ALTER TABLE board_members DROP COLUMN IF EXISTS role;
@@ -0,0 +1,8 @@
+ALTER TABLE board_members
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+        CHECK (role IN ('owner', 'admin', 'member', 'observer'));
+
+-- Владельцы досок раньше могли не попасть в board_members, поэтому добавляем их явно.
+INSERT INTO board_members (board_id, user_id, role)
+SELECT id, owner_id, 'owner' FROM boards
+ON CONFLICT (user_id, board_id) DO UPDATE SET role = 'owner';
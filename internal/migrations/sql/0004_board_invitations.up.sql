@@ -0,0 +1,16 @@
+CREATE TABLE board_invitations (
+    id          SERIAL PRIMARY KEY,
+    board_id    INTEGER     NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    email       TEXT        NOT NULL,
+    role        TEXT        NOT NULL CHECK (role IN ('admin', 'member', 'observer')),
+    token_hash  TEXT        NOT NULL UNIQUE,
+    invited_by  INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_board_invitations_board_id ON board_invitations (board_id);
+CREATE INDEX idx_board_invitations_pending_email ON board_invitations (LOWER(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
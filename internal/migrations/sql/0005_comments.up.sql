@@ -0,0 +1,10 @@
+CREATE TABLE comments (
+    id         SERIAL PRIMARY KEY,
+    card_id    INTEGER     NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    author_id  INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_card_id_created_at ON comments (card_id, created_at, id);
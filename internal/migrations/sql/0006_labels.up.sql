@@ -0,0 +1,17 @@
+CREATE TABLE labels (
+    id         SERIAL PRIMARY KEY,
+    board_id   INTEGER     NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    color      TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (board_id, name)
+);
+
+CREATE TABLE card_labels (
+    card_id  INTEGER NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    label_id INTEGER NOT NULL REFERENCES labels (id) ON DELETE CASCADE,
+    PRIMARY KEY (card_id, label_id)
+);
+
+CREATE INDEX idx_card_labels_label_id ON card_labels (label_id);
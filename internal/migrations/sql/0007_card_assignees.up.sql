@@ -0,0 +1,8 @@
+CREATE TABLE card_assignees (
+    card_id     INTEGER     NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    user_id     INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (card_id, user_id)
+);
+
+CREATE INDEX idx_card_assignees_user_id ON card_assignees (user_id);
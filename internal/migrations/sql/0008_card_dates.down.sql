@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_cards_due_at_open;
+
+ALTER TABLE cards
+    DROP COLUMN IF EXISTS overdue_notified_at,
+    DROP COLUMN IF EXISTS due_soon_notified_at,
+    DROP COLUMN IF EXISTS completed,
+    DROP COLUMN IF EXISTS due_at,
+    DROP COLUMN IF EXISTS start_at;
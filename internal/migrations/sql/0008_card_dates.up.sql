@@ -0,0 +1,9 @@
+ALTER TABLE cards
+    ADD COLUMN start_at             TIMESTAMPTZ,
+    ADD COLUMN due_at               TIMESTAMPTZ,
+    ADD COLUMN completed            BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN due_soon_notified_at TIMESTAMPTZ,
+    ADD COLUMN overdue_notified_at  TIMESTAMPTZ;
+
+-- Планировщик сроков ищет только незавершённые карточки с назначенным сроком.
+CREATE INDEX idx_cards_due_at_open ON cards (due_at) WHERE due_at IS NOT NULL AND NOT completed;
@@ -0,0 +1,22 @@
+CREATE TABLE checklists (
+    id         SERIAL PRIMARY KEY,
+    card_id    INTEGER          NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    title      TEXT             NOT NULL,
+    "position" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_checklists_card_id_position ON checklists (card_id, "position");
+
+CREATE TABLE checklist_items (
+    id           SERIAL PRIMARY KEY,
+    checklist_id INTEGER          NOT NULL REFERENCES checklists (id) ON DELETE CASCADE,
+    title        TEXT             NOT NULL,
+    checked      BOOLEAN          NOT NULL DEFAULT FALSE,
+    "position"   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_checklist_items_checklist_id_position ON checklist_items (checklist_id, "position");
@@ -0,0 +1,12 @@
+CREATE TABLE attachments (
+    id           SERIAL PRIMARY KEY,
+    card_id      INTEGER     NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+    uploader_id  INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    filename     TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size_bytes   BIGINT      NOT NULL,
+    storage_key  TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_attachments_card_id ON attachments (card_id, created_at);
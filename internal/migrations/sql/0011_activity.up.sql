@@ -0,0 +1,16 @@
+-- card_id без внешнего ключа: история удалённой карточки должна сохраниться.
+CREATE TABLE activity (
+    id          BIGSERIAL PRIMARY KEY,
+    board_id    INTEGER     NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    card_id     INTEGER,
+    actor_id    INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    action      TEXT        NOT NULL,
+    entity_type TEXT        NOT NULL,
+    entity_id   INTEGER     NOT NULL,
+    before      JSONB       NOT NULL DEFAULT 'null',
+    after       JSONB       NOT NULL DEFAULT 'null',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_activity_board_id_id ON activity (board_id, id DESC);
+CREATE INDEX idx_activity_card_id_id ON activity (card_id, id DESC) WHERE card_id IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_cards_archived_at;
+DROP INDEX IF EXISTS idx_lists_archived_at;
+DROP INDEX IF EXISTS idx_boards_archived_at;
+
+ALTER TABLE cards DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE boards DROP COLUMN IF EXISTS archived_at;
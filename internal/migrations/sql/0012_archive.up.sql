@@ -0,0 +1,7 @@
+ALTER TABLE boards ADD COLUMN archived_at TIMESTAMPTZ;
+ALTER TABLE cards ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Задача очистки ищет давно архивированные записи; активные в индексы не попадают.
+CREATE INDEX idx_boards_archived_at ON boards (archived_at) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_lists_archived_at ON lists (archived_at) WHERE archived_at IS NOT NULL;
+CREATE INDEX idx_cards_archived_at ON cards (archived_at) WHERE archived_at IS NOT NULL;
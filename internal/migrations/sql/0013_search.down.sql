@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS comments_search_vector_trigger ON comments;
+DROP TRIGGER IF EXISTS cards_search_vector_trigger ON cards;
+DROP TRIGGER IF EXISTS lists_search_vector_trigger ON lists;
+DROP TRIGGER IF EXISTS boards_search_vector_trigger ON boards;
+
+DROP FUNCTION IF EXISTS comments_search_vector_update();
+DROP FUNCTION IF EXISTS cards_search_vector_update();
+DROP FUNCTION IF EXISTS lists_search_vector_update();
+DROP FUNCTION IF EXISTS boards_search_vector_update();
+
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE cards DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE lists DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE boards DROP COLUMN IF EXISTS search_vector;
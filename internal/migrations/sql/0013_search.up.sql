@@ -0,0 +1,57 @@
+-- Конфигурация simple: тексты бывают и на русском, и на английском, а стемминг одного
+-- языка портил бы поиск по другому.
+ALTER TABLE boards ADD COLUMN search_vector tsvector;
+ALTER TABLE lists ADD COLUMN search_vector tsvector;
+ALTER TABLE cards ADD COLUMN search_vector tsvector;
+ALTER TABLE comments ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION boards_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := to_tsvector('simple', NEW.name);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION lists_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := to_tsvector('simple', NEW.title);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+-- Совпадение в заголовке карточки весит больше, чем в описании.
+CREATE FUNCTION cards_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := setweight(to_tsvector('simple', NEW.title), 'A') ||
+                         setweight(to_tsvector('simple', NEW.description), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION comments_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := to_tsvector('simple', NEW.body);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER boards_search_vector_trigger BEFORE INSERT OR UPDATE OF name ON boards
+    FOR EACH ROW EXECUTE FUNCTION boards_search_vector_update();
+CREATE TRIGGER lists_search_vector_trigger BEFORE INSERT OR UPDATE OF title ON lists
+    FOR EACH ROW EXECUTE FUNCTION lists_search_vector_update();
+CREATE TRIGGER cards_search_vector_trigger BEFORE INSERT OR UPDATE OF title, description ON cards
+    FOR EACH ROW EXECUTE FUNCTION cards_search_vector_update();
+CREATE TRIGGER comments_search_vector_trigger BEFORE INSERT OR UPDATE OF body ON comments
+    FOR EACH ROW EXECUTE FUNCTION comments_search_vector_update();
+
+-- Заполняем вектор для уже существующих строк.
+UPDATE boards SET search_vector = to_tsvector('simple', name);
+UPDATE lists SET search_vector = to_tsvector('simple', title);
+UPDATE cards SET search_vector = setweight(to_tsvector('simple', title), 'A') ||
+                                 setweight(to_tsvector('simple', description), 'B');
+UPDATE comments SET search_vector = to_tsvector('simple', body);
+
+CREATE INDEX idx_boards_search_vector ON boards USING GIN (search_vector);
+CREATE INDEX idx_lists_search_vector ON lists USING GIN (search_vector);
+CREATE INDEX idx_cards_search_vector ON cards USING GIN (search_vector);
+CREATE INDEX idx_comments_search_vector ON comments USING GIN (search_vector);
@@ -0,0 +1,34 @@
+-- Пустой events означает подписку на все события доски.
+CREATE TABLE webhooks (
+    id         SERIAL PRIMARY KEY,
+    board_id   INTEGER     NOT NULL REFERENCES boards (id) ON DELETE CASCADE,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT[]      NOT NULL DEFAULT '{}',
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_by INTEGER     REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_board_id ON webhooks (board_id);
+
+-- Доставка живёт в базе с момента события, поэтому перезапуск сервера её не теряет.
+-- status: pending - ждёт отправки или повтора, delivered - получатель ответил 2xx,
+-- dead - попытки исчерпаны, повторить можно только вручную.
+CREATE TABLE webhook_deliveries (
+    id               BIGSERIAL PRIMARY KEY,
+    webhook_id       INTEGER     NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event            TEXT        NOT NULL,
+    payload          JSONB       NOT NULL,
+    status           TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error       TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at     TIMESTAMPTZ
+);
+
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook_id_id ON webhook_deliveries (webhook_id, id DESC);
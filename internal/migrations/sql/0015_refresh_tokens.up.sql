@@ -0,0 +1,16 @@
+-- Хранится только SHA-256 от refresh-токена. Все токены одной сессии образуют семейство:
+-- каждый refresh выдаёт новый токен того же семейства и помечает старый rotated_at.
+-- Повторное использование уже сменённого токена отзывает всё семейство.
+CREATE TABLE refresh_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id  TEXT        NOT NULL,
+    token_hash TEXT        NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    rotated_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_user_id_active ON refresh_tokens (user_id) WHERE revoked_at IS NULL;
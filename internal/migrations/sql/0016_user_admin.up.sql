@@ -0,0 +1,3 @@
+-- Администратор системы управляет чужими аккаунтами. Первого администратора назначают
+-- командой `admin grant <email>`.
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+-- Аккаунты, созданные до появления подтверждения почты, считаются подтверждёнными.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+UPDATE users SET email_verified_at = created_at;
+
+-- Одноразовые токены для подтверждения почты и сброса пароля. Хранится только SHA-256.
+CREATE TABLE account_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    INTEGER     NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose    TEXT        NOT NULL CHECK (purpose IN ('email_verification', 'password_reset')),
+    token_hash TEXT        NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_account_tokens_user_id_active ON account_tokens (user_id, purpose) WHERE used_at IS NULL;
@@ -0,0 +1,5 @@
+-- Регистр email не восстанавливается: адреса в нижнем регистре остаются рабочими.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_age_range;
+ALTER TABLE users ALTER COLUMN age TYPE TEXT USING COALESCE(age::TEXT, '');
+ALTER TABLE users ALTER COLUMN age SET DEFAULT '';
+ALTER TABLE users ALTER COLUMN age SET NOT NULL;
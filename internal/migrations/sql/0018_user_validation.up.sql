@@ -0,0 +1,13 @@
+-- Email хранится в нижнем регистре. Адреса, которые совпадают с чужими без учёта регистра,
+-- не трогаются: такие аккаунты не найти по email, их нужно объединить вручную.
+UPDATE users u SET email = LOWER(u.email)
+WHERE u.email <> LOWER(u.email)
+  AND NOT EXISTS (SELECT 1 FROM users o WHERE o.id <> u.id AND LOWER(o.email) = LOWER(u.email));
+
+-- Возраст хранился произвольной строкой. Всё, что не является числом от 1 до 150, становится NULL.
+ALTER TABLE users ALTER COLUMN age DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN age DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN age TYPE SMALLINT
+    USING CASE WHEN btrim(age) ~ '^[0-9]{1,3}$' AND btrim(age)::INTEGER BETWEEN 1 AND 150
+               THEN btrim(age)::SMALLINT END;
+ALTER TABLE users ADD CONSTRAINT users_age_range CHECK (age BETWEEN 1 AND 150);
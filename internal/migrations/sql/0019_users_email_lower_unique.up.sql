@@ -0,0 +1,17 @@
+-- Адреса, совпадающие без учёта регистра, 0018 оставила как есть. Уникальный индекс по LOWER(email)
+-- с ними не создать, а выбрать, какой аккаунт главный, миграция не может. Поэтому при дублях она
+-- останавливается со списком адресов: аккаунты нужно объединить вручную и повторить migrate up.
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(email, ', ' ORDER BY email) INTO duplicates
+    FROM (SELECT LOWER(email) AS email FROM users GROUP BY LOWER(email) HAVING COUNT(*) > 1) d;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'users with case-insensitively equal emails must be merged first: %', duplicates;
+    END IF;
+END $$;
+
+UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email);
+
+CREATE UNIQUE INDEX users_email_lower_key ON users (LOWER(email));
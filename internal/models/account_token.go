@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type AccountTokenPurpose string
+
+const (
+	PurposeEmailVerification AccountTokenPurpose = "email_verification"
+	PurposePasswordReset     AccountTokenPurpose = "password_reset"
+)
+
+// AccountToken - одноразовый токен из письма. Сам токен уходит пользователю, в базе только хэш.
+type AccountToken struct {
+	ID        int64               `db:"id" json:"id"`
+	UserID    int                 `db:"user_id" json:"user_id"`
+	Purpose   AccountTokenPurpose `db:"purpose" json:"purpose"`
+	TokenHash string              `db:"token_hash" json:"-"`
+	ExpiresAt time.Time           `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time          `db:"used_at" json:"used_at"`
+	CreatedAt time.Time           `db:"created_at" json:"created_at"`
+}
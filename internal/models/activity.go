@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Activity - запись журнала действий на доске. Before и After хранят состояние сущности
+// до и после изменения; для создания Before равен null, для удаления - After.
+type Activity struct {
+	ID         int64          `db:"id" json:"id"`
+	BoardID    int            `db:"board_id" json:"board_id"`
+	CardID     *int           `db:"card_id" json:"card_id,omitempty"`
+	ActorID    *int           `db:"actor_id" json:"actor_id"`
+	ActorName  string         `db:"actor_name" json:"actor_name"`
+	Action     string         `db:"action" json:"action"`
+	EntityType string         `db:"entity_type" json:"entity_type"`
+	EntityID   int            `db:"entity_id" json:"entity_id"`
+	Before     types.JSONText `db:"before" json:"before"`
+	After      types.JSONText `db:"after" json:"after"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+type ActivityPage struct {
+	Entries    []Activity `json:"entries"`
+	NextCursor *string    `json:"next_cursor"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type CardAssignee struct {
+	CardID     int       `db:"card_id" json:"-"`
+	UserID     int       `db:"user_id" json:"user_id"`
+	Name       string    `db:"name" json:"name"`
+	AssignedAt time.Time `db:"assigned_at" json:"assigned_at"`
+}
+
+// BoardCards - карточки одной доски, назначенные пользователю.
+type BoardCards struct {
+	BoardID   int    `json:"board_id"`
+	BoardName string `json:"board_name"`
+	Cards     []Card `json:"cards"`
+}
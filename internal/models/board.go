@@ -3,38 +3,60 @@ package models
 import "time"
 
 type Card struct {
-	ID          int       `db:"id" json:"id"`
-	Title       string    `db:"title" json:"title"`
-	Description string    `db:"description" json:"description"`
-	Position    float64   `db:"position" json:"position"`
-	ListID      int       `db:"list_id" json:"list_id"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	ID          int        `db:"id" json:"id"`
+	Title       string     `db:"title" json:"title"`
+	Description string     `db:"description" json:"description"`
+	Position    float64    `db:"position" json:"position"`
+	ListID      int        `db:"list_id" json:"list_id"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	ArchivedAt  *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+
+	StartAt   *time.Time `db:"start_at" json:"start_at"`
+	DueAt     *time.Time `db:"due_at" json:"due_at"`
+	Completed bool       `db:"completed" json:"completed"`
+	// Отметки о разосланных уведомлениях: каждый порог срабатывает один раз на срок.
+	DueSoonNotifiedAt *time.Time `db:"due_soon_notified_at" json:"-"`
+	OverdueNotifiedAt *time.Time `db:"overdue_notified_at" json:"-"`
+
+	Labels            []Label            `json:"labels,omitempty"`
+	Assignees         []CardAssignee     `json:"assignees,omitempty"`
+	ChecklistProgress *ChecklistProgress `json:"checklist_progress,omitempty"`
 }
 
 type List struct {
-	ID        int       `db:"id" json:"id"`
-	Title     string    `db:"title" json:"title"`
-	Position  float64   `db:"position" json:"position"`
-	BoardID   int       `db:"board_id" json:"board_id"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	ID         int        `db:"id" json:"id"`
+	Title      string     `db:"title" json:"title"`
+	Position   float64    `db:"position" json:"position"`
+	BoardID    int        `db:"board_id" json:"board_id"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+	// BoardArchivedAt заполняет только ListRepository.GetByID: по нему сервисы не дают менять содержимое архивной доски.
+	BoardArchivedAt *time.Time `db:"board_archived_at" json:"-"`
 
 	Cards []Card `json:"cards,omitempty"`
 }
 
 type Board struct {
-	ID        int       `db:"id" json:"id"`
-	Name      string    `db:"name" json:"name"`
-	OwnerID   int       `db:"owner_id" json:"owner_id"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-
-	Lists   []List `json:"lists,omitempty"`
-	Members []User `json:"members,omitempty"`
+	ID         int        `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	OwnerID    int        `db:"owner_id" json:"owner_id"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+
+	Lists   []List        `json:"lists,omitempty"`
+	Members []BoardMember `json:"members,omitempty"`
+	Labels  []Label       `json:"labels,omitempty"`
 }
 
 type WebSocketMessage struct {
 	Event   string      `json:"event"`
 	Payload interface{} `json:"payload"`
 }
+
+type DueCards struct {
+	Overdue []Card `json:"overdue"`
+	DueSoon []Card `json:"due_soon"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// CardExportRow - строка CSV-выгрузки карточек. Метки и исполнители уже склеены
+// в одну строку через "; ", чтобы выгрузка шла одним запросом без догрузок.
+type CardExportRow struct {
+	ID          int        `db:"id"`
+	Title       string     `db:"title"`
+	Description string     `db:"description"`
+	ListID      int        `db:"list_id"`
+	ListTitle   string     `db:"list_title"`
+	Position    float64    `db:"position"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	ArchivedAt  *time.Time `db:"archived_at"`
+	StartAt     *time.Time `db:"start_at"`
+	DueAt       *time.Time `db:"due_at"`
+	Completed   bool       `db:"completed"`
+	Labels      string     `db:"labels"`
+	Assignees   string     `db:"assignees"`
+}
+
+// CardExportFilter сужает CSV-выгрузку. Пустые поля не фильтруют.
+type CardExportFilter struct {
+	ListID          *int
+	LabelID         *int
+	AssigneeID      *int
+	DueFrom         *time.Time
+	DueTo           *time.Time
+	Completed       *bool
+	IncludeArchived bool
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type Checklist struct {
+	ID        int       `db:"id" json:"id"`
+	CardID    int       `db:"card_id" json:"card_id"`
+	Title     string    `db:"title" json:"title"`
+	Position  float64   `db:"position" json:"position"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Items []ChecklistItem `json:"items"`
+}
+
+type ChecklistItem struct {
+	ID          int       `db:"id" json:"id"`
+	ChecklistID int       `db:"checklist_id" json:"checklist_id"`
+	Title       string    `db:"title" json:"title"`
+	Checked     bool      `db:"checked" json:"checked"`
+	Position    float64   `db:"position" json:"position"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// ChecklistProgress - сводка по всем чек-листам карточки, например 3 из 7.
+type ChecklistProgress struct {
+	Checked int `db:"checked" json:"checked"`
+	Total   int `db:"total" json:"total"`
+}
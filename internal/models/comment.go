@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Comment struct {
+	ID         int       `db:"id" json:"id"`
+	CardID     int       `db:"card_id" json:"card_id"`
+	AuthorID   int       `db:"author_id" json:"author_id"`
+	AuthorName string    `db:"author_name" json:"author_name"`
+	Body       string    `db:"body" json:"body"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type CommentPage struct {
+	Comments []Comment `json:"comments"`
+	Total    int       `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+const (
+	BoardExportFormat  = "notes-board"
+	BoardExportVersion = 1
+)
+
+// BoardExport - переносимый снимок доски. Ссылки между сущностями сделаны по именам,
+// а не по id, чтобы документ можно было импортировать в другую базу.
+type BoardExport struct {
+	Format     string           `json:"format"`
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Name       string           `json:"name"`
+	Labels     []ExportedLabel  `json:"labels"`
+	Lists      []ExportedList   `json:"lists"`
+	Members    []ExportedMember `json:"members"`
+}
+
+type ExportedLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type ExportedList struct {
+	Title    string         `json:"title"`
+	Position float64        `json:"position"`
+	Archived bool           `json:"archived,omitempty"`
+	Cards    []ExportedCard `json:"cards"`
+}
+
+type ExportedCard struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Position    float64    `json:"position"`
+	StartAt     *time.Time `json:"start_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Completed   bool       `json:"completed,omitempty"`
+	Archived    bool       `json:"archived,omitempty"`
+	Labels      []string   `json:"labels,omitempty"`
+}
+
+type ExportedMember struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Role  BoardRole `json:"role"`
+}
+
+// BoardImportResult описывает, что было создано при импорте. Участники документа получают
+// приглашения, а не членство; по ответу нельзя узнать, какие из адресов зарегистрированы.
+type BoardImportResult struct {
+	Board       Board `json:"board"`
+	Lists       int   `json:"lists"`
+	Cards       int   `json:"cards"`
+	Labels      int   `json:"labels"`
+	Invitations int   `json:"invitations"`
+}
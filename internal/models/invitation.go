@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Invitation struct {
+	ID         int        `db:"id" json:"id"`
+	BoardID    int        `db:"board_id" json:"board_id"`
+	Email      string     `db:"email" json:"email"`
+	Role       BoardRole  `db:"role" json:"role"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	InvitedBy  int        `db:"invited_by" json:"invited_by"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type Label struct {
+	ID        int       `db:"id" json:"id"`
+	BoardID   int       `db:"board_id" json:"board_id"`
+	Name      string    `db:"name" json:"name"`
+	Color     string    `db:"color" json:"color"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+type BoardRole string
+
+const (
+	RoleOwner    BoardRole = "owner"
+	RoleAdmin    BoardRole = "admin"
+	RoleMember   BoardRole = "member"
+	RoleObserver BoardRole = "observer"
+)
+
+var roleRanks = map[BoardRole]int{
+	RoleObserver: 1,
+	RoleMember:   2,
+	RoleAdmin:    3,
+	RoleOwner:    4,
+}
+
+func (r BoardRole) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast сообщает, даёт ли роль r не меньше прав, чем min.
+func (r BoardRole) AtLeast(min BoardRole) bool {
+	return r.IsValid() && roleRanks[r] >= roleRanks[min]
+}
+
+type BoardMember struct {
+	UserID int       `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+	Email  string    `db:"email" json:"email"`
+	Role   BoardRole `db:"role" json:"role"`
+}
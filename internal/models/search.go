@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// SearchResult - одно совпадение поиска. Type - board, list, card или comment;
+// ListID и CardID заполнены, если совпадение находится внутри списка или карточки.
+type SearchResult struct {
+	Type      string  `db:"type" json:"type"`
+	ID        int     `db:"id" json:"id"`
+	BoardID   int     `db:"board_id" json:"board_id"`
+	BoardName string  `db:"board_name" json:"board_name"`
+	ListID    *int    `db:"list_id" json:"list_id,omitempty"`
+	CardID    *int    `db:"card_id" json:"card_id,omitempty"`
+	Title     string  `db:"title" json:"title"`
+	Snippet   string  `db:"snippet" json:"snippet"`
+	Rank      float64 `db:"rank" json:"rank"`
+}
+
+// SearchFilters сужает поиск. Фильтры по метке, исполнителю и сроку относятся к карточкам,
+// поэтому с ними в выдачу попадают только карточки и комментарии к ним.
+type SearchFilters struct {
+	Query      string
+	BoardID    *int
+	LabelID    *int
+	AssigneeID *int
+	DueFrom    *time.Time
+	DueTo      *time.Time
+	Limit      int
+}
+
+// CardOnly сообщает, задан ли хотя бы один фильтр, имеющий смысл только для карточек.
+func (f SearchFilters) CardOnly() bool {
+	return f.LabelID != nil || f.AssigneeID != nil || f.DueFrom != nil || f.DueTo != nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// TokenPair выдаётся при входе и при каждом обновлении. ExpiresIn - время жизни
+// access-токена в секундах.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type RefreshToken struct {
+	ID        int64      `db:"id"`
+	UserID    int        `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// AccessClaims - проверенное содержимое access-токена.
+type AccessClaims struct {
+	UserID    int
+	TokenID   string
+	FamilyID  string
+	ExpiresAt time.Time
+}
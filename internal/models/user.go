@@ -3,12 +3,52 @@ package models
 import "time"
 
 type User struct {
-	ID           int       `db:"id" json:"id"`
-	Name         string    `db:"name" json:"name"`
-	Age          string    `db:"age" json:"age"`
-	Email        string    `db:"email" json:"email"`
-	Password     string    `db:"-" json:"password,omitempty"`
-	PasswordHash string    `db:"password_hash" json:"-"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	ID              int        `db:"id" json:"id"`
+	Name            string     `db:"name" json:"name"`
+	Age             *int       `db:"age" json:"age"`
+	Email           string     `db:"email" json:"email"`
+	IsAdmin         bool       `db:"is_admin" json:"is_admin"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`
+	Password        string     `db:"-" json:"password,omitempty"`
+	PasswordHash    string     `db:"password_hash" json:"-"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// PublicUser - профиль, который видят другие пользователи: без email и служебных полей.
+type PublicUser struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (u *User) Public() PublicUser {
+	return PublicUser{ID: u.ID, Name: u.Name, CreatedAt: u.CreatedAt}
+}
+
+// UserDeletion описывает, что стало с досками удалённого пользователя: доски с другими
+// участниками переходят к преемнику, доски без участников удаляются вместе с владельцем.
+type UserDeletion struct {
+	TransferredBoards []BoardTransfer `json:"transferred_boards"`
+	DeletedBoards     []int           `json:"deleted_boards"`
+	// MemberBoards - чужие доски, где пользователь был участником; его участие удаляется каскадом.
+	MemberBoards []int `json:"-"`
+	// BlobKeys - ключи файлов вложений удалённых досок, которые нужно убрать из хранилища.
+	BlobKeys []string `json:"-"`
+}
+
+type BoardTransfer struct {
+	BoardID    int `db:"board_id" json:"board_id"`
+	NewOwnerID int `db:"new_owner_id" json:"new_owner_id"`
+}
+
+// UserUpdate - частичное изменение профиля; nil означает "не менять". IsAdmin меняет только администратор.
+type UserUpdate struct {
+	Name    *string
+	Age     *int
+	IsAdmin *bool
 }
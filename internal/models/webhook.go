@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// Webhook - подписка доски на исходящие уведомления. Пустой Events означает все события.
+// Secret отдаётся клиенту только при создании.
+type Webhook struct {
+	ID        int            `db:"id" json:"id"`
+	BoardID   int            `db:"board_id" json:"board_id"`
+	URL       string         `db:"url" json:"url"`
+	Secret    string         `db:"secret" json:"secret,omitempty"`
+	Events    pq.StringArray `db:"events" json:"events"`
+	Active    bool           `db:"active" json:"active"`
+	CreatedBy *int           `db:"created_by" json:"created_by"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookDelivery - запись журнала доставок. Payload - то же сообщение, что уходит в WebSocket.
+type WebhookDelivery struct {
+	ID             int64          `db:"id" json:"id"`
+	WebhookID      int            `db:"webhook_id" json:"webhook_id"`
+	Event          string         `db:"event" json:"event"`
+	Payload        types.JSONText `db:"payload" json:"payload"`
+	Status         string         `db:"status" json:"status"`
+	Attempts       int            `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode *int           `db:"last_status_code" json:"last_status_code"`
+	LastError      *string        `db:"last_error" json:"last_error"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time     `db:"delivered_at" json:"delivered_at"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+// ClaimedWebhookDelivery - доставка, взятая воркером в работу, вместе с адресом и секретом подписки.
+type ClaimedWebhookDelivery struct {
+	ID        int64          `db:"id"`
+	WebhookID int            `db:"webhook_id"`
+	Event     string         `db:"event"`
+	Payload   types.JSONText `db:"payload"`
+	Attempts  int            `db:"attempts"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"`
+}
+
+// WebhookAttempt - итог одной попытки доставки. StatusCode пуст, если ответа не было.
+type WebhookAttempt struct {
+	Status        string
+	StatusCode    *int
+	Error         *string
+	NextAttemptAt time.Time
+	At            time.Time
+}
@@ -0,0 +1,34 @@
+package ordering
+
+// Step - расстояние между соседями при добавлении в конец и после перебалансировки.
+const Step = 1024.0
+
+// MinGap - минимальный зазор, при котором ещё можно вставить элемент между соседями.
+// Если зазор меньше, список нужно перебалансировать перед вставкой.
+const MinGap = 1e-6
+
+// RebalanceGap - порог, начиная с которого фоновая задача заранее перебалансирует список.
+const RebalanceGap = 1e-3
+
+// Between возвращает позицию строго между prev и next. nil означает отсутствие соседа
+// с этой стороны. ok=false сообщает, что соседи стоят слишком плотно.
+func Between(prev, next *float64) (position float64, ok bool) {
+	switch {
+	case prev == nil && next == nil:
+		return Step, true
+	case prev == nil:
+		return *next - Step, true
+	case next == nil:
+		return *prev + Step, true
+	}
+
+	if *next-*prev < MinGap {
+		return 0, false
+	}
+	return *prev + (*next-*prev)/2, true
+}
+
+// After возвращает позицию для добавления в конец, если max - текущая максимальная позиция.
+func After(max float64) float64 {
+	return max + Step
+}
@@ -0,0 +1,51 @@
+package ordering
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		prev *float64
+		next *float64
+		want float64
+		ok   bool
+	}{
+		{name: "empty list", want: Step, ok: true},
+		{name: "insert at head", next: ptr(1024), want: 0, ok: true},
+		{name: "insert at tail", prev: ptr(2048), want: 3072, ok: true},
+		{name: "insert in the middle", prev: ptr(1024), next: ptr(2048), want: 1536, ok: true},
+		{name: "neighbours too dense", prev: ptr(1), next: ptr(1 + MinGap/2), ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Between(tt.prev, tt.next)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBetween_RepeatedInsertsStayOrdered(t *testing.T) {
+	// Многократная вставка в одно и то же место должна сохранять порядок, пока не потребуется перебалансировка.
+	prev, next := 1024.0, 2048.0
+	for i := 0; i < 100; i++ {
+		pos, ok := Between(&prev, &next)
+		if !ok {
+			assert.Greater(t, i, 20)
+			return
+		}
+		assert.Greater(t, pos, prev)
+		assert.Less(t, pos, next)
+		next = pos
+	}
+	t.Fatal("expected the gap to become too dense")
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AccountTokenRepository interface {
+	Create(ctx context.Context, token *models.AccountToken) error
+	Consume(ctx context.Context, purpose models.AccountTokenPurpose, tokenHash string) (*models.AccountToken, error)
+}
+
+type accountTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountTokenRepository(db *sqlx.DB) AccountTokenRepository {
+	return &accountTokenRepository{db: db}
+}
+
+// Create сохраняет новый токен и гасит неиспользованные токены пользователя с тем же
+// назначением: действует только ссылка из последнего письма.
+func (r *accountTokenRepository) Create(ctx context.Context, token *models.AccountToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("accountTokenRepository.Create: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE account_tokens SET used_at=NOW()
+			  WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL`, token.UserID, token.Purpose); err != nil {
+		return fmt.Errorf("accountTokenRepository.Create: failed to expire previous tokens: %w", err)
+	}
+
+	query := `INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at)
+			  VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at`
+	row := tx.QueryRowxContext(ctx, query, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt)
+	if err := row.Scan(&token.ID, &token.CreatedAt); err != nil {
+		return fmt.Errorf("accountTokenRepository.Create: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("accountTokenRepository.Create: failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Consume помечает токен использованным одним условным UPDATE, поэтому из двух
+// одновременных запросов с одним токеном успешен только один. Неизвестный, истёкший
+// или уже использованный токен даёт sql.ErrNoRows.
+func (r *accountTokenRepository) Consume(ctx context.Context, purpose models.AccountTokenPurpose, tokenHash string) (*models.AccountToken, error) {
+	var token models.AccountToken
+	query := `UPDATE account_tokens SET used_at=NOW()
+			  WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > NOW()
+			  RETURNING *`
+	if err := r.db.GetContext(ctx, &token, query, tokenHash, purpose); err != nil {
+		return nil, fmt.Errorf("accountTokenRepository.Consume: %w", err)
+	}
+	return &token, nil
+}
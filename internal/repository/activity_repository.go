@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ActivityRepository interface {
+	Create(ctx context.Context, activity *models.Activity) error
+	GetPageByBoardID(ctx context.Context, boardID int, beforeID int64, limit int) ([]models.Activity, error)
+	GetPageByCardID(ctx context.Context, cardID int, beforeID int64, limit int) ([]models.Activity, error)
+}
+
+type activityRepository struct {
+	db *sqlx.DB
+}
+
+func NewActivityRepository(db *sqlx.DB) ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+func (r *activityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	query := `WITH inserted AS (
+				INSERT INTO activity (board_id, card_id, actor_id, action, entity_type, entity_id, before, after)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				RETURNING id, created_at, actor_id
+			  )
+			  SELECT inserted.id, inserted.created_at, COALESCE(u.name, '')
+			  FROM inserted LEFT JOIN users u ON u.id = inserted.actor_id`
+	row := r.db.QueryRowxContext(ctx, query, activity.BoardID, activity.CardID, activity.ActorID, activity.Action,
+		activity.EntityType, activity.EntityID, activity.Before, activity.After)
+	if err := row.Scan(&activity.ID, &activity.CreatedAt, &activity.ActorName); err != nil {
+		return fmt.Errorf("activityRepository.Create: %w", err)
+	}
+	return nil
+}
+
+// GetPageByBoardID возвращает до limit записей доски с id меньше beforeID, от новых к старым.
+// beforeID = 0 означает первую страницу.
+func (r *activityRepository) GetPageByBoardID(ctx context.Context, boardID int, beforeID int64, limit int) ([]models.Activity, error) {
+	entries := []models.Activity{}
+	query := `SELECT a.*, COALESCE(u.name, '') AS actor_name FROM activity a
+			  LEFT JOIN users u ON u.id = a.actor_id
+			  WHERE a.board_id=$1 AND ($2 = 0 OR a.id < $2)
+			  ORDER BY a.id DESC
+			  LIMIT $3`
+	if err := r.db.SelectContext(ctx, &entries, query, boardID, beforeID, limit); err != nil {
+		return nil, fmt.Errorf("activityRepository.GetPageByBoardID: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *activityRepository) GetPageByCardID(ctx context.Context, cardID int, beforeID int64, limit int) ([]models.Activity, error) {
+	entries := []models.Activity{}
+	query := `SELECT a.*, COALESCE(u.name, '') AS actor_name FROM activity a
+			  LEFT JOIN users u ON u.id = a.actor_id
+			  WHERE a.card_id=$1 AND ($2 = 0 OR a.id < $2)
+			  ORDER BY a.id DESC
+			  LIMIT $3`
+	if err := r.db.SelectContext(ctx, &entries, query, cardID, beforeID, limit); err != nil {
+		return nil, fmt.Errorf("activityRepository.GetPageByCardID: %w", err)
+	}
+	return entries, nil
+}
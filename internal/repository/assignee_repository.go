@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AssigneeRepository interface {
+	Assign(ctx context.Context, cardID, userID int) error
+	Unassign(ctx context.Context, cardID, userID int) error
+	GetByCardID(ctx context.Context, cardID int) ([]models.CardAssignee, error)
+	GetByCardIDs(ctx context.Context, cardIDs []int) (map[int][]models.CardAssignee, error)
+	GetCardsForUser(ctx context.Context, userID int, boardIDs []int) (map[int][]models.Card, error)
+}
+
+type assigneeRepository struct {
+	db *sqlx.DB
+}
+
+func NewAssigneeRepository(db *sqlx.DB) AssigneeRepository {
+	return &assigneeRepository{db: db}
+}
+
+func (r *assigneeRepository) Assign(ctx context.Context, cardID, userID int) error {
+	query := `INSERT INTO card_assignees (card_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := r.db.ExecContext(ctx, query, cardID, userID); err != nil {
+		return fmt.Errorf("assigneeRepository.Assign: %w", err)
+	}
+	return nil
+}
+
+func (r *assigneeRepository) Unassign(ctx context.Context, cardID, userID int) error {
+	query := `DELETE FROM card_assignees WHERE card_id=$1 AND user_id=$2`
+	result, err := r.db.ExecContext(ctx, query, cardID, userID)
+	if err != nil {
+		return fmt.Errorf("assigneeRepository.Unassign: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("assigneeRepository.Unassign: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %d is not assigned to card %d: %w", userID, cardID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *assigneeRepository) GetByCardID(ctx context.Context, cardID int) ([]models.CardAssignee, error) {
+	assignees := []models.CardAssignee{}
+	query := `SELECT ca.card_id, ca.user_id, u.name, ca.assigned_at FROM card_assignees ca
+			  JOIN users u ON u.id = ca.user_id
+			  WHERE ca.card_id=$1
+			  ORDER BY ca.assigned_at ASC, ca.user_id ASC`
+	if err := r.db.SelectContext(ctx, &assignees, query, cardID); err != nil {
+		return nil, fmt.Errorf("assigneeRepository.GetByCardID: %w", err)
+	}
+	return assignees, nil
+}
+
+func (r *assigneeRepository) GetByCardIDs(ctx context.Context, cardIDs []int) (map[int][]models.CardAssignee, error) {
+	if len(cardIDs) == 0 {
+		return make(map[int][]models.CardAssignee), nil
+	}
+
+	query, args, err := sqlx.In(`SELECT ca.card_id, ca.user_id, u.name, ca.assigned_at FROM card_assignees ca
+			  JOIN users u ON u.id = ca.user_id
+			  WHERE ca.card_id IN (?)
+			  ORDER BY ca.assigned_at ASC, ca.user_id ASC`, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("assigneeRepository.GetByCardIDs: failed to build query: %w", err)
+	}
+
+	var assignees []models.CardAssignee
+	if err := r.db.SelectContext(ctx, &assignees, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("assigneeRepository.GetByCardIDs: %w", err)
+	}
+
+	assigneesByCardID := make(map[int][]models.CardAssignee)
+	for _, assignee := range assignees {
+		assigneesByCardID[assignee.CardID] = append(assigneesByCardID[assignee.CardID], assignee)
+	}
+	return assigneesByCardID, nil
+}
+
+// GetCardsForUser возвращает назначенные пользователю карточки на указанных досках,
+// сгруппированные по id доски. Карточки из архивных списков не попадают в выборку.
+func (r *assigneeRepository) GetCardsForUser(ctx context.Context, userID int, boardIDs []int) (map[int][]models.Card, error) {
+	if len(boardIDs) == 0 {
+		return make(map[int][]models.Card), nil
+	}
+
+	query, args, err := sqlx.In(`SELECT l.board_id, `+cardColumns+` FROM cards c
+			  JOIN lists l ON l.id = c.list_id
+			  JOIN card_assignees ca ON ca.card_id = c.id
+			  WHERE ca.user_id = ? AND l.board_id IN (?) AND l.archived_at IS NULL AND c.archived_at IS NULL
+			  ORDER BY l.board_id ASC, l."position" ASC, c."position" ASC, c.id ASC`, userID, boardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("assigneeRepository.GetCardsForUser: failed to build query: %w", err)
+	}
+
+	var rows []struct {
+		BoardID int `db:"board_id"`
+		models.Card
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("assigneeRepository.GetCardsForUser: %w", err)
+	}
+
+	cardsByBoardID := make(map[int][]models.Card)
+	for _, row := range rows {
+		cardsByBoardID[row.BoardID] = append(cardsByBoardID[row.BoardID], row.Card)
+	}
+	return cardsByBoardID, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *models.Attachment) error
+	GetByID(ctx context.Context, attachmentID int) (*models.Attachment, error)
+	GetAllByCardID(ctx context.Context, cardID int) ([]models.Attachment, error)
+	Delete(ctx context.Context, attachmentID int) error
+}
+
+type attachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepository(db *sqlx.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// deleteAttachmentsOf удаляет вложения карточек, которые выбирает cardIDsQuery, и возвращает
+// их ключи в хранилище. Вызывается перед удалением карточек: каскад удалил бы строки,
+// а файлы остались бы в хранилище навсегда.
+func deleteAttachmentsOf(ctx context.Context, tx *sqlx.Tx, cardIDsQuery string, args ...interface{}) ([]string, error) {
+	keys := []string{}
+	query := `DELETE FROM attachments WHERE card_id IN (` + cardIDsQuery + `) RETURNING storage_key`
+	if err := tx.SelectContext(ctx, &keys, query, args...); err != nil {
+		return nil, fmt.Errorf("could not delete attachments: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	query := `INSERT INTO attachments (card_id, uploader_id, filename, content_type, size_bytes, storage_key)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at`
+	row := r.db.QueryRowxContext(ctx, query, attachment.CardID, attachment.UploaderID, attachment.Filename,
+		attachment.ContentType, attachment.SizeBytes, attachment.StorageKey)
+	if err := row.Scan(&attachment.ID, &attachment.CreatedAt); err != nil {
+		return fmt.Errorf("attachmentRepository.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, attachmentID int) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.GetContext(ctx, &attachment, `SELECT * FROM attachments WHERE id=$1`, attachmentID); err != nil {
+		return nil, fmt.Errorf("attachmentRepository.GetByID: %w", err)
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) GetAllByCardID(ctx context.Context, cardID int) ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	query := `SELECT * FROM attachments WHERE card_id=$1 ORDER BY created_at ASC, id ASC`
+	if err := r.db.SelectContext(ctx, &attachments, query, cardID); err != nil {
+		return nil, fmt.Errorf("attachmentRepository.GetAllByCardID: %w", err)
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, attachmentID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id=$1`, attachmentID); err != nil {
+		return fmt.Errorf("attachmentRepository.Delete: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type BoardImportRepository interface {
+	Import(ctx context.Context, ownerID int, data *models.BoardExport) (*models.BoardImportResult, error)
+}
+
+type boardImportRepository struct {
+	db *sqlx.DB
+}
+
+func NewBoardImportRepository(db *sqlx.DB) BoardImportRepository {
+	return &boardImportRepository{db: db}
+}
+
+// Import создаёт доску со всем содержимым в одной транзакции: при любой ошибке
+// в базе не остаётся частично созданной доски. Данные должны быть уже проверены сервисом.
+// Участники документа здесь не добавляются: их приглашает сервис.
+func (r *boardImportRepository) Import(ctx context.Context, ownerID int, data *models.BoardExport) (*models.BoardImportResult, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &models.BoardImportResult{}
+	board := &result.Board
+	board.Name = data.Name
+	board.OwnerID = ownerID
+	queryBoard := `INSERT INTO boards (name, owner_id) VALUES ($1, $2) RETURNING id, created_at, updated_at`
+	if err := tx.QueryRowxContext(ctx, queryBoard, board.Name, ownerID).Scan(&board.ID, &board.CreatedAt, &board.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("boardImportRepository.Import: board: %w", err)
+	}
+	queryOwner := `INSERT INTO board_members (board_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, queryOwner, board.ID, ownerID, models.RoleOwner); err != nil {
+		return nil, fmt.Errorf("boardImportRepository.Import: owner: %w", err)
+	}
+
+	labelIDs := make(map[string]int, len(data.Labels))
+	queryLabel := `INSERT INTO labels (board_id, name, color) VALUES ($1, $2, $3) RETURNING id`
+	for _, label := range data.Labels {
+		var labelID int
+		if err := tx.GetContext(ctx, &labelID, queryLabel, board.ID, label.Name, label.Color); err != nil {
+			return nil, fmt.Errorf("boardImportRepository.Import: label %q: %w", label.Name, err)
+		}
+		labelIDs[label.Name] = labelID
+		result.Labels++
+	}
+
+	queryList := `INSERT INTO lists (title, "position", board_id, archived_at)
+				  VALUES ($1, $2, $3, CASE WHEN $4 THEN NOW() END) RETURNING id`
+	queryCard := `INSERT INTO cards (title, description, "position", list_id, start_at, due_at, completed, archived_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $8 THEN NOW() END) RETURNING id`
+	queryCardLabel := `INSERT INTO card_labels (card_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, list := range data.Lists {
+		var listID int
+		if err := tx.GetContext(ctx, &listID, queryList, list.Title, list.Position, board.ID, list.Archived); err != nil {
+			return nil, fmt.Errorf("boardImportRepository.Import: list %q: %w", list.Title, err)
+		}
+		result.Lists++
+
+		for _, card := range list.Cards {
+			var cardID int
+			err := tx.GetContext(ctx, &cardID, queryCard, card.Title, card.Description, card.Position, listID,
+				card.StartAt, card.DueAt, card.Completed, card.Archived)
+			if err != nil {
+				return nil, fmt.Errorf("boardImportRepository.Import: card %q: %w", card.Title, err)
+			}
+			for _, name := range card.Labels {
+				if _, err := tx.ExecContext(ctx, queryCardLabel, cardID, labelIDs[name]); err != nil {
+					return nil, fmt.Errorf("boardImportRepository.Import: card %q label %q: %w", card.Title, name, err)
+				}
+			}
+			result.Cards++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("boardImportRepository.Import: commit: %w", err)
+	}
+	return result, nil
+}
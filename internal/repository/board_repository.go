@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"notes-project/internal/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -11,15 +13,29 @@ import (
 type BoardRepository interface {
 	Create(ctx context.Context, board *models.Board) error
 	GetByID(ctx context.Context, boardID int) (*models.Board, error)
-	GetAllForUser(ctx context.Context, userID int) ([]models.Board, error)
-	Update(ctx context.Context, boardID, ownerID int, name string) error
-	Delete(ctx context.Context, boardID, ownerID int) error
+	GetAllForUser(ctx context.Context, userID int, includeArchived bool) ([]models.Board, error)
+	Update(ctx context.Context, boardID int, name string) error
+	Archive(ctx context.Context, boardID int) error
+	Restore(ctx context.Context, boardID int) error
+	PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error)
 
-	AddMember(ctx context.Context, boardID, userID int) error
+	AddMember(ctx context.Context, boardID, userID int, role models.BoardRole) (bool, error)
 	RemoveMember(ctx context.Context, boardID, userID int) error
-	IsMemberOrOwner(ctx context.Context, boardID, userID int) (bool, error)
+	GetMemberRole(ctx context.Context, boardID, userID int) (models.BoardRole, error)
+	UpdateMemberRole(ctx context.Context, boardID, userID int, role models.BoardRole) error
+	GetMembers(ctx context.Context, boardID int) ([]models.BoardMember, error)
+	TransferOwnership(ctx context.Context, boardID, oldOwnerID, newOwnerID int) error
 }
 
+// boardColumns перечисляет колонки доски явно: search_vector в модель не читается.
+const boardColumns = `b.id, b.name, b.owner_id, b.created_at, b.updated_at, b.archived_at`
+
+// boardMembershipFrom - источник досок, доступных пользователю $1. Им пользуются и список
+// досок, и поиск, чтобы правило доступа было описано в одном месте.
+const boardMembershipFrom = `boards b
+			  LEFT JOIN board_members bm ON b.id = bm.board_id
+			  WHERE (b.owner_id = $1 OR bm.user_id = $1)`
+
 type boardRepository struct {
 	db *sqlx.DB
 }
@@ -40,29 +56,28 @@ func (r *boardRepository) Create(ctx context.Context, board *models.Board) error
 
 func (r *boardRepository) GetByID(ctx context.Context, boardID int) (*models.Board, error) {
 	var board models.Board
-	query := `SELECT * FROM boards WHERE id=$1`
+	query := `SELECT ` + boardColumns + ` FROM boards b WHERE b.id=$1`
 	if err := r.db.GetContext(ctx, &board, query, boardID); err != nil {
 		return nil, fmt.Errorf("boardRepository.GetByID: %w", err)
 	}
 	return &board, nil
 }
 
-func (r *boardRepository) GetAllForUser(ctx context.Context, userID int) ([]models.Board, error) {
+func (r *boardRepository) GetAllForUser(ctx context.Context, userID int, includeArchived bool) ([]models.Board, error) {
 	var boards []models.Board
 
-	query := `SELECT DISTINCT b.* FROM boards b
-			  LEFT JOIN board_members bm ON b.id = bm.board_id
-			  WHERE b.owner_id = $1 OR bm.user_id = $1
+	query := `SELECT DISTINCT ` + boardColumns + ` FROM ` + boardMembershipFrom + `
+			  AND ($2 OR b.archived_at IS NULL)
 			  ORDER BY b.updated_at DESC`
-	if err := r.db.SelectContext(ctx, &boards, query, userID); err != nil {
+	if err := r.db.SelectContext(ctx, &boards, query, userID, includeArchived); err != nil {
 		return nil, fmt.Errorf("boardRepository.GetAllForUser: %w", err)
 	}
 	return boards, nil
 }
 
-func (r *boardRepository) Update(ctx context.Context, boardID, ownerID int, name string) error {
-	query := `UPDATE boards SET name=$1, updated_at=NOW() WHERE id=$2 AND owner_id=$3`
-	result, err := r.db.ExecContext(ctx, query, name, boardID, ownerID)
+func (r *boardRepository) Update(ctx context.Context, boardID int, name string) error {
+	query := `UPDATE boards SET name=$1, updated_at=NOW() WHERE id=$2`
+	result, err := r.db.ExecContext(ctx, query, name, boardID)
 	if err != nil {
 		return fmt.Errorf("boardRepository.Update: %w", err)
 	}
@@ -72,50 +87,183 @@ func (r *boardRepository) Update(ctx context.Context, boardID, ownerID int, name
 		return fmt.Errorf("boardRepository.Update: failed to get rows affected: %w", err)
 	}
 	if rowAffected == 0 {
-		return fmt.Errorf("board not found or user is not the owner")
+		return fmt.Errorf("board with id %d not found: %w", boardID, sql.ErrNoRows)
 	}
 	return nil
 }
 
-func (r *boardRepository) Delete(ctx context.Context, boardID, ownerID int) error {
-	query := `DELETE FROM boards WHERE id=$1 AND owner_id=$2`
-	result, err := r.db.ExecContext(ctx, query, boardID, ownerID)
+func (r *boardRepository) Archive(ctx context.Context, boardID int) error {
+	return r.setArchived(ctx, "Archive", `UPDATE boards SET archived_at=NOW(), updated_at=NOW()
+										  WHERE id=$1 AND archived_at IS NULL`, boardID, "already archived")
+}
+
+func (r *boardRepository) Restore(ctx context.Context, boardID int) error {
+	return r.setArchived(ctx, "Restore", `UPDATE boards SET archived_at=NULL, updated_at=NOW()
+										  WHERE id=$1 AND archived_at IS NOT NULL`, boardID, "not archived")
+}
+
+func (r *boardRepository) setArchived(ctx context.Context, method, query string, boardID int, state string) error {
+	result, err := r.db.ExecContext(ctx, query, boardID)
 	if err != nil {
-		return fmt.Errorf("boardRepository.Delete: %w", err)
+		return fmt.Errorf("boardRepository.%s: %w", method, err)
 	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("boardRepository.Delete: failed to get rows affected: %w", err)
+		return fmt.Errorf("boardRepository.%s: failed to get rows affected: %w", method, err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("board not found or user is not the owner")
+		return fmt.Errorf("board with id %d not found or %s: %w", boardID, state, sql.ErrNoRows)
 	}
 	return nil
 }
 
-func (r *boardRepository) AddMember(ctx context.Context, boardID, userID int) error {
-	query := `INSERT INTO board_members (board_id, user_id) VALUES ($1, $2)
+// PurgeArchived окончательно удаляет доски, архивированные раньше before, со всем содержимым. Возвращает число удалённых
+// строк и ключи файлов их вложений, которые нужно удалить из хранилища.
+func (r *boardRepository) PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keys, err := deleteAttachmentsOf(ctx, tx, `SELECT c.id FROM cards c JOIN lists l ON l.id = c.list_id
+						JOIN boards b ON b.id = l.board_id WHERE b.archived_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("boardRepository.PurgeArchived: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM boards WHERE archived_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("boardRepository.PurgeArchived: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, fmt.Errorf("boardRepository.PurgeArchived: failed to get rows affected: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("boardRepository.PurgeArchived: commit: %w", err)
+	}
+	return purged, keys, nil
+}
+
+// AddMember возвращает false, если пользователь уже был участником доски и строка не добавлена.
+func (r *boardRepository) AddMember(ctx context.Context, boardID, userID int, role models.BoardRole) (bool, error) {
+	query := `INSERT INTO board_members (board_id, user_id, role) VALUES ($1, $2, $3)
 			  ON CONFLICT (user_id, board_id) DO NOTHING`
-	_, err := r.db.ExecContext(ctx, query, boardID, userID)
-	return err
+	result, err := r.db.ExecContext(ctx, query, boardID, userID, role)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("boardRepository.AddMember: failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
 }
 
+// RemoveMember вместе с участием снимает пользователя со всех карточек этой доски.
 func (r *boardRepository) RemoveMember(ctx context.Context, boardID, userID int) error {
-	query := `DELETE FROM board_members WHERE board_id=$1 AND user_id=$2`
-	_, err := r.db.ExecContext(ctx, query, boardID, userID)
-	return err
+	query := `WITH removed AS (
+				DELETE FROM board_members WHERE board_id=$1 AND user_id=$2 RETURNING user_id
+			  ), unassigned AS (
+				DELETE FROM card_assignees ca USING cards c, lists l
+				WHERE ca.card_id = c.id AND c.list_id = l.id AND l.board_id=$1
+				  AND ca.user_id IN (SELECT user_id FROM removed)
+			  )
+			  SELECT COUNT(*) FROM removed`
+	var rowsAffected int
+	if err := r.db.GetContext(ctx, &rowsAffected, query, boardID, userID); err != nil {
+		return fmt.Errorf("boardRepository.RemoveMember: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %d is not a member of board %d: %w", userID, boardID, sql.ErrNoRows)
+	}
+	return nil
 }
 
-func (r *boardRepository) IsMemberOrOwner(ctx context.Context, boardID, userID int) (bool, error) {
-	var exists bool
-	query := `SELECT EXISTS (
-				SELECT 1 FROM boards WHERE id=$1 AND owner_id=$2
-				UNION ALL
-				SELECT 1 FROM board_members WHERE board_id=$1 AND user_id=$2
+// GetMemberRole возвращает роль пользователя на доске или пустую строку, если он не участник.
+// Владелец из boards.owner_id считается владельцем, даже если строки в board_members нет.
+func (r *boardRepository) GetMemberRole(ctx context.Context, boardID, userID int) (models.BoardRole, error) {
+	var role models.BoardRole
+	query := `SELECT COALESCE(
+				(SELECT 'owner' FROM boards WHERE id=$1 AND owner_id=$2),
+				(SELECT role FROM board_members WHERE board_id=$1 AND user_id=$2),
+				''
 			  )`
-	err := r.db.GetContext(ctx, &exists, query, boardID, userID)
+	if err := r.db.GetContext(ctx, &role, query, boardID, userID); err != nil {
+		return "", fmt.Errorf("boardRepository.GetMemberRole: %w", err)
+	}
+	return role, nil
+}
+
+func (r *boardRepository) UpdateMemberRole(ctx context.Context, boardID, userID int, role models.BoardRole) error {
+	query := `UPDATE board_members SET role=$1 WHERE board_id=$2 AND user_id=$3`
+	result, err := r.db.ExecContext(ctx, query, role, boardID, userID)
 	if err != nil {
-		return false, fmt.Errorf("IsMemberOrOwner check failed: %w", err)
+		return fmt.Errorf("boardRepository.UpdateMemberRole: %w", err)
 	}
-	return exists, nil
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("boardRepository.UpdateMemberRole: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %d is not a member of board %d: %w", userID, boardID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *boardRepository) GetMembers(ctx context.Context, boardID int) ([]models.BoardMember, error) {
+	members := []models.BoardMember{}
+	query := `SELECT u.id AS user_id, u.name, u.email, bm.role
+			  FROM board_members bm
+			  JOIN users u ON u.id = bm.user_id
+			  WHERE bm.board_id = $1
+			  ORDER BY u.id`
+	if err := r.db.SelectContext(ctx, &members, query, boardID); err != nil {
+		return nil, fmt.Errorf("boardRepository.GetMembers: %w", err)
+	}
+	return members, nil
+}
+
+// TransferOwnership в одной транзакции меняет owner_id доски, делает нового владельца
+// владельцем в board_members и оставляет прежнего владельца участником.
+func (r *boardRepository) TransferOwnership(ctx context.Context, boardID, oldOwnerID, newOwnerID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queryBoard := `UPDATE boards SET owner_id=$1, updated_at=NOW() WHERE id=$2 AND owner_id=$3`
+	result, err := tx.ExecContext(ctx, queryBoard, newOwnerID, boardID, oldOwnerID)
+	if err != nil {
+		return fmt.Errorf("boardRepository.TransferOwnership: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("boardRepository.TransferOwnership: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("board not found or user is not the owner: %w", sql.ErrNoRows)
+	}
+
+	queryNewOwner := `UPDATE board_members SET role=$1 WHERE board_id=$2 AND user_id=$3`
+	result, err = tx.ExecContext(ctx, queryNewOwner, models.RoleOwner, boardID, newOwnerID)
+	if err != nil {
+		return fmt.Errorf("boardRepository.TransferOwnership: %w", err)
+	}
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("boardRepository.TransferOwnership: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user %d is not a member of board %d: %w", newOwnerID, boardID, sql.ErrNoRows)
+	}
+
+	queryOldOwner := `INSERT INTO board_members (board_id, user_id, role) VALUES ($1, $2, $3)
+					  ON CONFLICT (user_id, board_id) DO UPDATE SET role = EXCLUDED.role`
+	if _, err := tx.ExecContext(ctx, queryOldOwner, boardID, oldOwnerID, models.RoleMember); err != nil {
+		return fmt.Errorf("boardRepository.TransferOwnership: %w", err)
+	}
+
+	return tx.Commit()
 }
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CardExportRepository interface {
+	StreamByBoardID(ctx context.Context, boardID int, filter models.CardExportFilter, fn func(*models.CardExportRow) error) error
+}
+
+type cardExportRepository struct {
+	db *sqlx.DB
+}
+
+func NewCardExportRepository(db *sqlx.DB) CardExportRepository {
+	return &cardExportRepository{db: db}
+}
+
+// StreamByBoardID построчно передаёт карточки доски в fn, не загружая выборку в память целиком.
+// Ошибка из fn прерывает чтение и возвращается как есть.
+func (r *cardExportRepository) StreamByBoardID(ctx context.Context, boardID int, filter models.CardExportFilter, fn func(*models.CardExportRow) error) error {
+	query := `SELECT c.id, c.title, c.description, c.list_id, l.title AS list_title, c."position",
+				c.created_at, c.updated_at, c.archived_at, c.start_at, c.due_at, c.completed,
+				COALESCE((SELECT string_agg(lb.name, '; ' ORDER BY lb.name) FROM card_labels cl
+					JOIN labels lb ON lb.id = cl.label_id WHERE cl.card_id = c.id), '') AS labels,
+				COALESCE((SELECT string_agg(u.name, '; ' ORDER BY ca.assigned_at, u.id) FROM card_assignees ca
+					JOIN users u ON u.id = ca.user_id WHERE ca.card_id = c.id), '') AS assignees
+			  FROM cards c
+			  JOIN lists l ON l.id = c.list_id
+			  WHERE l.board_id = $1
+				AND ($2 OR (c.archived_at IS NULL AND l.archived_at IS NULL))
+				AND ($3::int IS NULL OR c.list_id = $3)
+				AND ($4::int IS NULL OR EXISTS (SELECT 1 FROM card_labels cl WHERE cl.card_id = c.id AND cl.label_id = $4))
+				AND ($5::int IS NULL OR EXISTS (SELECT 1 FROM card_assignees ca WHERE ca.card_id = c.id AND ca.user_id = $5))
+				AND ($6::timestamptz IS NULL OR c.due_at >= $6)
+				AND ($7::timestamptz IS NULL OR c.due_at <= $7)
+				AND ($8::boolean IS NULL OR c.completed = $8)
+			  ORDER BY l."position" ASC, l.id ASC, c."position" ASC, c.id ASC`
+
+	rows, err := r.db.QueryxContext(ctx, query, boardID, filter.IncludeArchived, filter.ListID, filter.LabelID,
+		filter.AssigneeID, filter.DueFrom, filter.DueTo, filter.Completed)
+	if err != nil {
+		return fmt.Errorf("cardExportRepository.StreamByBoardID: %w", err)
+	}
+	defer rows.Close()
+
+	var row models.CardExportRow
+	for rows.Next() {
+		if err := rows.StructScan(&row); err != nil {
+			return fmt.Errorf("cardExportRepository.StreamByBoardID: failed to scan row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("cardExportRepository.StreamByBoardID: %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"notes-project/internal/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -12,11 +13,27 @@ import (
 type CardRepository interface {
 	Create(ctx context.Context, card *models.Card) error
 	GetMaxPositionForList(ctx context.Context, listID int) (float64, error)
-	GetAllByListIDs(ctx context.Context, listIDs []int) (map[int][]models.Card, error)
+	GetAllByListIDs(ctx context.Context, listIDs []int, includeArchived bool) (map[int][]models.Card, error)
 	GetByID(ctx context.Context, cardID int) (*models.Card, error)
 	Move(ctx context.Context, cardID, newListID int, newPosition float64) error
+	MoveToBoard(ctx context.Context, cardID, newListID int, newPosition float64) ([]models.CardAssignee, error)
+	GetNeighbourPosition(ctx context.Context, listID int, position float64, before bool, excludeCardID int) (*float64, error)
+	Rebalance(ctx context.Context, listID int, step float64) error
+	GetDenseListIDs(ctx context.Context, minGap float64, limit int) ([]int, error)
+	Update(ctx context.Context, card *models.Card) error
+	Archive(ctx context.Context, cardID int) error
+	Restore(ctx context.Context, cardID int, position float64) error
+	PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error)
+	UpdateDates(ctx context.Context, card *models.Card) error
+	SetCompleted(ctx context.Context, card *models.Card) error
+	GetDueForBoard(ctx context.Context, boardID int, until time.Time) ([]models.Card, error)
+	ClaimDueSoon(ctx context.Context, now, until time.Time, limit int) (map[int][]models.Card, error)
+	ClaimOverdue(ctx context.Context, now time.Time, limit int) (map[int][]models.Card, error)
 }
 
+const cardColumns = `c.id, c.title, c.description, c."position", c.list_id, c.created_at, c.updated_at, c.archived_at,
+	c.start_at, c.due_at, c.completed, c.due_soon_notified_at, c.overdue_notified_at`
+
 type cardRepository struct {
 	db *sqlx.DB
 }
@@ -27,53 +44,129 @@ func NewCardRepository(db *sqlx.DB) CardRepository {
 
 func (r *cardRepository) GetByID(ctx context.Context, cardID int) (*models.Card, error) {
 	var card models.Card
-	query := `SELECT * FROM cards WHERE id=$1`
+	query := `SELECT ` + cardColumns + ` FROM cards c WHERE c.id=$1`
 	if err := r.db.GetContext(ctx, &card, query, cardID); err != nil {
 		return nil, fmt.Errorf("cardRepository.GetByID: %w", err)
 	}
 	return &card, nil
 }
 
-func (r cardRepository) Move(ctx context.Context, cardID, newListID int, newPosition float64) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+func (r *cardRepository) Move(ctx context.Context, cardID, newListID int, newPosition float64) error {
+	query := `UPDATE cards SET list_id = $1, "position" = $2, updated_at = NOW() WHERE id = $3`
+	result, err := r.db.ExecContext(ctx, query, newListID, newPosition, cardID)
 	if err != nil {
-		return fmt.Errorf("could not begin transaction: %w", err)
+		return fmt.Errorf("could not move card: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cardRepository.Move: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("card with id %d not found: %w", cardID, sql.ErrNoRows)
 	}
+	return nil
+}
 
+// MoveToBoard переносит карточку в список другой доски. В той же транзакции с карточки снимаются
+// метки прежней доски и исполнители, которые не состоят на новой; возвращаются оставшиеся исполнители.
+func (r *cardRepository) MoveToBoard(ctx context.Context, cardID, newListID int, newPosition float64) ([]models.CardAssignee, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
 	defer tx.Rollback()
 
-	var oldListID int
-	var oldPosition float64
-	queryGet := `SELECT list_id, "position" FROM cards WHERE id=$1 FOR UPDATE`
+	query := `UPDATE cards SET list_id = $1, "position" = $2, updated_at = NOW() WHERE id = $3`
+	result, err := tx.ExecContext(ctx, query, newListID, newPosition, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("cardRepository.MoveToBoard: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("cardRepository.MoveToBoard: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("card with id %d not found: %w", cardID, sql.ErrNoRows)
+	}
 
-	if err := tx.QueryRowContext(ctx, queryGet, cardID).Scan(&oldListID, &oldPosition); err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("card with id %d not found", cardID)
-		}
-		return fmt.Errorf("could not get current card state: %w", err)
+	queryLabels := `DELETE FROM card_labels cl USING labels l, lists li
+					WHERE cl.card_id = $1 AND l.id = cl.label_id AND li.id = $2 AND l.board_id <> li.board_id`
+	if _, err := tx.ExecContext(ctx, queryLabels, cardID, newListID); err != nil {
+		return nil, fmt.Errorf("cardRepository.MoveToBoard: labels: %w", err)
+	}
+	queryAssignees := `DELETE FROM card_assignees ca
+					   WHERE ca.card_id = $1 AND NOT EXISTS (
+						   SELECT 1 FROM board_members bm JOIN lists li ON li.board_id = bm.board_id
+						   WHERE li.id = $2 AND bm.user_id = ca.user_id)`
+	if _, err := tx.ExecContext(ctx, queryAssignees, cardID, newListID); err != nil {
+		return nil, fmt.Errorf("cardRepository.MoveToBoard: assignees: %w", err)
 	}
 
-	queryShiftOld := `UPDATE cards SET "position" = "position" - 1 WHERE list_id = $1 AND "position" > $2`
-	if _, err := tx.ExecContext(ctx, queryShiftOld, oldListID, oldPosition); err != nil {
-		return fmt.Errorf("coluld net shift card in old list: %w", err)
+	assignees := []models.CardAssignee{}
+	queryRemaining := `SELECT ca.card_id, ca.user_id, u.name, ca.assigned_at FROM card_assignees ca
+					   JOIN users u ON u.id = ca.user_id
+					   WHERE ca.card_id=$1
+					   ORDER BY ca.assigned_at ASC, ca.user_id ASC`
+	if err := tx.SelectContext(ctx, &assignees, queryRemaining, cardID); err != nil {
+		return nil, fmt.Errorf("cardRepository.MoveToBoard: %w", err)
 	}
 
-	queryShiftNew := `UPDATE cards SET "position" = "position" + 1 WHERE list_id = $1 AND "position" >= $2`
-	if _, err := tx.ExecContext(ctx, queryShiftNew, newListID, newPosition); err != nil {
-		return fmt.Errorf("could not shift cards in new list: %w", err)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("cardRepository.MoveToBoard: commit: %w", err)
 	}
+	return assignees, nil
+}
 
-	queryMove := `UPDATE cards SET list_id = $1, "position" = $2, updated_at = NOW() WHERE id = $3`
-	if _, err := tx.ExecContext(ctx, queryMove, newListID, newPosition, cardID); err != nil {
-		return fmt.Errorf("could not move card: %w", err)
+// GetNeighbourPosition возвращает позицию ближайшей карточки списка перед (before=true) или после position.
+// Карточка excludeCardID не учитывается, чтобы перемещаемая карточка не считалась своим соседом.
+func (r *cardRepository) GetNeighbourPosition(ctx context.Context, listID int, position float64, before bool, excludeCardID int) (*float64, error) {
+	query := `SELECT MIN("position") FROM cards
+			  WHERE list_id=$1 AND "position" > $2 AND id <> $3 AND archived_at IS NULL`
+	if before {
+		query = `SELECT MAX("position") FROM cards
+				 WHERE list_id=$1 AND "position" < $2 AND id <> $3 AND archived_at IS NULL`
+	}
+	var neighbour sql.NullFloat64
+	if err := r.db.GetContext(ctx, &neighbour, query, listID, position, excludeCardID); err != nil {
+		return nil, fmt.Errorf("cardRepository.GetNeighbourPosition: %w", err)
+	}
+	if !neighbour.Valid {
+		return nil, nil
 	}
+	return &neighbour.Float64, nil
+}
 
-	return tx.Commit()
+// Rebalance заново раскладывает карточки списка с шагом step, сохраняя их порядок.
+func (r *cardRepository) Rebalance(ctx context.Context, listID int, step float64) error {
+	query := `UPDATE cards c SET "position" = ordered.rn * $2
+			  FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY "position", id) AS rn
+					FROM cards WHERE list_id = $1 AND archived_at IS NULL) AS ordered
+			  WHERE c.id = ordered.id`
+	if _, err := r.db.ExecContext(ctx, query, listID, step); err != nil {
+		return fmt.Errorf("cardRepository.Rebalance: %w", err)
+	}
+	return nil
+}
+
+// GetDenseListIDs возвращает списки, в которых соседние карточки стоят ближе minGap.
+func (r *cardRepository) GetDenseListIDs(ctx context.Context, minGap float64, limit int) ([]int, error) {
+	query := `SELECT DISTINCT list_id FROM (
+				SELECT list_id, "position" - LAG("position") OVER (PARTITION BY list_id ORDER BY "position") AS gap
+				FROM cards
+				WHERE archived_at IS NULL
+			  ) AS gaps
+			  WHERE gap < $1
+			  LIMIT $2`
+	var listIDs []int
+	if err := r.db.SelectContext(ctx, &listIDs, query, minGap, limit); err != nil {
+		return nil, fmt.Errorf("cardRepository.GetDenseListIDs: %w", err)
+	}
+	return listIDs, nil
 }
 
 func (r *cardRepository) GetMaxPositionForList(ctx context.Context, listID int) (float64, error) {
 	var maxPos float64
-	query := `SELECT COALESCE(MAX("position"), 0) FROM cards WHERE list_id=$1`
+	query := `SELECT COALESCE(MAX("position"), 0) FROM cards WHERE list_id=$1 AND archived_at IS NULL`
 	err := r.db.GetContext(ctx, &maxPos, query, listID)
 	return maxPos, err
 }
@@ -85,12 +178,14 @@ func (r *cardRepository) Create(ctx context.Context, card *models.Card) error {
 	return row.Scan(&card.ID, &card.CreatedAt, &card.UpdatedAt)
 }
 
-func (r *cardRepository) GetAllByListIDs(ctx context.Context, listIDs []int) (map[int][]models.Card, error) {
+func (r *cardRepository) GetAllByListIDs(ctx context.Context, listIDs []int, includeArchived bool) (map[int][]models.Card, error) {
 	if len(listIDs) == 0 {
 		return make(map[int][]models.Card), nil
 	}
 
-	query, args, err := sqlx.In(`SELECT * FROM cards WHERE list_id IN (?) ORDER BY "position" ASC`, listIDs)
+	query, args, err := sqlx.In(`SELECT `+cardColumns+` FROM cards c
+								 WHERE c.list_id IN (?) AND (? OR c.archived_at IS NULL)
+								 ORDER BY c."position" ASC, c.id ASC`, listIDs, includeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
@@ -108,3 +203,165 @@ func (r *cardRepository) GetAllByListIDs(ctx context.Context, listIDs []int) (ma
 
 	return cardsByListID, nil
 }
+
+func (r *cardRepository) Update(ctx context.Context, card *models.Card) error {
+	query := `UPDATE cards SET title=$1, description=$2, updated_at=NOW() WHERE id=$3
+			  RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, card.Title, card.Description, card.ID)
+	if err := row.Scan(&card.UpdatedAt); err != nil {
+		return fmt.Errorf("cardRepository.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *cardRepository) Archive(ctx context.Context, cardID int) error {
+	query := `UPDATE cards SET archived_at=NOW(), updated_at=NOW() WHERE id=$1 AND archived_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, cardID)
+	if err != nil {
+		return fmt.Errorf("cardRepository.Archive: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cardRepository.Archive: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("card with id %d not found or already archived: %w", cardID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Restore возвращает карточку из архива на позицию position.
+func (r *cardRepository) Restore(ctx context.Context, cardID int, position float64) error {
+	query := `UPDATE cards SET archived_at=NULL, "position"=$1, updated_at=NOW() WHERE id=$2 AND archived_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, position, cardID)
+	if err != nil {
+		return fmt.Errorf("cardRepository.Restore: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("cardRepository.Restore: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("card with id %d not found or not archived: %w", cardID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// PurgeArchived окончательно удаляет карточки, архивированные раньше before. Возвращает число удалённых
+// строк и ключи файлов их вложений, которые нужно удалить из хранилища.
+func (r *cardRepository) PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keys, err := deleteAttachmentsOf(ctx, tx, `SELECT id FROM cards WHERE archived_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cardRepository.PurgeArchived: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM cards WHERE archived_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cardRepository.PurgeArchived: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, fmt.Errorf("cardRepository.PurgeArchived: failed to get rows affected: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("cardRepository.PurgeArchived: commit: %w", err)
+	}
+	return purged, keys, nil
+}
+
+// UpdateDates сохраняет start_at и due_at. Отметки об уведомлениях сбрасываются,
+// чтобы для нового срока пороги сработали заново.
+func (r *cardRepository) UpdateDates(ctx context.Context, card *models.Card) error {
+	query := `UPDATE cards SET start_at=$1, due_at=$2,
+				due_soon_notified_at=NULL, overdue_notified_at=NULL, updated_at=NOW()
+			  WHERE id=$3
+			  RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, card.StartAt, card.DueAt, card.ID)
+	if err := row.Scan(&card.UpdatedAt); err != nil {
+		return fmt.Errorf("cardRepository.UpdateDates: %w", err)
+	}
+	return nil
+}
+
+func (r *cardRepository) SetCompleted(ctx context.Context, card *models.Card) error {
+	query := `UPDATE cards SET completed=$1, updated_at=NOW() WHERE id=$2 RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, card.Completed, card.ID)
+	if err := row.Scan(&card.UpdatedAt); err != nil {
+		return fmt.Errorf("cardRepository.SetCompleted: %w", err)
+	}
+	return nil
+}
+
+// GetDueForBoard возвращает незавершённые карточки доски со сроком не позже until,
+// включая уже просроченные. Архивные карточки и карточки архивных списков пропускаются.
+func (r *cardRepository) GetDueForBoard(ctx context.Context, boardID int, until time.Time) ([]models.Card, error) {
+	cards := []models.Card{}
+	query := `SELECT ` + cardColumns + ` FROM cards c
+			  JOIN lists l ON l.id = c.list_id
+			  WHERE l.board_id=$1 AND l.archived_at IS NULL AND c.archived_at IS NULL
+				AND NOT c.completed AND c.due_at IS NOT NULL AND c.due_at <= $2
+			  ORDER BY c.due_at ASC, c.id ASC`
+	if err := r.db.SelectContext(ctx, &cards, query, boardID, until); err != nil {
+		return nil, fmt.Errorf("cardRepository.GetDueForBoard: %w", err)
+	}
+	return cards, nil
+}
+
+// ClaimDueSoon помечает карточки, срок которых наступит в (now, until], как уведомлённые
+// и возвращает их по доскам. Отметка ставится в том же UPDATE, поэтому несколько
+// реплик не разошлют одно событие дважды.
+func (r *cardRepository) ClaimDueSoon(ctx context.Context, now, until time.Time, limit int) (map[int][]models.Card, error) {
+	query := `UPDATE cards c SET due_soon_notified_at=NOW()
+			  FROM lists l
+			  WHERE l.id = c.list_id AND c.id IN (
+				SELECT c2.id FROM cards c2
+				JOIN lists l2 ON l2.id = c2.list_id
+				JOIN boards b2 ON b2.id = l2.board_id
+				WHERE b2.archived_at IS NULL AND l2.archived_at IS NULL AND c2.archived_at IS NULL AND NOT c2.completed
+				  AND c2.due_at > $1 AND c2.due_at <= $2 AND c2.due_soon_notified_at IS NULL
+				ORDER BY c2.due_at
+				LIMIT $3
+				FOR UPDATE OF c2 SKIP LOCKED
+			  )
+			  RETURNING l.board_id, ` + cardColumns
+	return r.claimDue(ctx, "ClaimDueSoon", query, now, until, limit)
+}
+
+// ClaimOverdue работает как ClaimDueSoon, но для карточек, срок которых уже прошёл.
+func (r *cardRepository) ClaimOverdue(ctx context.Context, now time.Time, limit int) (map[int][]models.Card, error) {
+	query := `UPDATE cards c SET overdue_notified_at=NOW()
+			  FROM lists l
+			  WHERE l.id = c.list_id AND c.id IN (
+				SELECT c2.id FROM cards c2
+				JOIN lists l2 ON l2.id = c2.list_id
+				JOIN boards b2 ON b2.id = l2.board_id
+				WHERE b2.archived_at IS NULL AND l2.archived_at IS NULL AND c2.archived_at IS NULL AND NOT c2.completed
+				  AND c2.due_at <= $1 AND c2.overdue_notified_at IS NULL
+				ORDER BY c2.due_at
+				LIMIT $2
+				FOR UPDATE OF c2 SKIP LOCKED
+			  )
+			  RETURNING l.board_id, ` + cardColumns
+	return r.claimDue(ctx, "ClaimOverdue", query, now, limit)
+}
+
+func (r *cardRepository) claimDue(ctx context.Context, method, query string, args ...interface{}) (map[int][]models.Card, error) {
+	var rows []struct {
+		BoardID int `db:"board_id"`
+		models.Card
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("cardRepository.%s: %w", method, err)
+	}
+
+	cardsByBoardID := make(map[int][]models.Card)
+	for _, row := range rows {
+		cardsByBoardID[row.BoardID] = append(cardsByBoardID[row.BoardID], row.Card)
+	}
+	return cardsByBoardID, nil
+}
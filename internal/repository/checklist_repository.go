@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ChecklistRepository interface {
+	Create(ctx context.Context, checklist *models.Checklist) error
+	GetByID(ctx context.Context, checklistID int) (*models.Checklist, error)
+	GetAllByCardID(ctx context.Context, cardID int) ([]models.Checklist, error)
+	GetMaxPositionForCard(ctx context.Context, cardID int) (float64, error)
+	UpdateTitle(ctx context.Context, checklist *models.Checklist) error
+	Delete(ctx context.Context, checklistID int) error
+
+	CreateItem(ctx context.Context, item *models.ChecklistItem) error
+	GetItemByID(ctx context.Context, itemID int) (*models.ChecklistItem, error)
+	GetMaxItemPosition(ctx context.Context, checklistID int) (float64, error)
+	GetItemNeighbourPosition(ctx context.Context, checklistID int, position float64, before bool, excludeItemID int) (*float64, error)
+	RebalanceItems(ctx context.Context, checklistID int, step float64) error
+	UpdateItem(ctx context.Context, item *models.ChecklistItem) error
+	MoveItem(ctx context.Context, itemID int, position float64) error
+	DeleteItem(ctx context.Context, itemID int) error
+
+	GetProgressByCardIDs(ctx context.Context, cardIDs []int) (map[int]models.ChecklistProgress, error)
+}
+
+type checklistRepository struct {
+	db *sqlx.DB
+}
+
+func NewChecklistRepository(db *sqlx.DB) ChecklistRepository {
+	return &checklistRepository{db: db}
+}
+
+func (r *checklistRepository) Create(ctx context.Context, checklist *models.Checklist) error {
+	query := `INSERT INTO checklists (card_id, title, "position") VALUES ($1, $2, $3)
+			  RETURNING id, created_at, updated_at`
+	row := r.db.QueryRowxContext(ctx, query, checklist.CardID, checklist.Title, checklist.Position)
+	if err := row.Scan(&checklist.ID, &checklist.CreatedAt, &checklist.UpdatedAt); err != nil {
+		return fmt.Errorf("checklistRepository.Create: %w", err)
+	}
+	checklist.Items = []models.ChecklistItem{}
+	return nil
+}
+
+func (r *checklistRepository) GetByID(ctx context.Context, checklistID int) (*models.Checklist, error) {
+	var checklist models.Checklist
+	if err := r.db.GetContext(ctx, &checklist, `SELECT * FROM checklists WHERE id=$1`, checklistID); err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetByID: %w", err)
+	}
+	return &checklist, nil
+}
+
+// GetAllByCardID возвращает чек-листы карточки вместе с пунктами, оба уровня упорядочены по позиции.
+func (r *checklistRepository) GetAllByCardID(ctx context.Context, cardID int) ([]models.Checklist, error) {
+	checklists := []models.Checklist{}
+	query := `SELECT * FROM checklists WHERE card_id=$1 ORDER BY "position" ASC, id ASC`
+	if err := r.db.SelectContext(ctx, &checklists, query, cardID); err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetAllByCardID: %w", err)
+	}
+
+	var items []models.ChecklistItem
+	query = `SELECT i.* FROM checklist_items i
+			 JOIN checklists cl ON cl.id = i.checklist_id
+			 WHERE cl.card_id=$1
+			 ORDER BY i."position" ASC, i.id ASC`
+	if err := r.db.SelectContext(ctx, &items, query, cardID); err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetAllByCardID: failed to select items: %w", err)
+	}
+
+	itemsByChecklistID := make(map[int][]models.ChecklistItem)
+	for _, item := range items {
+		itemsByChecklistID[item.ChecklistID] = append(itemsByChecklistID[item.ChecklistID], item)
+	}
+	for i := range checklists {
+		if items, ok := itemsByChecklistID[checklists[i].ID]; ok {
+			checklists[i].Items = items
+		} else {
+			checklists[i].Items = []models.ChecklistItem{}
+		}
+	}
+	return checklists, nil
+}
+
+func (r *checklistRepository) GetMaxPositionForCard(ctx context.Context, cardID int) (float64, error) {
+	var maxPosition sql.NullFloat64
+	query := `SELECT MAX("position") FROM checklists WHERE card_id=$1`
+	if err := r.db.GetContext(ctx, &maxPosition, query, cardID); err != nil {
+		return 0, fmt.Errorf("checklistRepository.GetMaxPositionForCard: %w", err)
+	}
+	return maxPosition.Float64, nil
+}
+
+func (r *checklistRepository) UpdateTitle(ctx context.Context, checklist *models.Checklist) error {
+	query := `UPDATE checklists SET title=$1, updated_at=NOW() WHERE id=$2 RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, checklist.Title, checklist.ID)
+	if err := row.Scan(&checklist.UpdatedAt); err != nil {
+		return fmt.Errorf("checklistRepository.UpdateTitle: %w", err)
+	}
+	return nil
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, checklistID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM checklists WHERE id=$1`, checklistID); err != nil {
+		return fmt.Errorf("checklistRepository.Delete: %w", err)
+	}
+	return nil
+}
+
+func (r *checklistRepository) CreateItem(ctx context.Context, item *models.ChecklistItem) error {
+	query := `INSERT INTO checklist_items (checklist_id, title, "position") VALUES ($1, $2, $3)
+			  RETURNING id, checked, created_at, updated_at`
+	row := r.db.QueryRowxContext(ctx, query, item.ChecklistID, item.Title, item.Position)
+	if err := row.Scan(&item.ID, &item.Checked, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return fmt.Errorf("checklistRepository.CreateItem: %w", err)
+	}
+	return nil
+}
+
+func (r *checklistRepository) GetItemByID(ctx context.Context, itemID int) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	if err := r.db.GetContext(ctx, &item, `SELECT * FROM checklist_items WHERE id=$1`, itemID); err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetItemByID: %w", err)
+	}
+	return &item, nil
+}
+
+func (r *checklistRepository) GetMaxItemPosition(ctx context.Context, checklistID int) (float64, error) {
+	var maxPosition sql.NullFloat64
+	query := `SELECT MAX("position") FROM checklist_items WHERE checklist_id=$1`
+	if err := r.db.GetContext(ctx, &maxPosition, query, checklistID); err != nil {
+		return 0, fmt.Errorf("checklistRepository.GetMaxItemPosition: %w", err)
+	}
+	return maxPosition.Float64, nil
+}
+
+// GetItemNeighbourPosition работает как cardRepository.GetNeighbourPosition, но для пунктов чек-листа.
+func (r *checklistRepository) GetItemNeighbourPosition(ctx context.Context, checklistID int, position float64, before bool, excludeItemID int) (*float64, error) {
+	query := `SELECT MIN("position") FROM checklist_items WHERE checklist_id=$1 AND "position" > $2 AND id <> $3`
+	if before {
+		query = `SELECT MAX("position") FROM checklist_items WHERE checklist_id=$1 AND "position" < $2 AND id <> $3`
+	}
+	var neighbour sql.NullFloat64
+	if err := r.db.GetContext(ctx, &neighbour, query, checklistID, position, excludeItemID); err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetItemNeighbourPosition: %w", err)
+	}
+	if !neighbour.Valid {
+		return nil, nil
+	}
+	return &neighbour.Float64, nil
+}
+
+func (r *checklistRepository) RebalanceItems(ctx context.Context, checklistID int, step float64) error {
+	query := `UPDATE checklist_items i SET "position" = ordered.rn * $2
+			  FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY "position", id) AS rn
+					FROM checklist_items WHERE checklist_id = $1) AS ordered
+			  WHERE i.id = ordered.id`
+	if _, err := r.db.ExecContext(ctx, query, checklistID, step); err != nil {
+		return fmt.Errorf("checklistRepository.RebalanceItems: %w", err)
+	}
+	return nil
+}
+
+func (r *checklistRepository) UpdateItem(ctx context.Context, item *models.ChecklistItem) error {
+	query := `UPDATE checklist_items SET title=$1, checked=$2, updated_at=NOW() WHERE id=$3
+			  RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, item.Title, item.Checked, item.ID)
+	if err := row.Scan(&item.UpdatedAt); err != nil {
+		return fmt.Errorf("checklistRepository.UpdateItem: %w", err)
+	}
+	return nil
+}
+
+func (r *checklistRepository) MoveItem(ctx context.Context, itemID int, position float64) error {
+	query := `UPDATE checklist_items SET "position"=$1, updated_at=NOW() WHERE id=$2`
+	if _, err := r.db.ExecContext(ctx, query, position, itemID); err != nil {
+		return fmt.Errorf("checklistRepository.MoveItem: %w", err)
+	}
+	return nil
+}
+
+func (r *checklistRepository) DeleteItem(ctx context.Context, itemID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM checklist_items WHERE id=$1`, itemID); err != nil {
+		return fmt.Errorf("checklistRepository.DeleteItem: %w", err)
+	}
+	return nil
+}
+
+// GetProgressByCardIDs считает отмеченные и все пункты по карточкам. Карточки без пунктов в ответ не попадают.
+func (r *checklistRepository) GetProgressByCardIDs(ctx context.Context, cardIDs []int) (map[int]models.ChecklistProgress, error) {
+	if len(cardIDs) == 0 {
+		return make(map[int]models.ChecklistProgress), nil
+	}
+
+	query, args, err := sqlx.In(`SELECT cl.card_id,
+				COUNT(*) FILTER (WHERE i.checked) AS checked,
+				COUNT(*) AS total
+			  FROM checklist_items i
+			  JOIN checklists cl ON cl.id = i.checklist_id
+			  WHERE cl.card_id IN (?)
+			  GROUP BY cl.card_id`, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetProgressByCardIDs: failed to build query: %w", err)
+	}
+
+	var rows []struct {
+		CardID int `db:"card_id"`
+		models.ChecklistProgress
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("checklistRepository.GetProgressByCardIDs: %w", err)
+	}
+
+	progressByCardID := make(map[int]models.ChecklistProgress, len(rows))
+	for _, row := range rows {
+		progressByCardID[row.CardID] = row.ChecklistProgress
+	}
+	return progressByCardID, nil
+}
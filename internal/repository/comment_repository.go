@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	GetByID(ctx context.Context, commentID int) (*models.Comment, error)
+	GetPageByCardID(ctx context.Context, cardID, limit, offset int) ([]models.Comment, int, error)
+	Update(ctx context.Context, comment *models.Comment) error
+	Delete(ctx context.Context, commentID int) error
+}
+
+const commentColumns = `c.id, c.card_id, c.author_id, c.body, c.created_at, c.updated_at`
+
+type commentRepository struct {
+	db *sqlx.DB
+}
+
+func NewCommentRepository(db *sqlx.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	query := `WITH inserted AS (
+				INSERT INTO comments (card_id, author_id, body) VALUES ($1, $2, $3)
+				RETURNING id, created_at, updated_at, author_id
+			  )
+			  SELECT inserted.id, inserted.created_at, inserted.updated_at, u.name
+			  FROM inserted JOIN users u ON u.id = inserted.author_id`
+	row := r.db.QueryRowxContext(ctx, query, comment.CardID, comment.AuthorID, comment.Body)
+	if err := row.Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt, &comment.AuthorName); err != nil {
+		return fmt.Errorf("commentRepository.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, commentID int) (*models.Comment, error) {
+	var comment models.Comment
+	query := `SELECT ` + commentColumns + `, u.name AS author_name FROM comments c
+			  JOIN users u ON u.id = c.author_id
+			  WHERE c.id=$1`
+	if err := r.db.GetContext(ctx, &comment, query, commentID); err != nil {
+		return nil, fmt.Errorf("commentRepository.GetByID: %w", err)
+	}
+	return &comment, nil
+}
+
+// GetPageByCardID возвращает страницу комментариев карточки в хронологическом порядке и их общее число.
+func (r *commentRepository) GetPageByCardID(ctx context.Context, cardID, limit, offset int) ([]models.Comment, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM comments WHERE card_id=$1`, cardID); err != nil {
+		return nil, 0, fmt.Errorf("commentRepository.GetPageByCardID: %w", err)
+	}
+
+	comments := []models.Comment{}
+	query := `SELECT ` + commentColumns + `, u.name AS author_name FROM comments c
+			  JOIN users u ON u.id = c.author_id
+			  WHERE c.card_id=$1
+			  ORDER BY c.created_at ASC, c.id ASC
+			  LIMIT $2 OFFSET $3`
+	if err := r.db.SelectContext(ctx, &comments, query, cardID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("commentRepository.GetPageByCardID: %w", err)
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *models.Comment) error {
+	query := `UPDATE comments SET body=$1, updated_at=NOW() WHERE id=$2 RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, comment.Body, comment.ID)
+	if err := row.Scan(&comment.UpdatedAt); err != nil {
+		return fmt.Errorf("commentRepository.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, commentID int) error {
+	query := `DELETE FROM comments WHERE id=$1`
+	if _, err := r.db.ExecContext(ctx, query, commentID); err != nil {
+		return fmt.Errorf("commentRepository.Delete: %w", err)
+	}
+	return nil
+}
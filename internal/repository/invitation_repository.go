@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.Invitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	GetPendingForBoard(ctx context.Context, boardID int) ([]models.Invitation, error)
+	GetPendingForEmail(ctx context.Context, email string) ([]models.Invitation, error)
+	Revoke(ctx context.Context, boardID, invitationID int) error
+	Accept(ctx context.Context, invitationID, userID int) (*models.Invitation, error)
+}
+
+type invitationRepository struct {
+	db *sqlx.DB
+}
+
+func NewInvitationRepository(db *sqlx.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *models.Invitation) error {
+	query := `INSERT INTO board_invitations (board_id, email, role, token_hash, invited_by, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at`
+	row := r.db.QueryRowxContext(ctx, query, invitation.BoardID, invitation.Email, invitation.Role,
+		invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt)
+	if err := row.Scan(&invitation.ID, &invitation.CreatedAt); err != nil {
+		return fmt.Errorf("invitationRepository.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	query := `SELECT * FROM board_invitations WHERE token_hash=$1`
+	if err := r.db.GetContext(ctx, &invitation, query, tokenHash); err != nil {
+		return nil, fmt.Errorf("invitationRepository.GetByTokenHash: %w", err)
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) GetPendingForBoard(ctx context.Context, boardID int) ([]models.Invitation, error) {
+	invitations := []models.Invitation{}
+	query := `SELECT * FROM board_invitations
+			  WHERE board_id=$1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+			  ORDER BY created_at DESC`
+	if err := r.db.SelectContext(ctx, &invitations, query, boardID); err != nil {
+		return nil, fmt.Errorf("invitationRepository.GetPendingForBoard: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) GetPendingForEmail(ctx context.Context, email string) ([]models.Invitation, error) {
+	var invitations []models.Invitation
+	query := `SELECT * FROM board_invitations
+			  WHERE LOWER(email)=LOWER($1) AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+			  ORDER BY created_at`
+	if err := r.db.SelectContext(ctx, &invitations, query, email); err != nil {
+		return nil, fmt.Errorf("invitationRepository.GetPendingForEmail: %w", err)
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, boardID, invitationID int) error {
+	query := `UPDATE board_invitations SET revoked_at=NOW()
+			  WHERE id=$1 AND board_id=$2 AND accepted_at IS NULL AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, invitationID, boardID)
+	if err != nil {
+		return fmt.Errorf("invitationRepository.Revoke: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("invitationRepository.Revoke: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending invitation with id %d not found: %w", invitationID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Accept в одной транзакции помечает приглашение принятым и добавляет пользователя в доску.
+// Приглашение должно быть ещё действующим: не принятым, не отозванным и не просроченным.
+func (r *invitationRepository) Accept(ctx context.Context, invitationID, userID int) (*models.Invitation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var invitation models.Invitation
+	query := `UPDATE board_invitations SET accepted_at=NOW()
+			  WHERE id=$1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+			  RETURNING *`
+	if err := tx.GetContext(ctx, &invitation, query, invitationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invitation is no longer valid: %w", sql.ErrNoRows)
+		}
+		return nil, fmt.Errorf("invitationRepository.Accept: %w", err)
+	}
+
+	queryMember := `INSERT INTO board_members (board_id, user_id, role) VALUES ($1, $2, $3)
+					ON CONFLICT (user_id, board_id) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, queryMember, invitation.BoardID, userID, invitation.Role); err != nil {
+		return nil, fmt.Errorf("invitationRepository.Accept: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("invitationRepository.Accept: %w", err)
+	}
+	return &invitation, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrDuplicateLabel - на доске уже есть метка с таким именем (нарушение уникальности (board_id, name)).
+var ErrDuplicateLabel = errors.New("label with this name already exists on the board")
+
+type LabelRepository interface {
+	Create(ctx context.Context, label *models.Label) error
+	GetByID(ctx context.Context, labelID int) (*models.Label, error)
+	GetAllByBoardID(ctx context.Context, boardID int) ([]models.Label, error)
+	GetByCardID(ctx context.Context, cardID int) ([]models.Label, error)
+	GetByCardIDs(ctx context.Context, cardIDs []int) (map[int][]models.Label, error)
+	Update(ctx context.Context, label *models.Label) error
+	Delete(ctx context.Context, labelID int) error
+	Attach(ctx context.Context, cardID, labelID int) error
+	Detach(ctx context.Context, cardID, labelID int) error
+}
+
+type labelRepository struct {
+	db *sqlx.DB
+}
+
+func NewLabelRepository(db *sqlx.DB) LabelRepository {
+	return &labelRepository{db: db}
+}
+
+func (r *labelRepository) Create(ctx context.Context, label *models.Label) error {
+	query := `INSERT INTO labels (board_id, name, color) VALUES ($1, $2, $3)
+			  RETURNING id, created_at, updated_at`
+	row := r.db.QueryRowxContext(ctx, query, label.BoardID, label.Name, label.Color)
+	if err := row.Scan(&label.ID, &label.CreatedAt, &label.UpdatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return ErrDuplicateLabel
+		}
+		return fmt.Errorf("labelRepository.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *labelRepository) GetByID(ctx context.Context, labelID int) (*models.Label, error) {
+	var label models.Label
+	if err := r.db.GetContext(ctx, &label, `SELECT * FROM labels WHERE id=$1`, labelID); err != nil {
+		return nil, fmt.Errorf("labelRepository.GetByID: %w", err)
+	}
+	return &label, nil
+}
+
+func (r *labelRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]models.Label, error) {
+	labels := []models.Label{}
+	query := `SELECT * FROM labels WHERE board_id=$1 ORDER BY name ASC, id ASC`
+	if err := r.db.SelectContext(ctx, &labels, query, boardID); err != nil {
+		return nil, fmt.Errorf("labelRepository.GetAllByBoardID: %w", err)
+	}
+	return labels, nil
+}
+
+func (r *labelRepository) GetByCardID(ctx context.Context, cardID int) ([]models.Label, error) {
+	labels := []models.Label{}
+	query := `SELECT l.* FROM labels l
+			  JOIN card_labels cl ON cl.label_id = l.id
+			  WHERE cl.card_id=$1
+			  ORDER BY l.name ASC, l.id ASC`
+	if err := r.db.SelectContext(ctx, &labels, query, cardID); err != nil {
+		return nil, fmt.Errorf("labelRepository.GetByCardID: %w", err)
+	}
+	return labels, nil
+}
+
+// GetByCardIDs возвращает метки сразу для набора карточек, сгруппированные по id карточки.
+func (r *labelRepository) GetByCardIDs(ctx context.Context, cardIDs []int) (map[int][]models.Label, error) {
+	if len(cardIDs) == 0 {
+		return make(map[int][]models.Label), nil
+	}
+
+	query, args, err := sqlx.In(`SELECT cl.card_id, l.* FROM labels l
+			  JOIN card_labels cl ON cl.label_id = l.id
+			  WHERE cl.card_id IN (?)
+			  ORDER BY l.name ASC, l.id ASC`, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("labelRepository.GetByCardIDs: failed to build query: %w", err)
+	}
+
+	var rows []struct {
+		CardID int `db:"card_id"`
+		models.Label
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("labelRepository.GetByCardIDs: %w", err)
+	}
+
+	labelsByCardID := make(map[int][]models.Label)
+	for _, row := range rows {
+		labelsByCardID[row.CardID] = append(labelsByCardID[row.CardID], row.Label)
+	}
+	return labelsByCardID, nil
+}
+
+func (r *labelRepository) Update(ctx context.Context, label *models.Label) error {
+	query := `UPDATE labels SET name=$1, color=$2, updated_at=NOW() WHERE id=$3
+			  RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, label.Name, label.Color, label.ID)
+	if err := row.Scan(&label.UpdatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return ErrDuplicateLabel
+		}
+		return fmt.Errorf("labelRepository.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *labelRepository) Delete(ctx context.Context, labelID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM labels WHERE id=$1`, labelID); err != nil {
+		return fmt.Errorf("labelRepository.Delete: %w", err)
+	}
+	return nil
+}
+
+// Attach идемпотентен: повторное навешивание той же метки ничего не меняет.
+func (r *labelRepository) Attach(ctx context.Context, cardID, labelID int) error {
+	query := `INSERT INTO card_labels (card_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := r.db.ExecContext(ctx, query, cardID, labelID); err != nil {
+		return fmt.Errorf("labelRepository.Attach: %w", err)
+	}
+	return nil
+}
+
+func (r *labelRepository) Detach(ctx context.Context, cardID, labelID int) error {
+	query := `DELETE FROM card_labels WHERE card_id=$1 AND label_id=$2`
+	if _, err := r.db.ExecContext(ctx, query, cardID, labelID); err != nil {
+		return fmt.Errorf("labelRepository.Detach: %w", err)
+	}
+	return nil
+}
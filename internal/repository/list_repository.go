@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"notes-project/internal/models"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -11,12 +13,21 @@ import (
 type ListRepository interface {
 	Create(ctx context.Context, list *models.List) error
 	GetByID(ctx context.Context, listID int) (*models.List, error)
-	GetAllByBoardID(ctx context.Context, boardID int) ([]models.List, error)
+	GetAllByBoardID(ctx context.Context, boardID int, includeArchived bool) ([]models.List, error)
 	Update(ctx context.Context, list *models.List) error
 	Delete(ctx context.Context, listID int) error
+	Archive(ctx context.Context, listID int) error
+	Restore(ctx context.Context, listID int, position float64) error
+	PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error)
+	Move(ctx context.Context, listID int, newPosition float64) error
+	GetNeighbourPosition(ctx context.Context, boardID int, position float64, before bool, excludeListID int) (*float64, error)
+	Rebalance(ctx context.Context, boardID int, step float64) error
+	GetDenseBoardIDs(ctx context.Context, minGap float64, limit int) ([]int, error)
 	GetMaxPositionForBoard(ctx context.Context, boardID int) (float64, error)
 }
 
+const listColumns = `l.id, l.title, l."position", l.board_id, l.created_at, l.updated_at, l.archived_at`
+
 type listRepository struct {
 	db *sqlx.DB
 }
@@ -27,7 +38,7 @@ func NewListRepository(db *sqlx.DB) ListRepository {
 
 func (r *listRepository) GetMaxPositionForBoard(ctx context.Context, boardID int) (float64, error) {
 	var maxPos float64
-	query := `SELECT COALESCE(MAX("position"), 0) FROM lists WHERE board_id=$1`
+	query := `SELECT COALESCE(MAX("position"), 0) FROM lists WHERE board_id=$1 AND archived_at IS NULL`
 	err := r.db.GetContext(ctx, &maxPos, query, boardID)
 	return maxPos, err
 }
@@ -41,17 +52,20 @@ func (r *listRepository) Create(ctx context.Context, list *models.List) error {
 
 func (r *listRepository) GetByID(ctx context.Context, listID int) (*models.List, error) {
 	var list models.List
-	query := `SELECT * FROM lists WHERE id = $1`
+	query := `SELECT ` + listColumns + `, b.archived_at AS board_archived_at
+			  FROM lists l JOIN boards b ON b.id = l.board_id WHERE l.id = $1`
 	if err := r.db.GetContext(ctx, &list, query, listID); err != nil {
 		return nil, fmt.Errorf("listRepository.GetByID: %w", err)
 	}
 	return &list, nil
 }
 
-func (r *listRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]models.List, error) {
+func (r *listRepository) GetAllByBoardID(ctx context.Context, boardID int, includeArchived bool) ([]models.List, error) {
 	var lists []models.List
-	query := `SELECT * FROM lists WHERE board_id=$1 ORDER BY "position" ASC`
-	if err := r.db.SelectContext(ctx, &lists, query, boardID); err != nil {
+	query := `SELECT ` + listColumns + ` FROM lists l
+			  WHERE l.board_id=$1 AND ($2 OR l.archived_at IS NULL)
+			  ORDER BY l."position" ASC, l.id ASC`
+	if err := r.db.SelectContext(ctx, &lists, query, boardID, includeArchived); err != nil {
 		return nil, fmt.Errorf("listRepository.GetAllByBoardID: %w", err)
 	}
 	return lists, nil
@@ -59,8 +73,18 @@ func (r *listRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]mo
 
 func (r *listRepository) Update(ctx context.Context, list *models.List) error {
 	query := `UPDATE lists SET title=$1, "position"=$2, updated_at=NOW() WHERE id=$3`
-	_, err := r.db.ExecContext(ctx, query, list.Title, list.Position, list.ID)
-	return err
+	result, err := r.db.ExecContext(ctx, query, list.Title, list.Position, list.ID)
+	if err != nil {
+		return fmt.Errorf("listRepository.Update: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("listRepository.Update: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("list with id %d not found: %w", list.ID, sql.ErrNoRows)
+	}
+	return nil
 }
 
 func (r *listRepository) Delete(ctx context.Context, listID int) error {
@@ -68,3 +92,126 @@ func (r *listRepository) Delete(ctx context.Context, listID int) error {
 	_, err := r.db.ExecContext(ctx, query, listID)
 	return err
 }
+
+func (r *listRepository) Archive(ctx context.Context, listID int) error {
+	query := `UPDATE lists SET archived_at=NOW(), updated_at=NOW() WHERE id=$1 AND archived_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, listID)
+	if err != nil {
+		return fmt.Errorf("listRepository.Archive: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("listRepository.Archive: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("list with id %d not found or already archived: %w", listID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Restore возвращает список из архива на позицию position.
+func (r *listRepository) Restore(ctx context.Context, listID int, position float64) error {
+	query := `UPDATE lists SET archived_at=NULL, "position"=$1, updated_at=NOW() WHERE id=$2 AND archived_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, query, position, listID)
+	if err != nil {
+		return fmt.Errorf("listRepository.Restore: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("listRepository.Restore: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("list with id %d not found or not archived: %w", listID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// PurgeArchived окончательно удаляет списки, архивированные раньше before, вместе с их карточками. Возвращает число удалённых
+// строк и ключи файлов их вложений, которые нужно удалить из хранилища.
+func (r *listRepository) PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	keys, err := deleteAttachmentsOf(ctx, tx, `SELECT c.id FROM cards c JOIN lists l ON l.id = c.list_id WHERE l.archived_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("listRepository.PurgeArchived: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM lists WHERE archived_at < $1`, before)
+	if err != nil {
+		return 0, nil, fmt.Errorf("listRepository.PurgeArchived: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, fmt.Errorf("listRepository.PurgeArchived: failed to get rows affected: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("listRepository.PurgeArchived: commit: %w", err)
+	}
+	return purged, keys, nil
+}
+
+func (r *listRepository) Move(ctx context.Context, listID int, newPosition float64) error {
+	query := `UPDATE lists SET "position" = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, newPosition, listID)
+	if err != nil {
+		return fmt.Errorf("could not move list: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("listRepository.Move: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("list with id %d not found: %w", listID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetNeighbourPosition возвращает позицию ближайшего списка доски перед (before=true) или после position.
+func (r *listRepository) GetNeighbourPosition(ctx context.Context, boardID int, position float64, before bool, excludeListID int) (*float64, error) {
+	query := `SELECT MIN("position") FROM lists
+			  WHERE board_id=$1 AND "position" > $2 AND id <> $3 AND archived_at IS NULL`
+	if before {
+		query = `SELECT MAX("position") FROM lists
+				 WHERE board_id=$1 AND "position" < $2 AND id <> $3 AND archived_at IS NULL`
+	}
+	var neighbour sql.NullFloat64
+	if err := r.db.GetContext(ctx, &neighbour, query, boardID, position, excludeListID); err != nil {
+		return nil, fmt.Errorf("listRepository.GetNeighbourPosition: %w", err)
+	}
+	if !neighbour.Valid {
+		return nil, nil
+	}
+	return &neighbour.Float64, nil
+}
+
+// Rebalance заново раскладывает активные списки доски с шагом step, сохраняя их порядок.
+// Архивные списки не трогаются: при восстановлении они всё равно встают в конец.
+func (r *listRepository) Rebalance(ctx context.Context, boardID int, step float64) error {
+	query := `UPDATE lists l SET "position" = ordered.rn * $2
+			  FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY "position", id) AS rn
+					FROM lists WHERE board_id = $1 AND archived_at IS NULL) AS ordered
+			  WHERE l.id = ordered.id`
+	if _, err := r.db.ExecContext(ctx, query, boardID, step); err != nil {
+		return fmt.Errorf("listRepository.Rebalance: %w", err)
+	}
+	return nil
+}
+
+// GetDenseBoardIDs возвращает доски, в которых соседние списки стоят ближе minGap.
+func (r *listRepository) GetDenseBoardIDs(ctx context.Context, minGap float64, limit int) ([]int, error) {
+	query := `SELECT DISTINCT board_id FROM (
+				SELECT board_id, "position" - LAG("position") OVER (PARTITION BY board_id ORDER BY "position") AS gap
+				FROM lists
+				WHERE archived_at IS NULL
+			  ) AS gaps
+			  WHERE gap < $1
+			  LIMIT $2`
+	var boardIDs []int
+	if err := r.db.SelectContext(ctx, &boardIDs, query, minGap, limit); err != nil {
+		return nil, fmt.Errorf("listRepository.GetDenseBoardIDs: %w", err)
+	}
+	return boardIDs, nil
+}
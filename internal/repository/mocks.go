@@ -4,6 +4,7 @@ package repository
 import (
 	"context"
 	"notes-project/internal/models"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -23,8 +24,8 @@ func (m *MockCardRepository) GetMaxPositionForList(ctx context.Context, listID i
 	return args.Get(0).(float64), args.Error(1)
 }
 
-func (m *MockCardRepository) GetAllByListIDs(ctx context.Context, listIDs []int) (map[int][]models.Card, error) {
-	args := m.Called(ctx, listIDs)
+func (m *MockCardRepository) GetAllByListIDs(ctx context.Context, listIDs []int, includeArchived bool) (map[int][]models.Card, error) {
+	args := m.Called(ctx, listIDs, includeArchived)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -44,6 +45,55 @@ func (m *MockCardRepository) Move(ctx context.Context, cardID, newListID int, ne
 	return args.Error(0)
 }
 
+func (m *MockCardRepository) MoveToBoard(ctx context.Context, cardID, newListID int, newPosition float64) ([]models.CardAssignee, error) {
+	args := m.Called(ctx, cardID, newListID, newPosition)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CardAssignee), args.Error(1)
+}
+
+func (m *MockCardRepository) GetNeighbourPosition(ctx context.Context, listID int, position float64, before bool, excludeCardID int) (*float64, error) {
+	args := m.Called(ctx, listID, position, before, excludeCardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+func (m *MockCardRepository) Rebalance(ctx context.Context, listID int, step float64) error {
+	args := m.Called(ctx, listID, step)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) GetDenseListIDs(ctx context.Context, minGap float64, limit int) ([]int, error) {
+	args := m.Called(ctx, minGap, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockCardRepository) Update(ctx context.Context, card *models.Card) error {
+	args := m.Called(ctx, card)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) Archive(ctx context.Context, cardID int) error {
+	args := m.Called(ctx, cardID)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) Restore(ctx context.Context, cardID int, position float64) error {
+	args := m.Called(ctx, cardID, position)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Get(1).([]string), args.Error(2)
+}
+
 // --- MockListRepository ---
 type MockListRepository struct {
 	mock.Mock
@@ -62,8 +112,8 @@ func (m *MockListRepository) GetByID(ctx context.Context, listID int) (*models.L
 	return args.Get(0).(*models.List), args.Error(1)
 }
 
-func (m *MockListRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]models.List, error) {
-	args := m.Called(ctx, boardID)
+func (m *MockListRepository) GetAllByBoardID(ctx context.Context, boardID int, includeArchived bool) ([]models.List, error) {
+	args := m.Called(ctx, boardID, includeArchived)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -80,6 +130,47 @@ func (m *MockListRepository) Delete(ctx context.Context, listID int) error {
 	return args.Error(0)
 }
 
+func (m *MockListRepository) Archive(ctx context.Context, listID int) error {
+	args := m.Called(ctx, listID)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) Restore(ctx context.Context, listID int, position float64) error {
+	args := m.Called(ctx, listID, position)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockListRepository) Move(ctx context.Context, listID int, newPosition float64) error {
+	args := m.Called(ctx, listID, newPosition)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) GetNeighbourPosition(ctx context.Context, boardID int, position float64, before bool, excludeListID int) (*float64, error) {
+	args := m.Called(ctx, boardID, position, before, excludeListID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+func (m *MockListRepository) Rebalance(ctx context.Context, boardID int, step float64) error {
+	args := m.Called(ctx, boardID, step)
+	return args.Error(0)
+}
+
+func (m *MockListRepository) GetDenseBoardIDs(ctx context.Context, minGap float64, limit int) ([]int, error) {
+	args := m.Called(ctx, minGap, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
 func (m *MockListRepository) GetMaxPositionForBoard(ctx context.Context, boardID int) (float64, error) {
 	args := m.Called(ctx, boardID)
 	return args.Get(0).(float64), args.Error(1)
@@ -103,35 +194,660 @@ func (m *MockBoardRepository) GetByID(ctx context.Context, boardID int) (*models
 	return args.Get(0).(*models.Board), args.Error(1)
 }
 
-func (m *MockBoardRepository) GetAllForUser(ctx context.Context, userID int) ([]models.Board, error) {
-	args := m.Called(ctx, userID)
+func (m *MockBoardRepository) GetAllForUser(ctx context.Context, userID int, includeArchived bool) ([]models.Board, error) {
+	args := m.Called(ctx, userID, includeArchived)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.Board), args.Error(1)
 }
 
-func (m *MockBoardRepository) Update(ctx context.Context, boardID, ownerID int, name string) error {
-	args := m.Called(ctx, boardID, ownerID, name)
+func (m *MockBoardRepository) Update(ctx context.Context, boardID int, name string) error {
+	args := m.Called(ctx, boardID, name)
 	return args.Error(0)
 }
 
-func (m *MockBoardRepository) Delete(ctx context.Context, boardID, ownerID int) error {
-	args := m.Called(ctx, boardID, ownerID)
+func (m *MockBoardRepository) Archive(ctx context.Context, boardID int) error {
+	args := m.Called(ctx, boardID)
 	return args.Error(0)
 }
 
-func (m *MockBoardRepository) AddMember(ctx context.Context, boardID, userID int) error {
-	args := m.Called(ctx, boardID, userID)
+func (m *MockBoardRepository) Restore(ctx context.Context, boardID int) error {
+	args := m.Called(ctx, boardID)
 	return args.Error(0)
 }
 
+func (m *MockBoardRepository) PurgeArchived(ctx context.Context, before time.Time) (int64, []string, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockBoardRepository) AddMember(ctx context.Context, boardID, userID int, role models.BoardRole) (bool, error) {
+	args := m.Called(ctx, boardID, userID, role)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockBoardRepository) RemoveMember(ctx context.Context, boardID, userID int) error {
 	args := m.Called(ctx, boardID, userID)
 	return args.Error(0)
 }
 
-func (m *MockBoardRepository) IsMemberOrOwner(ctx context.Context, boardID, userID int) (bool, error) {
+func (m *MockBoardRepository) GetMemberRole(ctx context.Context, boardID, userID int) (models.BoardRole, error) {
 	args := m.Called(ctx, boardID, userID)
+	return args.Get(0).(models.BoardRole), args.Error(1)
+}
+
+func (m *MockBoardRepository) UpdateMemberRole(ctx context.Context, boardID, userID int, role models.BoardRole) error {
+	args := m.Called(ctx, boardID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockBoardRepository) GetMembers(ctx context.Context, boardID int) ([]models.BoardMember, error) {
+	args := m.Called(ctx, boardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BoardMember), args.Error(1)
+}
+
+func (m *MockBoardRepository) TransferOwnership(ctx context.Context, boardID, oldOwnerID, newOwnerID int) error {
+	args := m.Called(ctx, boardID, oldOwnerID, newOwnerID)
+	return args.Error(0)
+}
+
+// --- MockUserRepository ---
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetAdmin(ctx context.Context, id int, isAdmin bool) error {
+	args := m.Called(ctx, id, isAdmin)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetPassword(ctx context.Context, id int, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id int) (*models.UserDeletion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserDeletion), args.Error(1)
+}
+
+// --- MockInvitationRepository ---
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(ctx context.Context, invitation *models.Invitation) error {
+	args := m.Called(ctx, invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetPendingForBoard(ctx context.Context, boardID int) ([]models.Invitation, error) {
+	args := m.Called(ctx, boardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) GetPendingForEmail(ctx context.Context, email string) ([]models.Invitation, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Revoke(ctx context.Context, boardID, invitationID int) error {
+	args := m.Called(ctx, boardID, invitationID)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) Accept(ctx context.Context, invitationID, userID int) (*models.Invitation, error) {
+	args := m.Called(ctx, invitationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+// --- MockCommentRepository ---
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) GetByID(ctx context.Context, commentID int) (*models.Comment, error) {
+	args := m.Called(ctx, commentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetPageByCardID(ctx context.Context, cardID, limit, offset int) ([]models.Comment, int, error) {
+	args := m.Called(ctx, cardID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Comment), args.Int(1), args.Error(2)
+}
+
+func (m *MockCommentRepository) Update(ctx context.Context, comment *models.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) Delete(ctx context.Context, commentID int) error {
+	args := m.Called(ctx, commentID)
+	return args.Error(0)
+}
+
+// --- MockLabelRepository ---
+type MockLabelRepository struct {
+	mock.Mock
+}
+
+func (m *MockLabelRepository) Create(ctx context.Context, label *models.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
+
+func (m *MockLabelRepository) GetByID(ctx context.Context, labelID int) (*models.Label, error) {
+	args := m.Called(ctx, labelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]models.Label, error) {
+	args := m.Called(ctx, boardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) GetByCardID(ctx context.Context, cardID int) ([]models.Label, error) {
+	args := m.Called(ctx, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) GetByCardIDs(ctx context.Context, cardIDs []int) (map[int][]models.Label, error) {
+	args := m.Called(ctx, cardIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]models.Label), args.Error(1)
+}
+
+func (m *MockLabelRepository) Update(ctx context.Context, label *models.Label) error {
+	args := m.Called(ctx, label)
+	return args.Error(0)
+}
+
+func (m *MockLabelRepository) Delete(ctx context.Context, labelID int) error {
+	args := m.Called(ctx, labelID)
+	return args.Error(0)
+}
+
+func (m *MockLabelRepository) Attach(ctx context.Context, cardID, labelID int) error {
+	args := m.Called(ctx, cardID, labelID)
+	return args.Error(0)
+}
+
+func (m *MockLabelRepository) Detach(ctx context.Context, cardID, labelID int) error {
+	args := m.Called(ctx, cardID, labelID)
+	return args.Error(0)
+}
+
+// --- MockAssigneeRepository ---
+type MockAssigneeRepository struct {
+	mock.Mock
+}
+
+func (m *MockAssigneeRepository) Assign(ctx context.Context, cardID, userID int) error {
+	args := m.Called(ctx, cardID, userID)
+	return args.Error(0)
+}
+
+func (m *MockAssigneeRepository) Unassign(ctx context.Context, cardID, userID int) error {
+	args := m.Called(ctx, cardID, userID)
+	return args.Error(0)
+}
+
+func (m *MockAssigneeRepository) GetByCardID(ctx context.Context, cardID int) ([]models.CardAssignee, error) {
+	args := m.Called(ctx, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CardAssignee), args.Error(1)
+}
+
+func (m *MockAssigneeRepository) GetByCardIDs(ctx context.Context, cardIDs []int) (map[int][]models.CardAssignee, error) {
+	args := m.Called(ctx, cardIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]models.CardAssignee), args.Error(1)
+}
+
+func (m *MockAssigneeRepository) GetCardsForUser(ctx context.Context, userID int, boardIDs []int) (map[int][]models.Card, error) {
+	args := m.Called(ctx, userID, boardIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]models.Card), args.Error(1)
+}
+
+func (m *MockCardRepository) UpdateDates(ctx context.Context, card *models.Card) error {
+	args := m.Called(ctx, card)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) SetCompleted(ctx context.Context, card *models.Card) error {
+	args := m.Called(ctx, card)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) GetDueForBoard(ctx context.Context, boardID int, until time.Time) ([]models.Card, error) {
+	args := m.Called(ctx, boardID, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Card), args.Error(1)
+}
+
+func (m *MockCardRepository) ClaimDueSoon(ctx context.Context, now, until time.Time, limit int) (map[int][]models.Card, error) {
+	args := m.Called(ctx, now, until, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]models.Card), args.Error(1)
+}
+
+func (m *MockCardRepository) ClaimOverdue(ctx context.Context, now time.Time, limit int) (map[int][]models.Card, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]models.Card), args.Error(1)
+}
+
+// --- MockChecklistRepository ---
+type MockChecklistRepository struct {
+	mock.Mock
+}
+
+func (m *MockChecklistRepository) Create(ctx context.Context, checklist *models.Checklist) error {
+	args := m.Called(ctx, checklist)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) GetByID(ctx context.Context, checklistID int) (*models.Checklist, error) {
+	args := m.Called(ctx, checklistID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistRepository) GetAllByCardID(ctx context.Context, cardID int) ([]models.Checklist, error) {
+	args := m.Called(ctx, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Checklist), args.Error(1)
+}
+
+func (m *MockChecklistRepository) GetMaxPositionForCard(ctx context.Context, cardID int) (float64, error) {
+	args := m.Called(ctx, cardID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockChecklistRepository) UpdateTitle(ctx context.Context, checklist *models.Checklist) error {
+	args := m.Called(ctx, checklist)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) Delete(ctx context.Context, checklistID int) error {
+	args := m.Called(ctx, checklistID)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) CreateItem(ctx context.Context, item *models.ChecklistItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) GetItemByID(ctx context.Context, itemID int) (*models.ChecklistItem, error) {
+	args := m.Called(ctx, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChecklistItem), args.Error(1)
+}
+
+func (m *MockChecklistRepository) GetMaxItemPosition(ctx context.Context, checklistID int) (float64, error) {
+	args := m.Called(ctx, checklistID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockChecklistRepository) GetItemNeighbourPosition(ctx context.Context, checklistID int, position float64, before bool, excludeItemID int) (*float64, error) {
+	args := m.Called(ctx, checklistID, position, before, excludeItemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+func (m *MockChecklistRepository) RebalanceItems(ctx context.Context, checklistID int, step float64) error {
+	args := m.Called(ctx, checklistID, step)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) UpdateItem(ctx context.Context, item *models.ChecklistItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) MoveItem(ctx context.Context, itemID int, position float64) error {
+	args := m.Called(ctx, itemID, position)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) DeleteItem(ctx context.Context, itemID int) error {
+	args := m.Called(ctx, itemID)
+	return args.Error(0)
+}
+
+func (m *MockChecklistRepository) GetProgressByCardIDs(ctx context.Context, cardIDs []int) (map[int]models.ChecklistProgress, error) {
+	args := m.Called(ctx, cardIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]models.ChecklistProgress), args.Error(1)
+}
+
+// --- MockAttachmentRepository ---
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) GetByID(ctx context.Context, attachmentID int) (*models.Attachment, error) {
+	args := m.Called(ctx, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) GetAllByCardID(ctx context.Context, cardID int) ([]models.Attachment, error) {
+	args := m.Called(ctx, cardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Delete(ctx context.Context, attachmentID int) error {
+	args := m.Called(ctx, attachmentID)
+	return args.Error(0)
+}
+
+// --- MockActivityRepository ---
+type MockActivityRepository struct {
+	mock.Mock
+}
+
+func (m *MockActivityRepository) Create(ctx context.Context, activity *models.Activity) error {
+	args := m.Called(ctx, activity)
+	return args.Error(0)
+}
+
+func (m *MockActivityRepository) GetPageByBoardID(ctx context.Context, boardID int, beforeID int64, limit int) ([]models.Activity, error) {
+	args := m.Called(ctx, boardID, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Activity), args.Error(1)
+}
+
+func (m *MockActivityRepository) GetPageByCardID(ctx context.Context, cardID int, beforeID int64, limit int) ([]models.Activity, error) {
+	args := m.Called(ctx, cardID, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Activity), args.Error(1)
+}
+
+// --- MockSearchRepository ---
+type MockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSearchRepository) Search(ctx context.Context, userID int, filters models.SearchFilters) ([]models.SearchResult, error) {
+	args := m.Called(ctx, userID, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SearchResult), args.Error(1)
+}
+
+// --- MockBoardImportRepository ---
+type MockBoardImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockBoardImportRepository) Import(ctx context.Context, ownerID int, data *models.BoardExport) (*models.BoardImportResult, error) {
+	args := m.Called(ctx, ownerID, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BoardImportResult), args.Error(1)
+}
+
+// --- MockCardExportRepository ---
+type MockCardExportRepository struct {
+	mock.Mock
+}
+
+// StreamByBoardID передаёт в fn строки, заданные первым аргументом Return.
+func (m *MockCardExportRepository) StreamByBoardID(ctx context.Context, boardID int, filter models.CardExportFilter, fn func(*models.CardExportRow) error) error {
+	args := m.Called(ctx, boardID, filter)
+	if rows, ok := args.Get(0).([]models.CardExportRow); ok {
+		for i := range rows {
+			if err := fn(&rows[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// --- MockWebhookRepository ---
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, webhookID int) (*models.Webhook, error) {
+	args := m.Called(ctx, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]models.Webhook, error) {
+	args := m.Called(ctx, boardID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, webhookID int) error {
+	args := m.Called(ctx, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) EnqueueDeliveries(ctx context.Context, boardID int, event string, payload []byte) (int64, error) {
+	args := m.Called(ctx, boardID, event, payload)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.ClaimedWebhookDelivery, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ClaimedWebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RecordAttempt(ctx context.Context, deliveryID int64, attempt models.WebhookAttempt) error {
+	args := m.Called(ctx, deliveryID, attempt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDeliveriesPage(ctx context.Context, webhookID int, beforeID int64, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Redeliver(ctx context.Context, webhookID int, deliveryID int64) error {
+	args := m.Called(ctx, webhookID, deliveryID)
+	return args.Error(0)
+}
+
+// --- MockRefreshTokenRepository ---
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Rotate(ctx context.Context, oldID int64, next *models.RefreshToken) (bool, error) {
+	args := m.Called(ctx, oldID, next)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int) ([]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// --- MockAccountTokenRepository ---
+type MockAccountTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountTokenRepository) Create(ctx context.Context, token *models.AccountToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAccountTokenRepository) Consume(ctx context.Context, purpose models.AccountTokenPurpose, tokenHash string) (*models.AccountToken, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AccountToken), args.Error(1)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	Rotate(ctx context.Context, oldID int64, next *models.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int) ([]string, error)
+}
+
+type refreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at`
+	row := r.db.QueryRowxContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt)
+	if err := row.Scan(&token.ID, &token.CreatedAt); err != nil {
+		return fmt.Errorf("refreshTokenRepository.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.GetContext(ctx, &token, `SELECT * FROM refresh_tokens WHERE token_hash=$1`, tokenHash); err != nil {
+		return nil, fmt.Errorf("refreshTokenRepository.GetByHash: %w", err)
+	}
+	return &token, nil
+}
+
+// Rotate помечает старый токен сменённым и сохраняет следующий токен семейства в одной транзакции.
+// false означает, что старый токен уже сменили или отозвали, - например, параллельный refresh
+// с тем же токеном успел раньше; тогда новый токен не создаётся.
+func (r *refreshTokenRepository) Rotate(ctx context.Context, oldID int64, next *models.RefreshToken) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("refreshTokenRepository.Rotate: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET rotated_at = NOW()
+			  WHERE id=$1 AND rotated_at IS NULL AND revoked_at IS NULL`, oldID)
+	if err != nil {
+		return false, fmt.Errorf("refreshTokenRepository.Rotate: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("refreshTokenRepository.Rotate: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at`
+	row := tx.QueryRowxContext(ctx, query, next.UserID, next.FamilyID, next.TokenHash, next.ExpiresAt)
+	if err := row.Scan(&next.ID, &next.CreatedAt); err != nil {
+		return false, fmt.Errorf("refreshTokenRepository.Rotate: failed to insert next token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("refreshTokenRepository.Rotate: failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id=$1 AND revoked_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, familyID); err != nil {
+		return fmt.Errorf("refreshTokenRepository.RevokeFamily: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser отзывает все сессии пользователя и возвращает их семейства,
+// чтобы можно было отозвать и выданные им access-токены.
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int) ([]string, error) {
+	families := []string{}
+	query := `WITH revoked AS (
+				UPDATE refresh_tokens SET revoked_at = NOW()
+				WHERE user_id=$1 AND revoked_at IS NULL
+				RETURNING family_id
+			  )
+			  SELECT DISTINCT family_id FROM revoked`
+	if err := r.db.SelectContext(ctx, &families, query, userID); err != nil {
+		return nil, fmt.Errorf("refreshTokenRepository.RevokeAllForUser: %w", err)
+	}
+	return families, nil
+}
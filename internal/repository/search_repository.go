@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, userID int, filters models.SearchFilters) ([]models.SearchResult, error)
+}
+
+type searchRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchRepository(db *sqlx.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// searchHeadline - параметры ts_headline. Разметка <mark> - единственные теги во фрагменте:
+// исходный текст экранируется через escapeHTML до того, как ts_headline её добавит.
+const searchHeadline = `'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2'`
+
+// escapeHTML возвращает SQL-выражение, экранирующее &, < и > в тексте expr. Сущности вроде &lt;
+// парсер полнотекстового поиска пропускает, поэтому на совпадения экранирование не влияет.
+func escapeHTML(expr string) string {
+	return `replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+}
+
+// cardSearchFilters - условия фильтров по карточке c, общие для карточек и комментариев.
+const cardSearchFilters = `c.archived_at IS NULL AND l.archived_at IS NULL
+				AND ($4::int IS NULL OR EXISTS (SELECT 1 FROM card_labels cl WHERE cl.card_id = c.id AND cl.label_id = $4))
+				AND ($5::int IS NULL OR EXISTS (SELECT 1 FROM card_assignees ca WHERE ca.card_id = c.id AND ca.user_id = $5))
+				AND ($6::timestamptz IS NULL OR c.due_at >= $6)
+				AND ($7::timestamptz IS NULL OR c.due_at <= $7)`
+
+// Search ищет по доскам, спискам, карточкам и комментариям, доступным пользователю.
+// Доступ определяется тем же соединением с board_members, что и в boardRepository.GetAllForUser.
+func (r *searchRepository) Search(ctx context.Context, userID int, filters models.SearchFilters) ([]models.SearchResult, error) {
+	query := `WITH accessible AS (
+				SELECT DISTINCT b.id, b.name FROM ` + boardMembershipFrom + `
+				AND b.archived_at IS NULL AND ($3::int IS NULL OR b.id = $3)
+			  ), q AS (
+				SELECT websearch_to_tsquery('simple', $2) AS tsq
+			  )
+			  SELECT 'board' AS type, b.id, b.id AS board_id, a.name AS board_name,
+				NULL::int AS list_id, NULL::int AS card_id, b.name AS title,
+				ts_headline('simple', ` + escapeHTML(`b.name`) + `, q.tsq, ` + searchHeadline + `) AS snippet,
+				ts_rank(b.search_vector, q.tsq) AS rank
+			  FROM boards b JOIN accessible a ON a.id = b.id, q
+			  WHERE NOT $8 AND b.search_vector @@ q.tsq
+			  UNION ALL
+			  SELECT 'list', l.id, l.board_id, a.name, l.id, NULL, l.title,
+				ts_headline('simple', ` + escapeHTML(`l.title`) + `, q.tsq, ` + searchHeadline + `),
+				ts_rank(l.search_vector, q.tsq)
+			  FROM lists l JOIN accessible a ON a.id = l.board_id, q
+			  WHERE NOT $8 AND l.archived_at IS NULL AND l.search_vector @@ q.tsq
+			  UNION ALL
+			  SELECT 'card', c.id, l.board_id, a.name, c.list_id, c.id, c.title,
+				ts_headline('simple', ` + escapeHTML(`c.title || ' ' || c.description`) + `, q.tsq, ` + searchHeadline + `),
+				ts_rank(c.search_vector, q.tsq)
+			  FROM cards c JOIN lists l ON l.id = c.list_id JOIN accessible a ON a.id = l.board_id, q
+			  WHERE c.search_vector @@ q.tsq AND ` + cardSearchFilters + `
+			  UNION ALL
+			  SELECT 'comment', cm.id, l.board_id, a.name, c.list_id, c.id, c.title,
+				ts_headline('simple', ` + escapeHTML(`cm.body`) + `, q.tsq, ` + searchHeadline + `),
+				ts_rank(cm.search_vector, q.tsq)
+			  FROM comments cm JOIN cards c ON c.id = cm.card_id JOIN lists l ON l.id = c.list_id
+				JOIN accessible a ON a.id = l.board_id, q
+			  WHERE cm.search_vector @@ q.tsq AND ` + cardSearchFilters + `
+			  ORDER BY rank DESC, type ASC, id ASC
+			  LIMIT $9`
+
+	results := []models.SearchResult{}
+	err := r.db.SelectContext(ctx, &results, query, userID, filters.Query, filters.BoardID, filters.LabelID,
+		filters.AssigneeID, filters.DueFrom, filters.DueTo, filters.CardOnly(), filters.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("searchRepository.Search: %w", err)
+	}
+	return results, nil
+}
@@ -2,19 +2,30 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"notes-project/internal/models"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// ErrDuplicateEmail - email уже занят другим аккаунтом (нарушение уникальности users.email).
+var ErrDuplicateEmail = errors.New("email is already registered")
+
+const uniqueViolation = "23505"
+
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetAll(ctx context.Context) ([]models.User, error)
 	GetByID(ctx context.Context, id int) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
-	Delete(ctx context.Context, id int) error
+	SetAdmin(ctx context.Context, id int, isAdmin bool) error
+	SetPassword(ctx context.Context, id int, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int) (*models.UserDeletion, error)
 }
 
 type userRepository struct {
@@ -32,14 +43,19 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 
 	row := r.db.QueryRowxContext(ctx, query, user.Name, user.Age, user.Email, user.PasswordHash)
 	if err := row.Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return ErrDuplicateEmail
+		}
 		return fmt.Errorf("userRepository.Create: %w", err)
 	}
 	return nil
 }
 
+// GetByEmail ищет без учёта регистра: email хранится в нижнем регистре.
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	query := "SELECT * FROM users WHERE email=$1"
+	query := "SELECT * FROM users WHERE email=LOWER($1)"
 	if err := r.db.GetContext(ctx, &user, query, email); err != nil {
 		return nil, fmt.Errorf("userRepository.GetByEmail: %w", err)
 	}
@@ -73,8 +89,121 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	return row.Scan(&user.UpdatedAt)
 }
 
-func (r *userRepository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM users WHERE id=$1"
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+func (r *userRepository) SetAdmin(ctx context.Context, id int, isAdmin bool) error {
+	query := `UPDATE users SET is_admin=$1, updated_at=NOW() WHERE id=$2`
+	result, err := r.db.ExecContext(ctx, query, isAdmin, id)
+	if err != nil {
+		return fmt.Errorf("userRepository.SetAdmin: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("userRepository.SetAdmin: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with id %d not found: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *userRepository) SetPassword(ctx context.Context, id int, passwordHash string) error {
+	query := `UPDATE users SET password_hash=$1, updated_at=NOW() WHERE id=$2`
+	result, err := r.db.ExecContext(ctx, query, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("userRepository.SetPassword: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("userRepository.SetPassword: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with id %d not found: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// MarkEmailVerified не перезаписывает дату, если почта уже подтверждена.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	query := `UPDATE users SET email_verified_at=COALESCE(email_verified_at, NOW()), updated_at=NOW() WHERE id=$1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("userRepository.MarkEmailVerified: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет пользователя в одной транзакции с судьбой его досок. Каждая доска, где есть
+// другие участники, переходит к участнику с самой высокой ролью (при равенстве - с меньшим id);
+// доска без других участников удаляется. Остальное (участие в чужих досках, комментарии,
+// назначения) удаляется каскадом.
+func (r *userRepository) Delete(ctx context.Context, id int) (*models.UserDeletion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.Delete: failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var boards []struct {
+		BoardID     int  `db:"board_id"`
+		SuccessorID *int `db:"successor_id"`
+	}
+	query := `SELECT b.id AS board_id, (
+				SELECT bm.user_id FROM board_members bm
+				WHERE bm.board_id = b.id AND bm.user_id <> $1
+				ORDER BY CASE bm.role WHEN 'admin' THEN 1 WHEN 'member' THEN 2 ELSE 3 END, bm.user_id
+				LIMIT 1
+			  ) AS successor_id
+			  FROM boards b WHERE b.owner_id = $1
+			  ORDER BY b.id
+			  FOR UPDATE OF b`
+	if err := tx.SelectContext(ctx, &boards, query, id); err != nil {
+		return nil, fmt.Errorf("userRepository.Delete: failed to load owned boards: %w", err)
+	}
+
+	deletion := &models.UserDeletion{TransferredBoards: []models.BoardTransfer{}, DeletedBoards: []int{}, BlobKeys: []string{}}
+	queryMemberBoards := `SELECT bm.board_id FROM board_members bm JOIN boards b ON b.id = bm.board_id
+						  WHERE bm.user_id = $1 AND b.owner_id <> $1 ORDER BY bm.board_id`
+	if err := tx.SelectContext(ctx, &deletion.MemberBoards, queryMemberBoards, id); err != nil {
+		return nil, fmt.Errorf("userRepository.Delete: failed to load member boards: %w", err)
+	}
+	for _, board := range boards {
+		if board.SuccessorID == nil {
+			keys, err := deleteAttachmentsOf(ctx, tx,
+				`SELECT c.id FROM cards c JOIN lists l ON l.id = c.list_id WHERE l.board_id = $1`, board.BoardID)
+			if err != nil {
+				return nil, fmt.Errorf("userRepository.Delete: board %d: %w", board.BoardID, err)
+			}
+			deletion.BlobKeys = append(deletion.BlobKeys, keys...)
+			if _, err := tx.ExecContext(ctx, `DELETE FROM boards WHERE id=$1`, board.BoardID); err != nil {
+				return nil, fmt.Errorf("userRepository.Delete: failed to delete board %d: %w", board.BoardID, err)
+			}
+			deletion.DeletedBoards = append(deletion.DeletedBoards, board.BoardID)
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE boards SET owner_id=$1, updated_at=NOW() WHERE id=$2`,
+			*board.SuccessorID, board.BoardID); err != nil {
+			return nil, fmt.Errorf("userRepository.Delete: failed to transfer board %d: %w", board.BoardID, err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE board_members SET role=$1 WHERE board_id=$2 AND user_id=$3`,
+			models.RoleOwner, board.BoardID, *board.SuccessorID); err != nil {
+			return nil, fmt.Errorf("userRepository.Delete: failed to promote owner of board %d: %w", board.BoardID, err)
+		}
+		deletion.TransferredBoards = append(deletion.TransferredBoards,
+			models.BoardTransfer{BoardID: board.BoardID, NewOwnerID: *board.SuccessorID})
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id=$1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.Delete: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("userRepository.Delete: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("user with id %d not found: %w", id, sql.ErrNoRows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("userRepository.Delete: failed to commit transaction: %w", err)
+	}
+	return deletion, nil
 }
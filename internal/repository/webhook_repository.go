@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"notes-project/internal/models"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, webhookID int) (*models.Webhook, error)
+	GetAllByBoardID(ctx context.Context, boardID int) ([]models.Webhook, error)
+	Update(ctx context.Context, webhook *models.Webhook) error
+	Delete(ctx context.Context, webhookID int) error
+
+	EnqueueDeliveries(ctx context.Context, boardID int, event string, payload []byte) (int64, error)
+	ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.ClaimedWebhookDelivery, error)
+	RecordAttempt(ctx context.Context, deliveryID int64, attempt models.WebhookAttempt) error
+	GetDeliveriesPage(ctx context.Context, webhookID int, beforeID int64, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID int, deliveryID int64) error
+}
+
+type webhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	query := `INSERT INTO webhooks (board_id, url, secret, events, active, created_by) VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at, updated_at`
+	row := r.db.QueryRowxContext(ctx, query, webhook.BoardID, webhook.URL, webhook.Secret, webhook.Events,
+		webhook.Active, webhook.CreatedBy)
+	if err := row.Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return fmt.Errorf("webhookRepository.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, webhookID int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := r.db.GetContext(ctx, &webhook, `SELECT * FROM webhooks WHERE id=$1`, webhookID); err != nil {
+		return nil, fmt.Errorf("webhookRepository.GetByID: %w", err)
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) GetAllByBoardID(ctx context.Context, boardID int) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	query := `SELECT * FROM webhooks WHERE board_id=$1 ORDER BY id ASC`
+	if err := r.db.SelectContext(ctx, &webhooks, query, boardID); err != nil {
+		return nil, fmt.Errorf("webhookRepository.GetAllByBoardID: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	query := `UPDATE webhooks SET url=$1, events=$2, active=$3, updated_at=NOW() WHERE id=$4 RETURNING updated_at`
+	row := r.db.QueryRowxContext(ctx, query, webhook.URL, webhook.Events, webhook.Active, webhook.ID)
+	if err := row.Scan(&webhook.UpdatedAt); err != nil {
+		return fmt.Errorf("webhookRepository.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, webhookID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id=$1`, webhookID)
+	if err != nil {
+		return fmt.Errorf("webhookRepository.Delete: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("webhookRepository.Delete: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook with id %d not found: %w", webhookID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// EnqueueDeliveries ставит событие в очередь каждой активной подписке доски, которая его ждёт.
+// Возвращает число созданных доставок.
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, boardID int, event string, payload []byte) (int64, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event, payload)
+			  SELECT w.id, $2, $3 FROM webhooks w
+			  WHERE w.board_id = $1 AND w.active AND (cardinality(w.events) = 0 OR $2 = ANY (w.events))`
+	result, err := r.db.ExecContext(ctx, query, boardID, event, string(payload))
+	if err != nil {
+		return 0, fmt.Errorf("webhookRepository.EnqueueDeliveries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("webhookRepository.EnqueueDeliveries: failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// ClaimDeliveries забирает до limit доставок, которым пора уходить, и откладывает их следующую
+// попытку до leaseUntil. Если воркер упадёт посреди отправки, доставка вернётся в работу после
+// истечения аренды; SKIP LOCKED не даёт двум экземплярам взять одну и ту же доставку.
+// Доставки выключенных подписок ждут, пока подписку не включат снова.
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.ClaimedWebhookDelivery, error) {
+	deliveries := []models.ClaimedWebhookDelivery{}
+	query := `UPDATE webhook_deliveries d SET next_attempt_at = $2
+			  FROM webhooks w
+			  WHERE w.id = d.webhook_id AND d.id IN (
+				SELECT d2.id FROM webhook_deliveries d2
+				JOIN webhooks w2 ON w2.id = d2.webhook_id
+				WHERE d2.status = 'pending' AND d2.next_attempt_at <= $1 AND w2.active
+				ORDER BY d2.next_attempt_at ASC, d2.id ASC
+				LIMIT $3
+				FOR UPDATE OF d2 SKIP LOCKED
+			  )
+			  RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, w.url, w.secret`
+	if err := r.db.SelectContext(ctx, &deliveries, query, now, leaseUntil, limit); err != nil {
+		return nil, fmt.Errorf("webhookRepository.ClaimDeliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, deliveryID int64, attempt models.WebhookAttempt) error {
+	query := `UPDATE webhook_deliveries
+			  SET attempts = attempts + 1, status = $1, last_status_code = $2, last_error = $3, next_attempt_at = $4,
+				  delivered_at = CASE WHEN $1 = 'delivered' THEN $5::timestamptz END
+			  WHERE id = $6`
+	if _, err := r.db.ExecContext(ctx, query, attempt.Status, attempt.StatusCode, attempt.Error,
+		attempt.NextAttemptAt, attempt.At, deliveryID); err != nil {
+		return fmt.Errorf("webhookRepository.RecordAttempt: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveriesPage возвращает до limit доставок подписки с id меньше beforeID, от новых к старым.
+// beforeID = 0 означает первую страницу.
+func (r *webhookRepository) GetDeliveriesPage(ctx context.Context, webhookID int, beforeID int64, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	query := `SELECT * FROM webhook_deliveries
+			  WHERE webhook_id=$1 AND ($2 = 0 OR id < $2)
+			  ORDER BY id DESC
+			  LIMIT $3`
+	if err := r.db.SelectContext(ctx, &deliveries, query, webhookID, beforeID, limit); err != nil {
+		return nil, fmt.Errorf("webhookRepository.GetDeliveriesPage: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver возвращает доставку в очередь с чистым счётчиком попыток.
+// Уже доставленное тоже можно отправить повторно, если получатель его потерял.
+func (r *webhookRepository) Redeliver(ctx context.Context, webhookID int, deliveryID int64) error {
+	query := `UPDATE webhook_deliveries
+			  SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+			  WHERE id = $1 AND webhook_id = $2`
+	result, err := r.db.ExecContext(ctx, query, deliveryID, webhookID)
+	if err != nil {
+		return fmt.Errorf("webhookRepository.Redeliver: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("webhookRepository.Redeliver: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("delivery with id %d not found: %w", deliveryID, sql.ErrNoRows)
+	}
+	return nil
+}
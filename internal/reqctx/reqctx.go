@@ -0,0 +1,71 @@
+// Package reqctx - типизированный доступ к данным запроса: пользователю, которого положил
+// AuthMiddleware, и числовым параметрам пути. Обработчики не читают c.Get("userId")
+// и не приводят типы сами, поэтому отсутствие значения даёт ошибку, а не панику.
+package reqctx
+
+import (
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	userIDKey = "userId"
+	claimsKey = "tokenClaims"
+)
+
+var ErrNoUser = apperr.Unauthorized("unauthorized", "user is not authenticated")
+
+// SetUser сохраняет в контексте проверенные claims access-токена.
+func SetUser(c *gin.Context, claims *models.AccessClaims) {
+	c.Set(userIDKey, claims.UserID)
+	c.Set(claimsKey, claims)
+}
+
+// CurrentUser возвращает id пользователя из access-токена запроса.
+func CurrentUser(c *gin.Context) (int, error) {
+	userID, ok := c.Value(userIDKey).(int)
+	if !ok || userID <= 0 {
+		return 0, ErrNoUser
+	}
+	return userID, nil
+}
+
+// Claims возвращает claims access-токена запроса, они нужны для выхода из текущей сессии.
+func Claims(c *gin.Context) (*models.AccessClaims, error) {
+	claims, ok := c.Value(claimsKey).(*models.AccessClaims)
+	if !ok || claims == nil {
+		return nil, ErrNoUser
+	}
+	return claims, nil
+}
+
+// PathInt разбирает положительный числовой параметр пути: PathInt(c, "boardId").
+func PathInt(c *gin.Context, name string) (int, error) {
+	value, err := parsePathInt(c, name, strconv.IntSize)
+	return int(value), err
+}
+
+// PathInt64 - то же для идентификаторов BIGSERIAL, например доставок вебхуков.
+func PathInt64(c *gin.Context, name string) (int64, error) {
+	return parsePathInt(c, name, 64)
+}
+
+func parsePathInt(c *gin.Context, name string, bitSize int) (int64, error) {
+	value, err := strconv.ParseInt(c.Param(name), 10, bitSize)
+	if err != nil || value <= 0 {
+		return 0, apperr.Validation("invalid_id", "invalid %s", paramLabel(name))
+	}
+	return value, nil
+}
+
+// paramLabel превращает имя параметра в текст для сообщения: "boardId" -> "board id".
+func paramLabel(name string) string {
+	if base := strings.TrimSuffix(name, "Id"); base != name && base != "" {
+		return base + " id"
+	}
+	return name
+}
@@ -0,0 +1,54 @@
+package reqctx
+
+import (
+	"net/http/httptest"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(params ...gin.Param) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Params = params
+	return c
+}
+
+func TestCurrentUser(t *testing.T) {
+	empty := newContext()
+	wrongType := newContext()
+	wrongType.Set(userIDKey, "7")
+	authenticated := newContext()
+	SetUser(authenticated, &models.AccessClaims{UserID: 7})
+
+	_, errEmpty := CurrentUser(empty)
+	_, errWrongType := CurrentUser(wrongType)
+	userID, err := CurrentUser(authenticated)
+	claims, errClaims := Claims(authenticated)
+
+	assert.ErrorIs(t, errEmpty, ErrNoUser)
+	assert.ErrorIs(t, errWrongType, ErrNoUser)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, userID)
+	assert.NoError(t, errClaims)
+	assert.Equal(t, 7, claims.UserID)
+}
+
+func TestPathInt(t *testing.T) {
+	for _, raw := range []string{"", "abc", "0", "-1", "1.5", "99999999999999999999"} {
+		_, err := PathInt(newContext(gin.Param{Key: "boardId", Value: raw}), "boardId")
+
+		assert.ErrorIs(t, err, apperr.ErrValidation, raw)
+		assert.EqualError(t, err, "invalid board id", raw)
+	}
+
+	id, err := PathInt(newContext(gin.Param{Key: "boardId", Value: "42"}), "boardId")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	deliveryID, err := PathInt64(newContext(gin.Param{Key: "deliveryId", Value: "5000000000"}), "deliveryId")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5000000000), deliveryID)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"notes-project/internal/apperr"
+	"notes-project/internal/mailer"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"notes-project/internal/validation"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+var (
+	ErrInvalidAccountToken  = apperr.Validation("invalid_token", "invalid, expired or already used token")
+	ErrWrongPassword        = apperr.Forbidden("wrong_password", "current password is incorrect")
+	ErrEmailAlreadyVerified = apperr.Conflict("email_already_verified", "email is already verified")
+)
+
+// EmailVerificationChecker нужен middleware, который не пускает неподтверждённые аккаунты к изменениям.
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID int) (bool, error)
+}
+
+type AccountService interface {
+	EmailVerificationChecker
+	SendVerification(ctx context.Context, user *models.User) error
+	ResendVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, token string) error
+	ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string) (*models.TokenPair, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+type accountService struct {
+	userRepo    repository.UserRepository
+	tokenRepo   repository.AccountTokenRepository
+	tokens      TokenService
+	invitations InvitationService
+	mailer      mailer.Mailer
+	baseURL     string
+	now         func() time.Time
+}
+
+func NewAccountService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.AccountTokenRepository,
+	tokens TokenService,
+	invitations InvitationService,
+	mailer mailer.Mailer,
+	baseURL string) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		tokens:      tokens,
+		invitations: invitations,
+		mailer:      mailer,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		now:         time.Now,
+	}
+}
+
+func (s *accountService) IsEmailVerified(ctx context.Context, userID int) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, apperr.NoRows(err, ErrUserNotFound)
+	}
+	return user.EmailVerified(), nil
+}
+
+func (s *accountService) SendVerification(ctx context.Context, user *models.User) error {
+	token, expiresAt, err := s.issue(ctx, user.ID, models.PurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Confirm your email address to start editing boards: %s/users/verify-email?token=%s\n\n"+
+			"The link expires on %s.",
+			s.baseURL, url.QueryEscape(token), expiresAt.Format(time.RFC1123)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("could not send verification email: %w", err)
+	}
+	return nil
+}
+
+func (s *accountService) ResendVerification(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return apperr.NoRows(err, ErrUserNotFound)
+	}
+	if user.EmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	return s.SendVerification(ctx, user)
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	accountToken, err := s.consume(ctx, models.PurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	return s.markEmailVerified(ctx, accountToken.UserID)
+}
+
+// ChangePassword меняет пароль и завершает все сессии пользователя. Текущая сессия
+// продолжается с новой парой токенов, которую возвращает метод.
+func (s *accountService) ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string) (*models.TokenPair, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrUserNotFound)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return nil, ErrWrongPassword
+	}
+	if err := s.setPassword(ctx, userID, newPassword); err != nil {
+		return nil, err
+	}
+	return s.tokens.Issue(ctx, userID)
+}
+
+// RequestPasswordReset отправляет ссылку для сброса пароля. Для неизвестного email ошибка
+// не возвращается, чтобы по ответу нельзя было узнать, зарегистрирован ли адрес.
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, validation.NormalizeEmail(email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, expiresAt, err := s.issue(ctx, user.ID, models.PurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone requested a password reset for your account.\n\n"+
+			"Set a new password: %s/users/reset-password?token=%s\n\n"+
+			"The link expires on %s. If it wasn't you, ignore this email.",
+			s.baseURL, url.QueryEscape(token), expiresAt.Format(time.RFC1123)),
+	}
+	// Ошибка отправки тоже не отдаётся клиенту: иначе она выдала бы существование адреса.
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("could not send password reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword задаёт новый пароль по токену из письма. Письмо пришло на почту аккаунта,
+// поэтому она заодно считается подтверждённой.
+func (s *accountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Пароль проверяется до того, как будет израсходован токен.
+	if err := checkPassword(newPassword); err != nil {
+		return err
+	}
+	accountToken, err := s.consume(ctx, models.PurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+	if err := s.setPassword(ctx, accountToken.UserID, newPassword); err != nil {
+		return err
+	}
+	return s.markEmailVerified(ctx, accountToken.UserID)
+}
+
+// markEmailVerified подтверждает почту и только после этого принимает приглашения,
+// отправленные на этот адрес: иначе доску получил бы любой, кто зарегистрировался с чужим email.
+func (s *accountService) markEmailVerified(ctx context.Context, userID int) error {
+	if err := s.userRepo.MarkEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Printf("could not load user %d to accept pending invitations: %v", userID, err)
+		return nil
+	}
+	if err := s.invitations.AcceptPendingForUser(ctx, user); err != nil {
+		log.Printf("could not accept pending invitations for user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// setPassword сохраняет новый пароль и отзывает все сессии: украденный refresh-токен
+// не должен пережить смену пароля.
+func (s *accountService) setPassword(ctx context.Context, userID int, password string) error {
+	if err := checkPassword(password); err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.SetPassword(ctx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.tokens.LogoutAll(ctx, userID)
+}
+
+func (s *accountService) issue(ctx context.Context, userID int, purpose models.AccountTokenPurpose, ttl time.Duration) (string, time.Time, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	accountToken := &models.AccountToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: s.now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, accountToken); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, accountToken.ExpiresAt, nil
+}
+
+func (s *accountService) consume(ctx context.Context, purpose models.AccountTokenPurpose, token string) (*models.AccountToken, error) {
+	accountToken, err := s.tokenRepo.Consume(ctx, purpose, hashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAccountToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return accountToken, nil
+}
+
+func checkPassword(password string) error {
+	v := validation.New()
+	v.Password("password", password)
+	return v.Err()
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"notes-project/internal/mailer"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"notes-project/internal/validation"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testAccountNow = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type accountServiceFixture struct {
+	service      *accountService
+	userRepo     *repository.MockUserRepository
+	tokenRepo    *repository.MockAccountTokenRepository
+	refreshRepo  *repository.MockRefreshTokenRepository
+	inviteRepo   *repository.MockInvitationRepository
+	broadcaster  *MockBroadcaster
+	mailer       *mailer.MemoryMailer
+	mockDenylist *MockTokenDenylist
+}
+
+func newAccountServiceFixture() *accountServiceFixture {
+	f := &accountServiceFixture{
+		userRepo:     new(repository.MockUserRepository),
+		tokenRepo:    new(repository.MockAccountTokenRepository),
+		refreshRepo:  new(repository.MockRefreshTokenRepository),
+		inviteRepo:   new(repository.MockInvitationRepository),
+		broadcaster:  new(MockBroadcaster),
+		mailer:       mailer.NewMemoryMailer(),
+		mockDenylist: new(MockTokenDenylist),
+	}
+	tokens := newTestTokenService(f.refreshRepo, f.mockDenylist)
+	invitations := NewInvitationService(f.inviteRepo, nil, f.userRepo, nil, f.mailer, newNopActivityRecorder(),
+		f.broadcaster, func(ctx context.Context, boardID int) {}, []byte("test-secret"), "https://app.example.com/")
+	f.service = NewAccountService(f.userRepo, f.tokenRepo, tokens, invitations, f.mailer, "https://app.example.com/").(*accountService)
+	f.service.now = func() time.Time { return testAccountNow }
+	return f
+}
+
+func tokenFromMail(t *testing.T, body string) string {
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(body)
+	if !assert.Len(t, match, 2, "mail has no token link") {
+		t.FailNow()
+	}
+	token, err := url.QueryUnescape(match[1])
+	assert.NoError(t, err)
+	return token
+}
+
+func hashPassword(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	return string(hash)
+}
+
+func TestAccountService_RequestPasswordReset_StoresOnlyHash(t *testing.T) {
+	// --- ARRANGE ---
+	f := newAccountServiceFixture()
+	f.userRepo.On("GetByEmail", mock.Anything, "alice@example.com").
+		Return(&models.User{ID: 1, Email: "alice@example.com"}, nil)
+	var stored *models.AccountToken
+	f.tokenRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.AccountToken)
+	}).Return(nil).Once()
+
+	// --- ACT ---
+	err := f.service.RequestPasswordReset(context.Background(), " alice@example.com ")
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	messages := f.mailer.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "alice@example.com", messages[0].To)
+	assert.Contains(t, messages[0].Body, "https://app.example.com/users/reset-password?token=")
+	token := tokenFromMail(t, messages[0].Body)
+	assert.Equal(t, models.PurposePasswordReset, stored.Purpose)
+	assert.Equal(t, hashToken(token), stored.TokenHash)
+	assert.NotEqual(t, token, stored.TokenHash)
+	assert.Equal(t, testAccountNow.Add(time.Hour), stored.ExpiresAt)
+}
+
+func TestAccountService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	f := newAccountServiceFixture()
+	f.userRepo.On("GetByEmail", mock.Anything, "nobody@example.com").
+		Return(nil, fmt.Errorf("userRepository.GetByEmail: %w", sql.ErrNoRows))
+
+	err := f.service.RequestPasswordReset(context.Background(), "nobody@example.com")
+
+	// Ответ не отличается от ответа для существующего адреса, письмо не уходит.
+	assert.NoError(t, err)
+	assert.Empty(t, f.mailer.Messages())
+	f.tokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	// --- ARRANGE ---
+	f := newAccountServiceFixture()
+	f.tokenRepo.On("Consume", mock.Anything, models.PurposePasswordReset, hashToken("good")).
+		Return(&models.AccountToken{UserID: 1, Purpose: models.PurposePasswordReset}, nil).Once()
+	f.tokenRepo.On("Consume", mock.Anything, models.PurposePasswordReset, hashToken("used")).
+		Return(nil, fmt.Errorf("accountTokenRepository.Consume: %w", sql.ErrNoRows)).Once()
+	var newHash string
+	f.userRepo.On("SetPassword", mock.Anything, 1, mock.Anything).Run(func(args mock.Arguments) {
+		newHash = args.String(2)
+	}).Return(nil).Once()
+	f.userRepo.On("MarkEmailVerified", mock.Anything, 1).Return(nil).Once()
+	f.userRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, Email: "alice@example.com"}, nil).Once()
+	f.inviteRepo.On("GetPendingForEmail", mock.Anything, "alice@example.com").Return([]models.Invitation{}, nil).Once()
+	f.refreshRepo.On("RevokeAllForUser", mock.Anything, 1).Return([]string{}, nil).Once()
+
+	// --- ACT ---
+	errWeak := f.service.ResetPassword(context.Background(), "good", "short")
+	errUsed := f.service.ResetPassword(context.Background(), "used", "new-password")
+	err := f.service.ResetPassword(context.Background(), "good", "new-password")
+
+	// --- ASSERT ---
+	var fieldErrs validation.Errors
+	assert.ErrorAs(t, errWeak, &fieldErrs)
+	assert.ErrorIs(t, errUsed, ErrInvalidAccountToken)
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("new-password")))
+	// Слабый пароль отклоняется до того, как токен будет израсходован.
+	f.tokenRepo.AssertNumberOfCalls(t, "Consume", 2)
+	f.tokenRepo.AssertExpectations(t)
+	f.userRepo.AssertExpectations(t)
+	f.refreshRepo.AssertExpectations(t)
+}
+
+func TestAccountService_ChangePassword(t *testing.T) {
+	// --- ARRANGE ---
+	f := newAccountServiceFixture()
+	f.userRepo.On("GetByID", mock.Anything, 1).
+		Return(&models.User{ID: 1, PasswordHash: hashPassword(t, "old-password")}, nil)
+	f.userRepo.On("SetPassword", mock.Anything, 1, mock.Anything).Return(nil).Once()
+	f.refreshRepo.On("RevokeAllForUser", mock.Anything, 1).Return([]string{"fam-1"}, nil).Once()
+	f.mockDenylist.On("RevokeFamily", mock.Anything, "fam-1", 15*time.Minute).Return(nil).Once()
+	f.refreshRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+
+	// --- ACT ---
+	_, errWrong := f.service.ChangePassword(context.Background(), 1, "not-it", "new-password")
+	pair, err := f.service.ChangePassword(context.Background(), 1, "old-password", "new-password")
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, errWrong, ErrWrongPassword)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
+	f.userRepo.AssertExpectations(t)
+	f.refreshRepo.AssertExpectations(t)
+	f.mockDenylist.AssertExpectations(t)
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	// --- ARRANGE ---
+	f := newAccountServiceFixture()
+	f.userRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, Email: "alice@example.com"}, nil).Once()
+	var stored *models.AccountToken
+	f.tokenRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.AccountToken)
+	}).Return(nil).Once()
+
+	// --- ACT ---
+	err := f.service.ResendVerification(context.Background(), 1)
+	assert.NoError(t, err)
+	token := tokenFromMail(t, f.mailer.Messages()[0].Body)
+	f.tokenRepo.On("Consume", mock.Anything, models.PurposeEmailVerification, stored.TokenHash).
+		Return(&models.AccountToken{UserID: 1, Purpose: models.PurposeEmailVerification}, nil).Once()
+	f.userRepo.On("MarkEmailVerified", mock.Anything, 1).Return(nil).Once()
+	f.userRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, Email: "alice@example.com"}, nil).Once()
+	f.inviteRepo.On("GetPendingForEmail", mock.Anything, "alice@example.com").Return([]models.Invitation{}, nil).Once()
+	err = f.service.VerifyEmail(context.Background(), token)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, testAccountNow.Add(48*time.Hour), stored.ExpiresAt)
+	f.tokenRepo.AssertExpectations(t)
+	f.userRepo.AssertExpectations(t)
+}
+
+func TestAccountService_ResendVerification_AlreadyVerified(t *testing.T) {
+	f := newAccountServiceFixture()
+	verifiedAt := testAccountNow
+	f.userRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, EmailVerifiedAt: &verifiedAt}, nil)
+
+	err := f.service.ResendVerification(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+	assert.Empty(t, f.mailer.Messages())
+}
+
+func TestAccountService_PendingInvitationsWaitForVerification(t *testing.T) {
+	// --- ARRANGE ---
+	f := newAccountServiceFixture()
+	userService := NewUserService(f.userRepo, f.service, nil, nil, nil, func(ctx context.Context, boardID int) {})
+	var stored *models.AccountToken
+	f.userRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.User).ID = 7
+	}).Return(nil).Once()
+	f.tokenRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.AccountToken)
+	}).Return(nil).Once()
+
+	// --- ACT ---
+	err := userService.Register(context.Background(),
+		&models.User{Name: "Mallory", Email: "bob@example.com", Password: "correct horse"})
+
+	// --- ASSERT ---
+	// Пока почта не подтверждена, приглашение на этот адрес не даёт доступа к доске.
+	assert.NoError(t, err)
+	f.inviteRepo.AssertNotCalled(t, "GetPendingForEmail", mock.Anything, mock.Anything)
+	f.inviteRepo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything)
+
+	// --- ACT ---
+	token := tokenFromMail(t, f.mailer.Messages()[0].Body)
+	f.tokenRepo.On("Consume", mock.Anything, models.PurposeEmailVerification, stored.TokenHash).
+		Return(&models.AccountToken{UserID: 7, Purpose: models.PurposeEmailVerification}, nil).Once()
+	f.userRepo.On("MarkEmailVerified", mock.Anything, 7).Return(nil).Once()
+	f.userRepo.On("GetByID", mock.Anything, 7).Return(&models.User{ID: 7, Name: "Bob", Email: "bob@example.com"}, nil).Once()
+	f.inviteRepo.On("GetPendingForEmail", mock.Anything, "bob@example.com").
+		Return([]models.Invitation{{ID: 3, BoardID: 1, Email: "bob@example.com", Role: models.RoleMember}}, nil).Once()
+	f.inviteRepo.On("Accept", mock.Anything, 3, 7).
+		Return(&models.Invitation{ID: 3, BoardID: 1, Role: models.RoleMember}, nil).Once()
+	f.broadcaster.On("BroadcastToBoard", 1, mock.Anything).Return().Once()
+	err = f.service.VerifyEmail(context.Background(), token)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	f.inviteRepo.AssertExpectations(t)
+	f.broadcaster.AssertExpectations(t)
+}
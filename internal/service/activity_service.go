@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"strconv"
+)
+
+var ErrInvalidActivityCursor = apperr.Validation("invalid_cursor", "invalid cursor")
+
+// ActivityEntry описывает одно действие для журнала. Before и After сериализуются в JSON;
+// nil означает, что состояния до (или после) нет.
+type ActivityEntry struct {
+	BoardID    int
+	CardID     *int
+	ActorID    int
+	Action     string
+	EntityType string
+	EntityID   int
+	Before     interface{}
+	After      interface{}
+}
+
+// ActivityRecorder пишет действия в журнал. Ошибки записи только логируются:
+// недоступность журнала не должна ломать само изменение.
+type ActivityRecorder interface {
+	Record(ctx context.Context, entry ActivityEntry)
+}
+
+type ActivityService interface {
+	ActivityRecorder
+	ListForBoard(ctx context.Context, boardID, userID int, cursor string, limit int) (*models.ActivityPage, error)
+	ListForCard(ctx context.Context, cardID, userID int, cursor string, limit int) (*models.ActivityPage, error)
+}
+
+type activityService struct {
+	repo        repository.ActivityRepository
+	access      cardAccess
+	broadcaster Broadcaster
+}
+
+func NewActivityService(
+	repo repository.ActivityRepository,
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	permissions BoardPermissions,
+	broadcaster Broadcaster) ActivityService {
+	return &activityService{
+		repo:        repo,
+		access:      cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		broadcaster: broadcaster,
+	}
+}
+
+func (s *activityService) Record(ctx context.Context, entry ActivityEntry) {
+	before, err := json.Marshal(entry.Before)
+	if err != nil {
+		log.Printf("Activity: could not encode %s before-state: %v", entry.Action, err)
+		return
+	}
+	after, err := json.Marshal(entry.After)
+	if err != nil {
+		log.Printf("Activity: could not encode %s after-state: %v", entry.Action, err)
+		return
+	}
+
+	actorID := entry.ActorID
+	activity := &models.Activity{
+		BoardID:    entry.BoardID,
+		CardID:     entry.CardID,
+		ActorID:    &actorID,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     before,
+		After:      after,
+	}
+	if err := s.repo.Create(ctx, activity); err != nil {
+		log.Printf("Activity: could not record %s on board %d: %v", entry.Action, entry.BoardID, err)
+		return
+	}
+
+	broadcastEvent(s.broadcaster, entry.BoardID, "ACTIVITY_CREATED", activity)
+}
+
+func (s *activityService) ListForBoard(ctx context.Context, boardID, userID int, cursor string, limit int) (*models.ActivityPage, error) {
+	beforeID, err := parseActivityCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.access.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.GetPageByBoardID(ctx, boardID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return newActivityPage(entries, limit), nil
+}
+
+func (s *activityService) ListForCard(ctx context.Context, cardID, userID int, cursor string, limit int) (*models.ActivityPage, error) {
+	beforeID, err := parseActivityCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := s.access.card(ctx, cardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.GetPageByCardID(ctx, cardID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return newActivityPage(entries, limit), nil
+}
+
+// Курсор - id последней полученной записи; следующая страница начинается с более старых.
+func parseActivityCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidActivityCursor
+	}
+	return id, nil
+}
+
+// newActivityPage получает на одну запись больше limit: её наличие означает, что есть следующая страница.
+func newActivityPage(entries []models.Activity, limit int) *models.ActivityPage {
+	page := &models.ActivityPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		next := strconv.FormatInt(page.Entries[limit-1].ID, 10)
+		page.NextCursor = &next
+	}
+	return page
+}
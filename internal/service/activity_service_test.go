@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestActivityService_ListForBoard_Paginates(t *testing.T) {
+	// --- ARRANGE ---
+	mockActivityRepo := new(repository.MockActivityRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	activityService := NewActivityService(mockActivityRepo, new(repository.MockCardRepository),
+		new(repository.MockListRepository), NewBoardPermissions(mockBoardRepo), new(MockBroadcaster))
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleObserver, nil)
+	// Запрашивается на одну запись больше лимита, чтобы понять, есть ли следующая страница.
+	mockActivityRepo.On("GetPageByBoardID", mock.Anything, 1, int64(40), 3).
+		Return([]models.Activity{{ID: 39}, {ID: 37}, {ID: 36}}, nil).Once()
+	mockActivityRepo.On("GetPageByBoardID", mock.Anything, 1, int64(37), 3).
+		Return([]models.Activity{{ID: 36}}, nil).Once()
+
+	// --- ACT ---
+	first, err := activityService.ListForBoard(context.Background(), 1, 10, "40", 2)
+	assert.NoError(t, err)
+	second, err := activityService.ListForBoard(context.Background(), 1, 10, *first.NextCursor, 2)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Len(t, first.Entries, 2)
+	assert.Equal(t, "37", *first.NextCursor)
+	assert.Len(t, second.Entries, 1)
+	assert.Nil(t, second.NextCursor)
+	mockActivityRepo.AssertExpectations(t)
+}
+
+func TestActivityService_ListForBoard_InvalidCursor(t *testing.T) {
+	activityService := NewActivityService(new(repository.MockActivityRepository), new(repository.MockCardRepository),
+		new(repository.MockListRepository), NewBoardPermissions(new(repository.MockBoardRepository)), new(MockBroadcaster))
+
+	_, err := activityService.ListForBoard(context.Background(), 1, 10, "abc", 20)
+
+	assert.ErrorIs(t, err, ErrInvalidActivityCursor)
+}
+
+func TestActivityService_Record_BroadcastsEntry(t *testing.T) {
+	// --- ARRANGE ---
+	mockActivityRepo := new(repository.MockActivityRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	activityService := NewActivityService(mockActivityRepo, new(repository.MockCardRepository),
+		new(repository.MockListRepository), NewBoardPermissions(new(repository.MockBoardRepository)), mockBroadcaster)
+
+	mockActivityRepo.On("Create", mock.Anything, mock.MatchedBy(func(a *models.Activity) bool {
+		return a.ActorID != nil && *a.ActorID == 10 && string(a.Before) == "null" && string(a.After) == `{"name":"New"}`
+	})).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	activityService.Record(context.Background(), ActivityEntry{
+		BoardID: 1, ActorID: 10, Action: "board.created", EntityType: "board", EntityID: 1,
+		After: map[string]interface{}{"name": "New"},
+	})
+
+	// --- ASSERT ---
+	mockActivityRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
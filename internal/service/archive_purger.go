@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"log"
+	"notes-project/internal/repository"
+	"notes-project/internal/storage"
+	"time"
+)
+
+// ArchivePurger периодически окончательно удаляет доски, списки и карточки,
+// которые пролежали в архиве дольше retention.
+type ArchivePurger struct {
+	boardRepo repository.BoardRepository
+	listRepo  repository.ListRepository
+	cardRepo  repository.CardRepository
+	store     storage.BlobStore
+	interval  time.Duration
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewArchivePurger(
+	boardRepo repository.BoardRepository,
+	listRepo repository.ListRepository,
+	cardRepo repository.CardRepository,
+	store storage.BlobStore,
+	interval time.Duration,
+	retention time.Duration) *ArchivePurger {
+	return &ArchivePurger{
+		boardRepo: boardRepo,
+		listRepo:  listRepo,
+		cardRepo:  cardRepo,
+		store:     store,
+		interval:  interval,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+func (p *ArchivePurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.PurgeOnce(ctx)
+		}
+	}
+}
+
+// PurgeOnce удаляет сначала карточки, затем списки и доски: так счётчики в логе
+// отражают то, что было архивировано явно, а не удалено каскадом. Файлы вложений
+// удаляются из хранилища после того, как удалены их строки.
+func (p *ArchivePurger) PurgeOnce(ctx context.Context) {
+	cutoff := p.now().Add(-p.retention)
+
+	cards, cardKeys, err := p.cardRepo.PurgeArchived(ctx, cutoff)
+	if err != nil {
+		log.Printf("ArchivePurger: could not purge cards: %v", err)
+	}
+	deleteBlobs(ctx, p.store, cardKeys)
+	lists, listKeys, err := p.listRepo.PurgeArchived(ctx, cutoff)
+	if err != nil {
+		log.Printf("ArchivePurger: could not purge lists: %v", err)
+	}
+	deleteBlobs(ctx, p.store, listKeys)
+	boards, boardKeys, err := p.boardRepo.PurgeArchived(ctx, cutoff)
+	if err != nil {
+		log.Printf("ArchivePurger: could not purge boards: %v", err)
+	}
+	deleteBlobs(ctx, p.store, boardKeys)
+
+	if cards > 0 || lists > 0 || boards > 0 {
+		log.Printf("ArchivePurger: purged %d card(s), %d list(s) and %d board(s) archived before %s",
+			cards, lists, boards, cutoff.Format(time.RFC3339))
+	}
+}
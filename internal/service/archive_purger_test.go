@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"notes-project/internal/repository"
+	"notes-project/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchivePurger_PurgeOnce(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockCardRepo := new(repository.MockCardRepository)
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	for _, key := range []string{"list-file", "board-file"} {
+		require.NoError(t, store.Put(context.Background(), key, strings.NewReader("data"), 4, "text/plain"))
+	}
+	purger := NewArchivePurger(mockBoardRepo, mockListRepo, mockCardRepo, store, time.Hour, 30*24*time.Hour)
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+	cutoff := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Ошибка на одном шаге не должна мешать очистке остальных таблиц.
+	mockCardRepo.On("PurgeArchived", mock.Anything, cutoff).Return(int64(0), []string(nil), errors.New("boom")).Once()
+	mockListRepo.On("PurgeArchived", mock.Anything, cutoff).Return(int64(2), []string{"list-file"}, nil).Once()
+	mockBoardRepo.On("PurgeArchived", mock.Anything, cutoff).Return(int64(1), []string{"board-file", "already-gone"}, nil).Once()
+
+	// --- ACT ---
+	purger.PurgeOnce(context.Background())
+
+	// --- ASSERT ---
+	mockCardRepo.AssertExpectations(t)
+	mockListRepo.AssertExpectations(t)
+	mockBoardRepo.AssertExpectations(t)
+	// Файлы вложений удалённых строк убраны из хранилища.
+	for _, key := range []string{"list-file", "board-file"} {
+		_, err := store.Open(context.Background(), key)
+		assert.ErrorIs(t, err, storage.ErrNotFound, key)
+	}
+}
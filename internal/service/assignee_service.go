@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+)
+
+var ErrAssigneeNotFound = apperr.NotFound("assignee_not_found", "user is not assigned to this card")
+
+type AssigneeService interface {
+	Assign(ctx context.Context, cardID, assigneeID, userID int) ([]models.CardAssignee, error)
+	Unassign(ctx context.Context, cardID, assigneeID, userID int) ([]models.CardAssignee, error)
+	CardsForUser(ctx context.Context, userID int) ([]models.BoardCards, error)
+}
+
+type assigneeService struct {
+	repo            repository.AssigneeRepository
+	boardRepo       repository.BoardRepository
+	access          cardAccess
+	activity        ActivityRecorder
+	broadcaster     Broadcaster
+	invalidateCache CacheInvalidator
+}
+
+func NewAssigneeService(
+	repo repository.AssigneeRepository,
+	boardRepo repository.BoardRepository,
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	permissions BoardPermissions,
+	activity ActivityRecorder,
+	broadcaster Broadcaster,
+	invalidateCache CacheInvalidator) AssigneeService {
+	return &assigneeService{
+		repo:            repo,
+		boardRepo:       boardRepo,
+		access:          cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		activity:        activity,
+		broadcaster:     broadcaster,
+		invalidateCache: invalidateCache,
+	}
+}
+
+func (s *assigneeService) Assign(ctx context.Context, cardID, assigneeID, userID int) ([]models.CardAssignee, error) {
+	_, list, _, err := s.access.card(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	// Назначить можно только участника доски, который сам может менять карточки.
+	role, err := s.boardRepo.GetMemberRole(ctx, list.BoardID, assigneeID)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify assignee: %w", err)
+	}
+	if role == "" {
+		return nil, apperr.NotFound(ErrMemberNotFound.Code, "user %d is not a member of board %d", assigneeID, list.BoardID)
+	}
+	if !role.AtLeast(models.RoleMember) {
+		return nil, apperr.Validation("observer_not_assignable", "observers cannot be assigned to cards")
+	}
+
+	if err := s.repo.Assign(ctx, cardID, assigneeID); err != nil {
+		return nil, err
+	}
+	s.recordAssignee(ctx, list.BoardID, cardID, userID, "card.assigned", assigneeID, nil, map[string]interface{}{"user_id": assigneeID})
+	return s.assigneesChanged(ctx, list.BoardID, cardID)
+}
+
+func (s *assigneeService) Unassign(ctx context.Context, cardID, assigneeID, userID int) ([]models.CardAssignee, error) {
+	_, list, _, err := s.access.card(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Unassign(ctx, cardID, assigneeID); err != nil {
+		return nil, apperr.NoRows(err, ErrAssigneeNotFound)
+	}
+	s.recordAssignee(ctx, list.BoardID, cardID, userID, "card.unassigned", assigneeID, map[string]interface{}{"user_id": assigneeID}, nil)
+	return s.assigneesChanged(ctx, list.BoardID, cardID)
+}
+
+// CardsForUser собирает назначенные пользователю карточки по всем его доскам.
+// Доски без назначенных карточек в ответ не попадают.
+func (s *assigneeService) CardsForUser(ctx context.Context, userID int) ([]models.BoardCards, error) {
+	boards, err := s.boardRepo.GetAllForUser(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	boardIDs := make([]int, len(boards))
+	for i, board := range boards {
+		boardIDs[i] = board.ID
+	}
+
+	cardsByBoardID, err := s.repo.GetCardsForUser(ctx, userID, boardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.BoardCards{}
+	for _, board := range boards {
+		cards, ok := cardsByBoardID[board.ID]
+		if !ok {
+			continue
+		}
+		result = append(result, models.BoardCards{BoardID: board.ID, BoardName: board.Name, Cards: cards})
+	}
+	return result, nil
+}
+
+func (s *assigneeService) assigneesChanged(ctx context.Context, boardID, cardID int) ([]models.CardAssignee, error) {
+	assignees, err := s.repo.GetByCardID(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "CARD_ASSIGNEES_CHANGED", map[string]interface{}{
+		"card_id":   cardID,
+		"assignees": assignees,
+	})
+	return assignees, nil
+}
+
+func (s *assigneeService) recordAssignee(ctx context.Context, boardID, cardID, userID int, action string, assigneeID int, before, after interface{}) {
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, CardID: &cardID, ActorID: userID, Action: action, EntityType: "assignee", EntityID: assigneeID,
+		Before: before, After: after,
+	})
+}
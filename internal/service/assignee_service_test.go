@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAssigneeService(assigneeRepo *repository.MockAssigneeRepository, boardRepo *repository.MockBoardRepository,
+	broadcaster *MockBroadcaster) AssigneeService {
+	cardRepo := new(repository.MockCardRepository)
+	listRepo := new(repository.MockListRepository)
+	cardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil)
+	listRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	return NewAssigneeService(assigneeRepo, boardRepo, cardRepo, listRepo, NewBoardPermissions(boardRepo), newNopActivityRecorder(), broadcaster,
+		func(ctx context.Context, boardID int) {})
+}
+
+func TestAssigneeService_Assign_RequiresBoardMember(t *testing.T) {
+	// --- ARRANGE ---
+	mockAssigneeRepo := new(repository.MockAssigneeRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	assigneeService := newTestAssigneeService(mockAssigneeRepo, mockBoardRepo, mockBroadcaster)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 2).Return(models.BoardRole(""), nil).Once()
+
+	// --- ACT ---
+	_, err := assigneeService.Assign(context.Background(), 10, 2, 1)
+
+	// --- ASSERT ---
+	assert.Error(t, err)
+	mockAssigneeRepo.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
+
+func TestAssigneeService_CardsForUser_GroupsByBoard(t *testing.T) {
+	// --- ARRANGE ---
+	mockAssigneeRepo := new(repository.MockAssigneeRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	assigneeService := newTestAssigneeService(mockAssigneeRepo, mockBoardRepo, new(MockBroadcaster))
+
+	boards := []models.Board{{ID: 1, Name: "Работа"}, {ID: 2, Name: "Дом"}, {ID: 3, Name: "Пустая"}}
+	mockBoardRepo.On("GetAllForUser", mock.Anything, 5, false).Return(boards, nil).Once()
+	mockAssigneeRepo.On("GetCardsForUser", mock.Anything, 5, []int{1, 2, 3}).Return(map[int][]models.Card{
+		1: {{ID: 11}, {ID: 12}},
+		2: {{ID: 21}},
+	}, nil).Once()
+
+	// --- ACT ---
+	result, err := assigneeService.CardsForUser(context.Background(), 5)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 1, result[0].BoardID)
+	assert.Len(t, result[0].Cards, 2)
+	assert.Equal(t, "Дом", result[1].BoardName)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"notes-project/internal/storage"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrAttachmentTooLarge       = apperr.New(apperr.ErrTooLarge, "attachment_too_large", "attachment is too large")
+	ErrAttachmentTypeNotAllowed = apperr.New(apperr.ErrUnsupported, "attachment_type_not_allowed", "attachment type is not allowed")
+	ErrAttachmentNotFound       = apperr.NotFound("attachment_not_found", "attachment not found")
+)
+
+// maxFilenameLength ограничивает длину имени файла, которое мы храним и отдаём в Content-Disposition.
+const maxFilenameLength = 255
+
+// AttachmentLimits задаёт ограничения на загружаемые файлы. AllowedTypes - MIME-типы
+// или их семейства с завершающим "/", например "image/".
+type AttachmentLimits struct {
+	MaxBytes     int64
+	AllowedTypes []string
+}
+
+type AttachmentService interface {
+	List(ctx context.Context, cardID, userID int) ([]models.Attachment, error)
+	Upload(ctx context.Context, cardID, userID int, filename string, size int64, r io.Reader) (*models.Attachment, error)
+	Open(ctx context.Context, attachmentID, userID int) (*models.Attachment, io.ReadSeekCloser, error)
+	Delete(ctx context.Context, attachmentID, userID int) error
+}
+
+type attachmentService struct {
+	repo        repository.AttachmentRepository
+	store       storage.BlobStore
+	access      cardAccess
+	broadcaster Broadcaster
+	limits      AttachmentLimits
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	store storage.BlobStore,
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	permissions BoardPermissions,
+	broadcaster Broadcaster,
+	limits AttachmentLimits) AttachmentService {
+	return &attachmentService{
+		repo:        repo,
+		store:       store,
+		access:      cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		broadcaster: broadcaster,
+		limits:      limits,
+	}
+}
+
+func (s *attachmentService) List(ctx context.Context, cardID, userID int) ([]models.Attachment, error) {
+	if _, _, _, err := s.access.card(ctx, cardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAllByCardID(ctx, cardID)
+}
+
+func (s *attachmentService) Upload(ctx context.Context, cardID, userID int, filename string, size int64, r io.Reader) (*models.Attachment, error) {
+	if size > s.limits.MaxBytes {
+		return nil, apperr.New(apperr.ErrTooLarge, ErrAttachmentTooLarge.Code,
+			fmt.Sprintf("attachment is too large, limit is %d bytes", s.limits.MaxBytes))
+	}
+	_, list, _, err := s.access.card(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	// Тип определяем по содержимому, а не по заголовку клиента, который легко подделать.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not read upload: %w", err)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !s.typeAllowed(contentType) {
+		return nil, apperr.New(apperr.ErrUnsupported, ErrAttachmentTypeNotAllowed.Code,
+			fmt.Sprintf("attachment type %s is not allowed", contentType))
+	}
+
+	key, err := newStorageKey(cardID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, key, io.MultiReader(bytes.NewReader(head), r), size, contentType); err != nil {
+		return nil, fmt.Errorf("could not store attachment: %w", err)
+	}
+
+	attachment := &models.Attachment{
+		CardID:      cardID,
+		UploaderID:  &userID,
+		Filename:    sanitizeFilename(filename),
+		ContentType: contentType,
+		SizeBytes:   size,
+		StorageKey:  key,
+	}
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		if delErr := s.store.Delete(context.Background(), key); delErr != nil {
+			log.Printf("Attachments: could not remove orphaned blob %s: %v", key, delErr)
+		}
+		return nil, err
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "ATTACHMENT_ADDED", attachment)
+	return attachment, nil
+}
+
+// Open проверяет доступ к доске и открывает содержимое вложения; закрыть поток должен вызывающий.
+func (s *attachmentService) Open(ctx context.Context, attachmentID, userID int) (*models.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := s.repo.GetByID(ctx, attachmentID)
+	if err != nil {
+		return nil, nil, apperr.NoRows(err, ErrAttachmentNotFound)
+	}
+	if _, _, _, err := s.access.card(ctx, attachment.CardID, userID, models.RoleObserver); err != nil {
+		return nil, nil, err
+	}
+	content, err := s.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open attachment %d: %w", attachmentID, err)
+	}
+	return attachment, content, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, attachmentID, userID int) error {
+	attachment, err := s.repo.GetByID(ctx, attachmentID)
+	if err != nil {
+		return apperr.NoRows(err, ErrAttachmentNotFound)
+	}
+	_, list, role, err := s.access.card(ctx, attachment.CardID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	isUploader := attachment.UploaderID != nil && *attachment.UploaderID == userID
+	if !isUploader && !role.AtLeast(models.RoleAdmin) {
+		return apperr.Forbidden("not_attachment_uploader", "only the uploader or a board admin can delete an attachment")
+	}
+
+	if err := s.repo.Delete(ctx, attachmentID); err != nil {
+		return err
+	}
+	// Строка уже удалена, поэтому ошибка хранилища оставляет лишь недостижимый объект.
+	if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+		log.Printf("Attachments: could not delete blob %s: %v", attachment.StorageKey, err)
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "ATTACHMENT_DELETED", attachment)
+	return nil
+}
+
+// deleteBlobs удаляет файлы вложений, строки которых уже удалены из базы. Ошибка хранилища
+// оставляет лишь недостижимый объект, поэтому она только логируется.
+func deleteBlobs(ctx context.Context, store storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Attachments: could not delete blob %s: %v", key, err)
+		}
+	}
+}
+
+func (s *attachmentService) typeAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.limits.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mediaType || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+func newStorageKey(cardID int) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("could not generate storage key: %w", err)
+	}
+	return fmt.Sprintf("cards/%d/%s", cardID, hex.EncodeToString(nonce)), nil
+}
+
+// sanitizeFilename оставляет только последний элемент пути и отрезает слишком длинные имена.
+func sanitizeFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	for len(name) > maxFilenameLength || !utf8.ValidString(name) {
+		name = strings.ToValidUTF8(name, "")
+		if len(name) > maxFilenameLength {
+			name = name[:maxFilenameLength]
+		}
+	}
+	return name
+}
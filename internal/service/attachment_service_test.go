@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"io"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"notes-project/internal/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAttachmentService(t *testing.T, attachmentRepo *repository.MockAttachmentRepository,
+	boardRepo *repository.MockBoardRepository, broadcaster *MockBroadcaster) (AttachmentService, storage.BlobStore) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	cardRepo := new(repository.MockCardRepository)
+	listRepo := new(repository.MockListRepository)
+	cardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil)
+	listRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	limits := AttachmentLimits{MaxBytes: 1024, AllowedTypes: []string{"text/plain", "image/"}}
+	return NewAttachmentService(attachmentRepo, store, cardRepo, listRepo, NewBoardPermissions(boardRepo), broadcaster, limits), store
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	// --- ARRANGE ---
+	mockAttachmentRepo := new(repository.MockAttachmentRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	attachmentService, store := newTestAttachmentService(t, mockAttachmentRepo, mockBoardRepo, mockBroadcaster)
+
+	content := "just some notes"
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockAttachmentRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	attachment, err := attachmentService.Upload(context.Background(), 10, 1, "../../notes.txt", int64(len(content)), strings.NewReader(content))
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", attachment.Filename)
+	assert.Equal(t, "text/plain; charset=utf-8", attachment.ContentType)
+
+	stored, err := store.Open(context.Background(), attachment.StorageKey)
+	require.NoError(t, err)
+	defer stored.Close()
+	data, err := io.ReadAll(stored)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestAttachmentService_Upload_RejectsDisallowedType(t *testing.T) {
+	// --- ARRANGE ---
+	mockAttachmentRepo := new(repository.MockAttachmentRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	attachmentService, _ := newTestAttachmentService(t, mockAttachmentRepo, mockBoardRepo, new(MockBroadcaster))
+
+	pdf := "%PDF-1.4 fake document"
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+
+	// --- ACT ---
+	_, err := attachmentService.Upload(context.Background(), 10, 1, "picture.png", int64(len(pdf)), strings.NewReader(pdf))
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrAttachmentTypeNotAllowed)
+	mockAttachmentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestAttachmentService_Upload_RejectsTooLarge(t *testing.T) {
+	attachmentService, _ := newTestAttachmentService(t, new(repository.MockAttachmentRepository),
+		new(repository.MockBoardRepository), new(MockBroadcaster))
+
+	_, err := attachmentService.Upload(context.Background(), 10, 1, "big.txt", 4096, strings.NewReader(""))
+
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/ordering"
+	"notes-project/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidImport = apperr.Validation("invalid_import", "invalid import document")
+
+type BoardExportService interface {
+	Export(ctx context.Context, boardID, userID int, includeArchived bool) (*models.BoardExport, error)
+	Import(ctx context.Context, userID int, document []byte) (*models.BoardImportResult, error)
+}
+
+type boardExportService struct {
+	boardRepo   repository.BoardRepository
+	listRepo    repository.ListRepository
+	cardRepo    repository.CardRepository
+	labelRepo   repository.LabelRepository
+	importRepo  repository.BoardImportRepository
+	permissions BoardPermissions
+	invitations InvitationService
+	activity    ActivityRecorder
+}
+
+func NewBoardExportService(
+	boardRepo repository.BoardRepository,
+	listRepo repository.ListRepository,
+	cardRepo repository.CardRepository,
+	labelRepo repository.LabelRepository,
+	importRepo repository.BoardImportRepository,
+	permissions BoardPermissions,
+	invitations InvitationService,
+	activity ActivityRecorder) BoardExportService {
+	return &boardExportService{
+		boardRepo:   boardRepo,
+		listRepo:    listRepo,
+		cardRepo:    cardRepo,
+		labelRepo:   labelRepo,
+		importRepo:  importRepo,
+		permissions: permissions,
+		invitations: invitations,
+		activity:    activity,
+	}
+}
+
+// Export выгружает доску целиком, включая email участников, поэтому доступен администраторам.
+func (s *boardExportService) Export(ctx context.Context, boardID, userID int, includeArchived bool) (*models.BoardExport, error) {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	lists, err := s.listRepo.GetAllByBoardID(ctx, boardID, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch lists: %w", err)
+	}
+	listIDs := make([]int, len(lists))
+	for i, list := range lists {
+		listIDs[i] = list.ID
+	}
+	cardsByListID, err := s.cardRepo.GetAllByListIDs(ctx, listIDs, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch cards: %w", err)
+	}
+	var cardIDs []int
+	for _, cards := range cardsByListID {
+		for _, card := range cards {
+			cardIDs = append(cardIDs, card.ID)
+		}
+	}
+	labelsByCardID, err := s.labelRepo.GetByCardIDs(ctx, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch card labels: %w", err)
+	}
+	labels, err := s.labelRepo.GetAllByBoardID(ctx, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch labels: %w", err)
+	}
+	members, err := s.boardRepo.GetMembers(ctx, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch members: %w", err)
+	}
+
+	export := &models.BoardExport{
+		Format:     models.BoardExportFormat,
+		Version:    models.BoardExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Name:       board.Name,
+		Labels:     make([]models.ExportedLabel, 0, len(labels)),
+		Lists:      make([]models.ExportedList, 0, len(lists)),
+		Members:    make([]models.ExportedMember, 0, len(members)),
+	}
+	for _, label := range labels {
+		export.Labels = append(export.Labels, models.ExportedLabel{Name: label.Name, Color: label.Color})
+	}
+	for _, list := range lists {
+		exported := models.ExportedList{
+			Title:    list.Title,
+			Position: list.Position,
+			Archived: list.ArchivedAt != nil,
+			Cards:    []models.ExportedCard{},
+		}
+		for _, card := range cardsByListID[list.ID] {
+			var labelNames []string
+			for _, label := range labelsByCardID[card.ID] {
+				labelNames = append(labelNames, label.Name)
+			}
+			exported.Cards = append(exported.Cards, models.ExportedCard{
+				Title:       card.Title,
+				Description: card.Description,
+				Position:    card.Position,
+				StartAt:     card.StartAt,
+				DueAt:       card.DueAt,
+				Completed:   card.Completed,
+				Archived:    card.ArchivedAt != nil,
+				Labels:      labelNames,
+			})
+		}
+		export.Lists = append(export.Lists, exported)
+	}
+	for _, member := range members {
+		export.Members = append(export.Members, models.ExportedMember{Name: member.Name, Email: member.Email, Role: member.Role})
+	}
+	return export, nil
+}
+
+// Import принимает документ Export или JSON-выгрузку доски Trello; формат определяется по содержимому.
+func (s *boardExportService) Import(ctx context.Context, userID int, document []byte) (*models.BoardImportResult, error) {
+	// Ошибки разбора и проверки документа - ошибки клиента, их текст отдаётся ему целиком.
+	data, err := parseBoardDocument(document)
+	if err != nil {
+		return nil, apperr.Validation(ErrInvalidImport.Code, "invalid import document: %v", err)
+	}
+	if err := normalizeBoardExport(data); err != nil {
+		return nil, apperr.Validation(ErrInvalidImport.Code, "invalid import document: %v", err)
+	}
+
+	result, err := s.importRepo.Import(ctx, userID, data)
+	if err != nil {
+		return nil, err
+	}
+	// Участники из документа не добавляются на доску сами: каждый получает обычное приглашение
+	// и решает, принимать ли его.
+	for _, member := range data.Members {
+		if _, err := s.invitations.Invite(ctx, result.Board.ID, userID, member.Email, member.Role); err != nil {
+			log.Printf("could not invite %s to imported board %d: %v", member.Email, result.Board.ID, err)
+			continue
+		}
+		result.Invitations++
+	}
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: result.Board.ID, ActorID: userID, Action: "board.imported", EntityType: "board", EntityID: result.Board.ID,
+		After: map[string]interface{}{
+			"name": result.Board.Name, "lists": result.Lists, "cards": result.Cards, "invitations": result.Invitations,
+		},
+	})
+	return result, nil
+}
+
+func parseBoardDocument(document []byte) (*models.BoardExport, error) {
+	var probe struct {
+		Format  string `json:"format"`
+		Version int    `json:"version"`
+	}
+	if err := json.Unmarshal(document, &probe); err != nil {
+		return nil, fmt.Errorf("import document is not valid JSON")
+	}
+
+	if probe.Format == models.BoardExportFormat {
+		if probe.Version != models.BoardExportVersion {
+			return nil, fmt.Errorf("unsupported export version %d", probe.Version)
+		}
+		var data models.BoardExport
+		if err := json.Unmarshal(document, &data); err != nil {
+			return nil, fmt.Errorf("invalid board export: %w", err)
+		}
+		return &data, nil
+	}
+	return parseTrelloBoard(document)
+}
+
+// normalizeBoardExport проверяет документ и приводит его к виду, который можно записать
+// как есть: позиции заново раскладываются с шагом ordering.Step в исходном порядке,
+// повторяющиеся метки и участники схлопываются.
+func normalizeBoardExport(data *models.BoardExport) error {
+	data.Name = strings.TrimSpace(data.Name)
+	if data.Name == "" {
+		return fmt.Errorf("board name cannot be empty")
+	}
+
+	labels := data.Labels[:0]
+	known := make(map[string]bool, len(data.Labels))
+	for _, label := range data.Labels {
+		label.Color = strings.ToLower(label.Color)
+		if err := validateLabel(label.Name, label.Color); err != nil {
+			return fmt.Errorf("label %q: %w", label.Name, err)
+		}
+		if !known[label.Name] {
+			known[label.Name] = true
+			labels = append(labels, label)
+		}
+	}
+	data.Labels = labels
+
+	sort.SliceStable(data.Lists, func(i, j int) bool { return data.Lists[i].Position < data.Lists[j].Position })
+	for i := range data.Lists {
+		list := &data.Lists[i]
+		if strings.TrimSpace(list.Title) == "" {
+			return fmt.Errorf("list %d has an empty title", i+1)
+		}
+		list.Position = ordering.Step * float64(i+1)
+
+		sort.SliceStable(list.Cards, func(a, b int) bool { return list.Cards[a].Position < list.Cards[b].Position })
+		for j := range list.Cards {
+			card := &list.Cards[j]
+			if strings.TrimSpace(card.Title) == "" {
+				return fmt.Errorf("card %d in list %q has an empty title", j+1, list.Title)
+			}
+			if card.StartAt != nil && card.DueAt != nil && card.StartAt.After(*card.DueAt) {
+				return fmt.Errorf("card %q: start date cannot be after due date", card.Title)
+			}
+			for _, name := range card.Labels {
+				if !known[name] {
+					return fmt.Errorf("card %q references unknown label %q", card.Title, name)
+				}
+			}
+			card.Position = ordering.Step * float64(j+1)
+		}
+	}
+
+	members := data.Members[:0]
+	seen := make(map[string]bool, len(data.Members))
+	for _, member := range data.Members {
+		email := strings.ToLower(strings.TrimSpace(member.Email))
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		// Владелец у импортированной доски один - тот, кто её импортирует.
+		if member.Role == models.RoleOwner {
+			member.Role = models.RoleAdmin
+		}
+		if !member.Role.IsValid() {
+			return fmt.Errorf("member %q has invalid role %q", member.Email, member.Role)
+		}
+		member.Email = email
+		members = append(members, member)
+	}
+	data.Members = members
+	return nil
+}
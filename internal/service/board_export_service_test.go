@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/mailer"
+	"notes-project/internal/models"
+	"notes-project/internal/ordering"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const trelloExport = `{
+	"name": "Roadmap",
+	"labels": [
+		{"id": "l1", "name": "Bug", "color": "red"},
+		{"id": "l2", "name": "", "color": "green_dark"}
+	],
+	"lists": [
+		{"id": "b", "name": "Done", "closed": false, "pos": 65535},
+		{"id": "a", "name": "Todo", "closed": false, "pos": 16384},
+		{"id": "c", "name": "Old", "closed": true, "pos": 131070}
+	],
+	"cards": [
+		{"id": "c2", "name": "Second", "desc": "", "idList": "a", "pos": 32768, "closed": false},
+		{"id": "c1", "name": "First", "desc": "Details", "idList": "a", "pos": 1024, "closed": false,
+		 "idLabels": ["l1", "l2"]},
+		{"id": "c3", "name": "Shipped", "idList": "b", "pos": 5, "closed": true, "dueComplete": true}
+	]
+}`
+
+func newTestBoardExportService(importRepo repository.BoardImportRepository, invitations InvitationService, activity ActivityRecorder) BoardExportService {
+	boardRepo := new(repository.MockBoardRepository)
+	return NewBoardExportService(boardRepo, new(repository.MockListRepository), new(repository.MockCardRepository),
+		new(repository.MockLabelRepository), importRepo, NewBoardPermissions(boardRepo), invitations, activity)
+}
+
+func TestParseBoardDocument_Trello(t *testing.T) {
+	// --- ACT ---
+	data, err := parseBoardDocument([]byte(trelloExport))
+	assert.NoError(t, err)
+	err = normalizeBoardExport(data)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, "Roadmap", data.Name)
+	assert.Equal(t, []models.ExportedLabel{{Name: "Bug", Color: "#eb5a46"}, {Name: "green_dark", Color: "#61bd4f"}}, data.Labels)
+
+	assert.Len(t, data.Lists, 3)
+	assert.Equal(t, "Todo", data.Lists[0].Title)
+	assert.Equal(t, ordering.Step, data.Lists[0].Position)
+	assert.Equal(t, "Done", data.Lists[1].Title)
+	assert.Equal(t, 2*ordering.Step, data.Lists[1].Position)
+	assert.True(t, data.Lists[2].Archived)
+
+	todo := data.Lists[0].Cards
+	assert.Equal(t, "First", todo[0].Title)
+	assert.Equal(t, "Details", todo[0].Description)
+	assert.Equal(t, []string{"Bug", "green_dark"}, todo[0].Labels)
+	assert.Equal(t, ordering.Step, todo[0].Position)
+	assert.Equal(t, "Second", todo[1].Title)
+	assert.Equal(t, 2*ordering.Step, todo[1].Position)
+
+	shipped := data.Lists[1].Cards[0]
+	assert.True(t, shipped.Archived)
+	assert.True(t, shipped.Completed)
+}
+
+func TestParseBoardDocument_TrelloUnknownList(t *testing.T) {
+	_, err := parseBoardDocument([]byte(`{"name": "B", "lists": [], "cards": [{"name": "x", "idList": "nope"}]}`))
+
+	assert.ErrorContains(t, err, "unknown list")
+}
+
+func TestParseBoardDocument_Rejects(t *testing.T) {
+	_, err := parseBoardDocument([]byte(`{"format": "notes-board", "version": 99, "name": "B"}`))
+	assert.ErrorContains(t, err, "unsupported export version")
+
+	_, err = parseBoardDocument([]byte(`{"title": "not a board"}`))
+	assert.ErrorContains(t, err, "unrecognised import format")
+
+	_, err = parseBoardDocument([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestNormalizeBoardExport_Validates(t *testing.T) {
+	unknownLabel := &models.BoardExport{Name: "B", Lists: []models.ExportedList{
+		{Title: "L", Cards: []models.ExportedCard{{Title: "C", Labels: []string{"missing"}}}},
+	}}
+	assert.ErrorContains(t, normalizeBoardExport(unknownLabel), "unknown label")
+
+	emptyList := &models.BoardExport{Name: "B", Lists: []models.ExportedList{{Title: "  "}}}
+	assert.ErrorContains(t, normalizeBoardExport(emptyList), "empty title")
+
+	badRole := &models.BoardExport{Name: "B", Members: []models.ExportedMember{{Email: "a@b.c", Role: "boss"}}}
+	assert.ErrorContains(t, normalizeBoardExport(badRole), "invalid role")
+}
+
+func TestBoardExportService_Import(t *testing.T) {
+	// --- ARRANGE ---
+	mockImportRepo := new(repository.MockBoardImportRepository)
+	mockInvitationRepo := new(repository.MockInvitationRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockActivity := new(MockActivityRecorder)
+	appMailer := mailer.NewMemoryMailer()
+	invitationService := NewInvitationService(mockInvitationRepo, mockBoardRepo, nil, NewBoardPermissions(mockBoardRepo),
+		appMailer, mockActivity, nil, func(ctx context.Context, boardID int) {}, []byte("test-secret"), "http://app.test")
+	exportService := newTestBoardExportService(mockImportRepo, invitationService, mockActivity)
+
+	document := `{"format": "notes-board", "version": 1, "name": "Copy",
+		"lists": [{"title": "Todo", "position": 3, "cards": []}],
+		"members": [
+			{"name": "Ann", "email": " Ann@Example.com", "role": "owner"},
+			{"name": "Ann", "email": "ann@example.com", "role": "member"}
+		]}`
+	result := &models.BoardImportResult{Board: models.Board{ID: 7, Name: "Copy"}, Lists: 1}
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 7, 10).Return(models.RoleOwner, nil)
+	mockBoardRepo.On("GetByID", mock.Anything, 7).Return(&models.Board{ID: 7, Name: "Copy"}, nil)
+	mockInvitationRepo.On("Create", mock.Anything, mock.MatchedBy(func(i *models.Invitation) bool {
+		return i.BoardID == 7 && i.Email == "ann@example.com" && i.Role == models.RoleAdmin && i.InvitedBy == 10
+	})).Return(nil).Once()
+
+	mockImportRepo.On("Import", mock.Anything, 10, mock.MatchedBy(func(data *models.BoardExport) bool {
+		return len(data.Members) == 1 && data.Members[0].Email == "ann@example.com" &&
+			data.Members[0].Role == models.RoleAdmin && data.Lists[0].Position == ordering.Step
+	})).Return(result, nil).Once()
+	mockActivity.On("Record", mock.Anything, mock.MatchedBy(func(e ActivityEntry) bool {
+		return e.BoardID == 7 && e.ActorID == 10 && e.Action == "board.imported"
+	})).Return().Once()
+
+	// --- ACT ---
+	got, err := exportService.Import(context.Background(), 10, []byte(document))
+
+	// --- ASSERT ---
+	// Участник не попадает на доску без согласия: ему уходит приглашение.
+	assert.NoError(t, err)
+	assert.Equal(t, result, got)
+	assert.Equal(t, 1, got.Invitations)
+	if assert.Len(t, appMailer.Messages(), 1) {
+		assert.Equal(t, "ann@example.com", appMailer.Messages()[0].To)
+	}
+	mockImportRepo.AssertExpectations(t)
+	mockInvitationRepo.AssertExpectations(t)
+	mockActivity.AssertExpectations(t)
+}
+
+func TestBoardExportService_Import_InvalidDocumentSkipsRepository(t *testing.T) {
+	mockImportRepo := new(repository.MockBoardImportRepository)
+	exportService := newTestBoardExportService(mockImportRepo, nil, new(MockActivityRecorder))
+
+	_, err := exportService.Import(context.Background(), 10, []byte(`{"format": "notes-board", "version": 1, "name": ""}`))
+
+	assert.ErrorContains(t, err, "board name cannot be empty")
+	mockImportRepo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,99 +2,149 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"notes-project/internal/apperr"
 	"notes-project/internal/models"
 	"notes-project/internal/repository"
+	"notes-project/internal/validation"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	ErrBoardAlreadyArchived = apperr.Conflict("board_already_archived", "board is already archived")
+	ErrBoardNotArchived     = apperr.Conflict("board_not_archived", "board is not archived")
+	ErrMemberNotFound       = apperr.NotFound("member_not_found", "user is not a member of this board")
+	ErrInvalidBoardRole     = apperr.Validation("invalid_role", "role must be one of admin, member or observer")
+	ErrOwnerOnly            = apperr.Forbidden("owner_only", "only the board owner can do this")
+	ErrOwnerCannotLeave     = apperr.Conflict("owner_cannot_leave", "the board owner cannot leave, transfer the board first")
+	ErrAlreadyMember        = apperr.Conflict("already_member", "user is already a member of this board")
+)
+
 type BoardService interface {
 	Create(ctx context.Context, board *models.Board, ownerID int) error
-	GetByID(ctx context.Context, boardID, userID int) (*models.Board, error)
-	GetAllForUser(ctx context.Context, userID int) ([]models.Board, error)
+	GetByID(ctx context.Context, boardID, userID int, includeArchived bool) (*models.Board, error)
+	GetAllForUser(ctx context.Context, userID int, includeArchived bool) ([]models.Board, error)
 	Update(ctx context.Context, boardID, userID int, name string) error
-	Delete(ctx context.Context, boardID, userID int) error
-	AddMember(ctx context.Context, boardID, inviterID int, inviteeEmail string) error
+	Archive(ctx context.Context, boardID, userID int) error
+	Restore(ctx context.Context, boardID, userID int) error
+	GetRole(ctx context.Context, boardID, userID int) (models.BoardRole, error)
+	GetMembers(ctx context.Context, boardID, userID int) ([]models.BoardMember, error)
+	AddMember(ctx context.Context, boardID, inviterID int, inviteeEmail string, role models.BoardRole) (*models.Invitation, error)
+	UpdateMemberRole(ctx context.Context, boardID, actorID, memberID int, role models.BoardRole) error
+	RemoveMember(ctx context.Context, boardID, actorID, memberID int) error
+	Leave(ctx context.Context, boardID, userID int) error
+	TransferOwnership(ctx context.Context, boardID, ownerID, newOwnerID int) error
 	InvalidateBoardCache(ctx context.Context, boardID int)
 }
 
 type boardService struct {
-	repo        repository.BoardRepository
-	listRepo    repository.ListRepository
-	cardRepo    repository.CardRepository
-	userRepo    repository.UserRepository
-	broadcaster Broadcaster
-	rdb         *redis.Client
+	repo          repository.BoardRepository
+	listRepo      repository.ListRepository
+	cardRepo      repository.CardRepository
+	labelRepo     repository.LabelRepository
+	assigneeRepo  repository.AssigneeRepository
+	checklistRepo repository.ChecklistRepository
+	userRepo      repository.UserRepository
+	permissions   BoardPermissions
+	invitations   InvitationService
+	activity      ActivityRecorder
+	broadcaster   Broadcaster
+	rdb           *redis.Client
 }
 
 func NewBoardService(
 	repo repository.BoardRepository,
 	listRepo repository.ListRepository,
 	cardRepo repository.CardRepository,
+	labelRepo repository.LabelRepository,
+	assigneeRepo repository.AssigneeRepository,
+	checklistRepo repository.ChecklistRepository,
 	userRepo repository.UserRepository,
+	permissions BoardPermissions,
+	invitations InvitationService,
+	activity ActivityRecorder,
 	broadcaster Broadcaster,
 	rdb *redis.Client) BoardService {
 	return &boardService{
-		repo:        repo,
-		listRepo:    listRepo,
-		cardRepo:    cardRepo,
-		userRepo:    userRepo,
-		broadcaster: broadcaster,
-		rdb:         rdb,
+		repo:          repo,
+		listRepo:      listRepo,
+		cardRepo:      cardRepo,
+		labelRepo:     labelRepo,
+		assigneeRepo:  assigneeRepo,
+		checklistRepo: checklistRepo,
+		userRepo:      userRepo,
+		permissions:   permissions,
+		invitations:   invitations,
+		activity:      activity,
+		broadcaster:   broadcaster,
+		rdb:           rdb,
 	}
 }
 
-func (s *boardService) InvalidateBoardCache(ctx context.Context, boardID int) {
-	cacheKey := fmt.Sprintf("board:%d", boardID)
-	if err := s.rdb.Del(ctx, cacheKey).Err(); err != nil {
-		log.Printf("Failed to invalidate cache for board %d: %v", boardID, err)
-	} else {
-		log.Println("Cache invalidated for board:", boardID)
+// NewCacheInvalidator возвращает функцию сброса кэша доски. Она не зависит от boardService,
+// поэтому её можно передать сервисам, которые создаются раньше него.
+func NewCacheInvalidator(rdb *redis.Client) CacheInvalidator {
+	return func(ctx context.Context, boardID int) {
+		cacheKey := fmt.Sprintf("board:%d", boardID)
+		if err := rdb.Del(ctx, cacheKey).Err(); err != nil {
+			log.Printf("Failed to invalidate cache for board %d: %v", boardID, err)
+		} else {
+			log.Println("Cache invalidated for board:", boardID)
+		}
 	}
 }
 
+func (s *boardService) InvalidateBoardCache(ctx context.Context, boardID int) {
+	NewCacheInvalidator(s.rdb)(ctx, boardID)
+}
+
 func (s *boardService) Create(ctx context.Context, board *models.Board, ownerID int) error {
 	board.OwnerID = ownerID
 	if err := s.repo.Create(ctx, board); err != nil {
 		return err
 	}
-	if err := s.repo.AddMember(ctx, board.ID, ownerID); err != nil {
+	if _, err := s.repo.AddMember(ctx, board.ID, ownerID, models.RoleOwner); err != nil {
 		log.Printf("CRITICAL: could not add owner as member to board %d: %v", board.ID, err)
 	}
+	s.record(ctx, board.ID, ownerID, "board.created", "board", board.ID, nil, board)
 	return nil
 }
 
-func (s *boardService) GetByID(ctx context.Context, boardID, userID int) (*models.Board, error) {
+// GetByID собирает доску целиком. В кэше хранится только вид без архивных элементов;
+// запрос с includeArchived всегда идёт в базу.
+func (s *boardService) GetByID(ctx context.Context, boardID, userID int, includeArchived bool) (*models.Board, error) {
 	cacheKey := fmt.Sprintf("board:%d", boardID)
-	val, err := s.rdb.Get(ctx, cacheKey).Result()
-	if err == nil {
-		log.Println("Cache HIT for board:", boardID)
-		var board models.Board
-		if json.Unmarshal([]byte(val), &board) == nil {
-			hasAccess, _ := s.repo.IsMemberOrOwner(ctx, board.ID, userID)
-			if hasAccess {
+	if !includeArchived {
+		val, err := s.rdb.Get(ctx, cacheKey).Result()
+		if err == nil {
+			log.Println("Cache HIT for board:", boardID)
+			var board models.Board
+			if json.Unmarshal([]byte(val), &board) == nil {
+				if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
+					return nil, err
+				}
 				return &board, nil
 			}
-			return nil, fmt.Errorf("access denied")
 		}
+		log.Println("Cache MISS for board:", boardID)
 	}
-	log.Println("Cache MISS for board:", boardID)
-	hasAccess, err := s.repo.IsMemberOrOwner(ctx, boardID, userID)
-	if err != nil {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
 		return nil, err
 	}
-	if !hasAccess {
-		return nil, fmt.Errorf("access denied")
-	}
 	board, err := s.repo.GetByID(ctx, boardID)
 	if err != nil {
-		return nil, err
+		return nil, apperr.NoRows(err, ErrBoardNotFound)
+	}
+	if board.ArchivedAt != nil && !includeArchived {
+		return nil, ErrBoardNotFound
 	}
-	lists, err := s.listRepo.GetAllByBoardID(ctx, boardID)
+	lists, err := s.listRepo.GetAllByBoardID(ctx, boardID, includeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch lists: %w", err)
 	}
@@ -102,18 +152,53 @@ func (s *boardService) GetByID(ctx context.Context, boardID, userID int) (*model
 	for i, list := range lists {
 		listIDs[i] = list.ID
 	}
-	cardsByListID, err := s.cardRepo.GetAllByListIDs(ctx, listIDs)
+	cardsByListID, err := s.cardRepo.GetAllByListIDs(ctx, listIDs, includeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("could not fetch cards: %w", err)
 	}
+	var cardIDs []int
+	for _, cards := range cardsByListID {
+		for _, card := range cards {
+			cardIDs = append(cardIDs, card.ID)
+		}
+	}
+	labelsByCardID, err := s.labelRepo.GetByCardIDs(ctx, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch card labels: %w", err)
+	}
+	assigneesByCardID, err := s.assigneeRepo.GetByCardIDs(ctx, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch card assignees: %w", err)
+	}
+	progressByCardID, err := s.checklistRepo.GetProgressByCardIDs(ctx, cardIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch checklist progress: %w", err)
+	}
 	for i := range lists {
 		if cards, ok := cardsByListID[lists[i].ID]; ok {
+			for j := range cards {
+				cards[j].Labels = labelsByCardID[cards[j].ID]
+				cards[j].Assignees = assigneesByCardID[cards[j].ID]
+				if progress, ok := progressByCardID[cards[j].ID]; ok {
+					cards[j].ChecklistProgress = &progress
+				}
+			}
 			lists[i].Cards = cards
 		} else {
 			lists[i].Cards = []models.Card{}
 		}
 	}
 	board.Lists = lists
+	labels, err := s.labelRepo.GetAllByBoardID(ctx, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch labels: %w", err)
+	}
+	board.Labels = labels
+	members, err := s.repo.GetMembers(ctx, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch members: %w", err)
+	}
+	board.Members = members
 	jsonData, err := json.Marshal(board)
 	if err == nil {
 		s.rdb.Set(ctx, cacheKey, jsonData, 10*time.Minute)
@@ -121,43 +206,239 @@ func (s *boardService) GetByID(ctx context.Context, boardID, userID int) (*model
 	return board, nil
 }
 
-func (s *boardService) GetAllForUser(ctx context.Context, userID int) ([]models.Board, error) {
-	return s.repo.GetAllForUser(ctx, userID)
+func (s *boardService) GetAllForUser(ctx context.Context, userID int, includeArchived bool) ([]models.Board, error) {
+	return s.repo.GetAllForUser(ctx, userID, includeArchived)
 }
 
 func (s *boardService) Update(ctx context.Context, boardID, userID int, name string) error {
-	if err := s.repo.Update(ctx, boardID, userID, name); err != nil {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return err
+	}
+	board, err := s.repo.GetByID(ctx, boardID)
+	if err != nil {
+		return apperr.NoRows(err, ErrBoardNotFound)
+	}
+	if board.ArchivedAt != nil {
+		return ErrBoardArchived
+	}
+	if err := s.repo.Update(ctx, boardID, name); err != nil {
+		return apperr.NoRows(err, ErrBoardNotFound)
+	}
+	s.InvalidateBoardCache(ctx, boardID)
+	s.record(ctx, boardID, userID, "board.updated", "board", boardID,
+		map[string]interface{}{"name": board.Name}, map[string]interface{}{"name": name})
+	return nil
+}
+
+// Archive скрывает доску вместо удаления; окончательно её удалит задача очистки архива.
+func (s *boardService) Archive(ctx context.Context, boardID, userID int) error {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleOwner); err != nil {
 		return err
 	}
+	if err := s.repo.Archive(ctx, boardID); err != nil {
+		return apperr.NoRows(err, ErrBoardAlreadyArchived)
+	}
 	s.InvalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "BOARD_ARCHIVED", map[string]interface{}{"board_id": boardID})
+	s.record(ctx, boardID, userID, "board.archived", "board", boardID,
+		map[string]interface{}{"archived": false}, map[string]interface{}{"archived": true})
 	return nil
 }
 
-func (s *boardService) Delete(ctx context.Context, boardID, userID int) error {
+func (s *boardService) Restore(ctx context.Context, boardID, userID int) error {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleOwner); err != nil {
+		return err
+	}
+	if err := s.repo.Restore(ctx, boardID); err != nil {
+		return apperr.NoRows(err, ErrBoardNotArchived)
+	}
 	s.InvalidateBoardCache(ctx, boardID)
-	return s.repo.Delete(ctx, boardID, userID)
+	broadcastEvent(s.broadcaster, boardID, "BOARD_RESTORED", map[string]interface{}{"board_id": boardID})
+	s.record(ctx, boardID, userID, "board.restored", "board", boardID,
+		map[string]interface{}{"archived": true}, map[string]interface{}{"archived": false})
+	return nil
 }
 
-func (s *boardService) AddMember(ctx context.Context, boardID, inviterID int, inviteeEmail string) error {
-	board, err := s.repo.GetByID(ctx, boardID)
+func (s *boardService) GetRole(ctx context.Context, boardID, userID int) (models.BoardRole, error) {
+	return s.permissions.Require(ctx, boardID, userID, models.RoleObserver)
+}
+
+func (s *boardService) GetMembers(ctx context.Context, boardID, userID int) ([]models.BoardMember, error) {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(ctx, boardID)
+}
+
+// checkGrantableRole проверяет, что actorRole может выдать role: владельца выдаёт только
+// передача доски, а роль администратора - только владелец.
+func checkGrantableRole(actorRole, role models.BoardRole) error {
+	if !role.IsValid() || role == models.RoleOwner {
+		return ErrInvalidBoardRole
+	}
+	if role == models.RoleAdmin && actorRole != models.RoleOwner {
+		return apperr.Forbidden(ErrOwnerOnly.Code, "only the board owner can grant the admin role")
+	}
+	return nil
+}
+
+// AddMember добавляет пользователя с подтверждённой почтой сразу, а незарегистрированному или
+// неподтверждённому отправляет приглашение на email: иначе чужой адрес при регистрации давал бы
+// доступ к доске. Во втором случае возвращается созданное приглашение.
+func (s *boardService) AddMember(ctx context.Context, boardID, inviterID int, inviteeEmail string, role models.BoardRole) (*models.Invitation, error) {
+	inviterRole, err := s.permissions.Require(ctx, boardID, inviterID, models.RoleAdmin)
 	if err != nil {
-		return fmt.Errorf("board not found")
+		return nil, err
 	}
-	if board.OwnerID != inviterID {
-		return fmt.Errorf("only the board owner can invite members")
+	if err := checkGrantableRole(inviterRole, role); err != nil {
+		return nil, err
 	}
 
+	inviteeEmail = validation.NormalizeEmail(inviteeEmail)
 	invitee, err := s.userRepo.GetByEmail(ctx, inviteeEmail)
 	if err != nil {
-		return fmt.Errorf("user with email %s not found", inviteeEmail)
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.invitations.Invite(ctx, boardID, inviterID, inviteeEmail, role)
+		}
+		return nil, err
+	}
+	if !invitee.EmailVerified() {
+		return s.invitations.Invite(ctx, boardID, inviterID, inviteeEmail, role)
+	}
+
+	added, err := s.repo.AddMember(ctx, boardID, invitee.ID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrAlreadyMember
+	}
+
+	member := models.BoardMember{UserID: invitee.ID, Name: invitee.Name, Email: invitee.Email, Role: role}
+	s.InvalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "MEMBER_ADDED", member)
+	s.record(ctx, boardID, inviterID, "member.added", "member", invitee.ID, nil, member)
+
+	return nil, nil
+}
+
+func (s *boardService) UpdateMemberRole(ctx context.Context, boardID, actorID, memberID int, role models.BoardRole) error {
+	actorRole, err := s.permissions.Require(ctx, boardID, actorID, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if err := checkGrantableRole(actorRole, role); err != nil {
+		return err
+	}
+
+	currentRole, err := s.repo.GetMemberRole(ctx, boardID, memberID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case currentRole == "":
+		return apperr.NotFound(ErrMemberNotFound.Code, "user %d is not a member of board %d", memberID, boardID)
+	case currentRole == models.RoleOwner:
+		return apperr.Conflict("owner_role_locked", "the owner's role cannot be changed, transfer the board instead")
+	case currentRole == models.RoleAdmin && actorRole != models.RoleOwner:
+		return apperr.Forbidden(ErrOwnerOnly.Code, "only the board owner can change an admin's role")
+	}
+
+	if err := s.repo.UpdateMemberRole(ctx, boardID, memberID, role); err != nil {
+		return apperr.NoRows(err, ErrMemberNotFound)
+	}
+
+	s.InvalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "MEMBER_ROLE_CHANGED", map[string]interface{}{"user_id": memberID, "role": role})
+	// Права WebSocket-соединения фиксируются при подключении, поэтому после смены роли
+	// соединения участника закрываются и клиент переподключается уже с новой ролью.
+	s.broadcaster.DisconnectUser(boardID, memberID)
+	s.record(ctx, boardID, actorID, "member.role_changed", "member", memberID,
+		map[string]interface{}{"role": currentRole}, map[string]interface{}{"role": role})
+	return nil
+}
+
+func (s *boardService) RemoveMember(ctx context.Context, boardID, actorID, memberID int) error {
+	actorRole, err := s.permissions.Require(ctx, boardID, actorID, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if actorID == memberID {
+		return apperr.Validation("cannot_remove_self", "use leave to remove yourself from the board")
 	}
 
-	err = s.repo.AddMember(ctx, boardID, invitee.ID)
+	memberRole, err := s.repo.GetMemberRole(ctx, boardID, memberID)
 	if err != nil {
 		return err
 	}
+	switch {
+	case memberRole == "":
+		return apperr.NotFound(ErrMemberNotFound.Code, "user %d is not a member of board %d", memberID, boardID)
+	case memberRole == models.RoleOwner:
+		return apperr.Conflict("owner_cannot_be_removed", "the board owner cannot be removed")
+	case memberRole == models.RoleAdmin && actorRole != models.RoleOwner:
+		return apperr.Forbidden(ErrOwnerOnly.Code, "only the board owner can remove an admin")
+	}
+
+	if err := s.repo.RemoveMember(ctx, boardID, memberID); err != nil {
+		return apperr.NoRows(err, ErrMemberNotFound)
+	}
 
 	s.InvalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "MEMBER_REMOVED", map[string]interface{}{"user_id": memberID})
+	s.record(ctx, boardID, actorID, "member.removed", "member", memberID, map[string]interface{}{"role": memberRole}, nil)
+	s.broadcaster.DisconnectUser(boardID, memberID)
+	return nil
+}
 
+func (s *boardService) Leave(ctx context.Context, boardID, userID int) error {
+	role, err := s.permissions.Require(ctx, boardID, userID, models.RoleObserver)
+	if err != nil {
+		return err
+	}
+	if role == models.RoleOwner {
+		return ErrOwnerCannotLeave
+	}
+
+	if err := s.repo.RemoveMember(ctx, boardID, userID); err != nil {
+		return apperr.NoRows(err, ErrMemberNotFound)
+	}
+
+	s.InvalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "MEMBER_LEFT", map[string]interface{}{"user_id": userID})
+	s.record(ctx, boardID, userID, "member.left", "member", userID, map[string]interface{}{"role": role}, nil)
+	s.broadcaster.DisconnectUser(boardID, userID)
+	return nil
+}
+
+func (s *boardService) TransferOwnership(ctx context.Context, boardID, ownerID, newOwnerID int) error {
+	if _, err := s.permissions.Require(ctx, boardID, ownerID, models.RoleOwner); err != nil {
+		return err
+	}
+	if ownerID == newOwnerID {
+		return apperr.Conflict("already_owner", "user is already the board owner")
+	}
+
+	// Владелец уже проверен выше, поэтому отсутствие строки означает, что новый владелец не участник доски.
+	if err := s.repo.TransferOwnership(ctx, boardID, ownerID, newOwnerID); err != nil {
+		return apperr.NoRows(err, ErrMemberNotFound)
+	}
+
+	s.InvalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "BOARD_TRANSFERRED", map[string]interface{}{
+		"previous_owner_id": ownerID,
+		"owner_id":          newOwnerID,
+	})
+	// Новый владелец мог быть наблюдателем с соединением только для чтения.
+	s.broadcaster.DisconnectUser(boardID, newOwnerID)
+	s.record(ctx, boardID, ownerID, "board.transferred", "board", boardID,
+		map[string]interface{}{"owner_id": ownerID}, map[string]interface{}{"owner_id": newOwnerID})
 	return nil
 }
+
+func (s *boardService) record(ctx context.Context, boardID, actorID int, action, entityType string, entityID int, before, after interface{}) {
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, ActorID: actorID, Action: action, EntityType: entityType, EntityID: entityID,
+		Before: before, After: after,
+	})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/mailer"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestBoardService собирает boardService с моками; Redis недоступен, поэтому кэш просто не работает.
+func newTestBoardService(boardRepo *repository.MockBoardRepository, broadcaster *MockBroadcaster) BoardService {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	return NewBoardService(boardRepo, new(repository.MockListRepository), new(repository.MockCardRepository),
+		new(repository.MockLabelRepository), new(repository.MockAssigneeRepository), new(repository.MockChecklistRepository),
+		new(repository.MockUserRepository), NewBoardPermissions(boardRepo), nil, newNopActivityRecorder(), broadcaster, rdb)
+}
+
+func TestBoardService_RemoveMember_DisconnectsUser(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	boardService := newTestBoardService(mockBoardRepo, mockBroadcaster)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 20).Return(models.RoleMember, nil).Once()
+	mockBoardRepo.On("RemoveMember", mock.Anything, 1, 20).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1, mock.Anything).Return().Once()
+	mockBroadcaster.On("DisconnectUser", 1, 20).Return().Once()
+
+	// --- ACT ---
+	err := boardService.RemoveMember(context.Background(), 1, 10, 20)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockBoardRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestBoardService_UpdateMemberRole_DisconnectsUser(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	boardService := newTestBoardService(mockBoardRepo, mockBroadcaster)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 20).Return(models.RoleMember, nil).Once()
+	mockBoardRepo.On("UpdateMemberRole", mock.Anything, 1, 20, models.RoleObserver).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1, mock.Anything).Return().Once()
+	// Открытое соединение было с правом записи; после понижения до наблюдателя его нужно закрыть.
+	mockBroadcaster.On("DisconnectUser", 1, 20).Return().Once()
+
+	// --- ACT ---
+	err := boardService.UpdateMemberRole(context.Background(), 1, 10, 20, models.RoleObserver)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockBoardRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestBoardService_RemoveMember_AdminCannotRemoveAdmin(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	boardService := newTestBoardService(mockBoardRepo, mockBroadcaster)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 20).Return(models.RoleAdmin, nil).Once()
+
+	// --- ACT ---
+	err := boardService.RemoveMember(context.Background(), 1, 10, 20)
+
+	// --- ASSERT ---
+	assert.Error(t, err)
+	mockBoardRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "DisconnectUser", mock.Anything, mock.Anything)
+}
+
+func TestBoardService_Leave_OwnerMustTransferFirst(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	boardService := newTestBoardService(mockBoardRepo, mockBroadcaster)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleOwner, nil).Once()
+
+	// --- ACT ---
+	err := boardService.Leave(context.Background(), 1, 10)
+
+	// --- ASSERT ---
+	assert.Error(t, err)
+	mockBoardRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBoardService_AddMember_AlreadyMember(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockUserRepo := new(repository.MockUserRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	mockActivity := new(MockActivityRecorder)
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	boardService := NewBoardService(mockBoardRepo, new(repository.MockListRepository), new(repository.MockCardRepository),
+		new(repository.MockLabelRepository), new(repository.MockAssigneeRepository), new(repository.MockChecklistRepository),
+		mockUserRepo, NewBoardPermissions(mockBoardRepo), nil, mockActivity, mockBroadcaster, rdb)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil).Once()
+	verifiedAt := time.Now()
+	mockUserRepo.On("GetByEmail", mock.Anything, "bob@example.com").
+		Return(&models.User{ID: 20, Email: "bob@example.com", EmailVerifiedAt: &verifiedAt}, nil).Once()
+	mockBoardRepo.On("AddMember", mock.Anything, 1, 20, models.RoleMember).Return(false, nil).Once()
+
+	// --- ACT ---
+	_, err := boardService.AddMember(context.Background(), 1, 10, " Bob@Example.com", models.RoleMember)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrAlreadyMember)
+	mockBoardRepo.AssertExpectations(t)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+	mockActivity.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
+
+func TestBoardService_AddMember_UnverifiedAccountGetsInvitation(t *testing.T) {
+	// --- ARRANGE ---
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockUserRepo := new(repository.MockUserRepository)
+	mockInvitationRepo := new(repository.MockInvitationRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	appMailer := mailer.NewMemoryMailer()
+	permissions := NewBoardPermissions(mockBoardRepo)
+	invitationService := NewInvitationService(mockInvitationRepo, mockBoardRepo, mockUserRepo, permissions, appMailer,
+		newNopActivityRecorder(), mockBroadcaster, func(ctx context.Context, boardID int) {}, []byte("test-secret"), "http://app.test")
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	boardService := NewBoardService(mockBoardRepo, new(repository.MockListRepository), new(repository.MockCardRepository),
+		new(repository.MockLabelRepository), new(repository.MockAssigneeRepository), new(repository.MockChecklistRepository),
+		mockUserRepo, permissions, invitationService, newNopActivityRecorder(), mockBroadcaster, rdb)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil)
+	mockBoardRepo.On("GetByID", mock.Anything, 1).Return(&models.Board{ID: 1, Name: "Roadmap"}, nil).Once()
+	// Аккаунт зарегистрирован, но почта не подтверждена: владелец адреса мог быть и не он.
+	mockUserRepo.On("GetByEmail", mock.Anything, "bob@example.com").Return(&models.User{ID: 20, Email: "bob@example.com"}, nil).Once()
+	mockInvitationRepo.On("Create", mock.Anything, mock.MatchedBy(func(i *models.Invitation) bool {
+		return i.BoardID == 1 && i.Email == "bob@example.com" && i.Role == models.RoleMember
+	})).Return(nil).Once()
+
+	// --- ACT ---
+	invitation, err := boardService.AddMember(context.Background(), 1, 10, "bob@example.com", models.RoleMember)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.NotNil(t, invitation)
+	assert.Len(t, appMailer.Messages(), 1)
+	mockInvitationRepo.AssertExpectations(t)
+	mockBoardRepo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+)
+
+var (
+	ErrBoardNotFound = apperr.NotFound("board_not_found", "board not found")
+	ErrListNotFound  = apperr.NotFound("list_not_found", "list not found")
+	ErrCardNotFound  = apperr.NotFound("card_not_found", "card not found")
+	ErrBoardArchived = apperr.Conflict("board_archived", "board is archived")
+	ErrListArchived  = apperr.Conflict("list_archived", "list is archived")
+	ErrCardArchived  = apperr.Conflict("card_archived", "card is archived")
+)
+
+// cardAccess проходит по цепочке карточка → список → доска и проверяет роль пользователя.
+// Её используют все сервисы, работающие с содержимым карточек. Роль выше наблюдателя означает
+// изменение, поэтому для неё карточка, список и доска дополнительно не должны быть в архиве.
+type cardAccess struct {
+	cardRepo    repository.CardRepository
+	listRepo    repository.ListRepository
+	permissions BoardPermissions
+}
+
+// list загружает список и проверяет, что роль пользователя на его доске не ниже minRole.
+func (a cardAccess) list(ctx context.Context, listID, userID int, minRole models.BoardRole) (*models.List, models.BoardRole, error) {
+	list, err := a.listRepo.GetByID(ctx, listID)
+	if err != nil {
+		return nil, "", apperr.NoRows(err, ErrListNotFound)
+	}
+	role, err := a.permissions.Require(ctx, list.BoardID, userID, minRole)
+	if err != nil {
+		return nil, "", err
+	}
+	if minRole != models.RoleObserver {
+		if err := checkListWritable(list); err != nil {
+			return nil, "", err
+		}
+	}
+	return list, role, nil
+}
+
+// checkListWritable запрещает менять содержимое архивного списка или списка архивной доски.
+func checkListWritable(list *models.List) error {
+	if list.BoardArchivedAt != nil {
+		return ErrBoardArchived
+	}
+	if list.ArchivedAt != nil {
+		return ErrListArchived
+	}
+	return nil
+}
+
+// card загружает карточку с её списком и проверяет, что роль пользователя не ниже minRole.
+func (a cardAccess) card(ctx context.Context, cardID, userID int, minRole models.BoardRole) (*models.Card, *models.List, models.BoardRole, error) {
+	card, list, role, err := a.cardInAnyState(ctx, cardID, userID, minRole)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if minRole != models.RoleObserver && card.ArchivedAt != nil {
+		return nil, nil, "", ErrCardArchived
+	}
+	return card, list, role, nil
+}
+
+// cardInAnyState - то же, что card, но пропускает архивную карточку. Нужна только
+// архивации и восстановлению, которые сами проверяют состояние карточки.
+func (a cardAccess) cardInAnyState(ctx context.Context, cardID, userID int, minRole models.BoardRole) (*models.Card, *models.List, models.BoardRole, error) {
+	card, err := a.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return nil, nil, "", apperr.NoRows(err, ErrCardNotFound)
+	}
+	list, role, err := a.list(ctx, card.ListID, userID, minRole)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return card, list, role, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCardExportQuery - неверные колонки или фильтры выгрузки; ошибка клиента, а не прав доступа.
+var ErrInvalidCardExportQuery = apperr.Validation("invalid_export_query", "invalid export query")
+
+type cardExportColumn struct {
+	name  string
+	value func(*models.CardExportRow) string
+}
+
+// cardExportColumns - доступные колонки CSV в порядке по умолчанию.
+var cardExportColumns = []cardExportColumn{
+	{"id", func(r *models.CardExportRow) string { return strconv.Itoa(r.ID) }},
+	{"title", func(r *models.CardExportRow) string { return csvText(r.Title) }},
+	{"description", func(r *models.CardExportRow) string { return csvText(r.Description) }},
+	{"list_id", func(r *models.CardExportRow) string { return strconv.Itoa(r.ListID) }},
+	{"list", func(r *models.CardExportRow) string { return csvText(r.ListTitle) }},
+	{"position", func(r *models.CardExportRow) string { return strconv.FormatFloat(r.Position, 'f', -1, 64) }},
+	{"created_at", func(r *models.CardExportRow) string { return csvTime(&r.CreatedAt) }},
+	{"updated_at", func(r *models.CardExportRow) string { return csvTime(&r.UpdatedAt) }},
+	{"start_at", func(r *models.CardExportRow) string { return csvTime(r.StartAt) }},
+	{"due_at", func(r *models.CardExportRow) string { return csvTime(r.DueAt) }},
+	{"completed", func(r *models.CardExportRow) string { return strconv.FormatBool(r.Completed) }},
+	{"archived_at", func(r *models.CardExportRow) string { return csvTime(r.ArchivedAt) }},
+	{"labels", func(r *models.CardExportRow) string { return csvText(r.Labels) }},
+	{"assignees", func(r *models.CardExportRow) string { return csvText(r.Assignees) }},
+}
+
+type CardExportService interface {
+	ExportCSV(ctx context.Context, boardID, userID int, columns []string, filter models.CardExportFilter, w io.Writer) error
+}
+
+type cardExportService struct {
+	repo        repository.CardExportRepository
+	permissions BoardPermissions
+}
+
+func NewCardExportService(repo repository.CardExportRepository, permissions BoardPermissions) CardExportService {
+	return &cardExportService{repo: repo, permissions: permissions}
+}
+
+// ExportCSV пишет карточки доски в w по мере чтения из базы. Пустой columns означает все колонки.
+// До первой записи в w проверяются запрос и права доступа, так что при ошибке в w ничего не попадает.
+func (s *cardExportService) ExportCSV(ctx context.Context, boardID, userID int, columns []string, filter models.CardExportFilter, w io.Writer) error {
+	selected, err := selectCardExportColumns(columns)
+	if err != nil {
+		return err
+	}
+	if filter.DueFrom != nil && filter.DueTo != nil && filter.DueFrom.After(*filter.DueTo) {
+		return apperr.Validation(ErrInvalidCardExportQuery.Code, "due_from cannot be after due_to")
+	}
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	header := make([]string, len(selected))
+	for i, column := range selected {
+		header[i] = column.name
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("could not write csv header: %w", err)
+	}
+
+	record := make([]string, len(selected))
+	err = s.repo.StreamByBoardID(ctx, boardID, filter, func(row *models.CardExportRow) error {
+		for i, column := range selected {
+			record[i] = column.value(row)
+		}
+		return writer.Write(record)
+	})
+	writer.Flush()
+	if err != nil {
+		return fmt.Errorf("could not export cards: %w", err)
+	}
+	return writer.Error()
+}
+
+func selectCardExportColumns(names []string) ([]cardExportColumn, error) {
+	if len(names) == 0 {
+		return cardExportColumns, nil
+	}
+	selected := make([]cardExportColumn, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			continue
+		}
+		column, ok := findCardExportColumn(name)
+		if !ok {
+			return nil, apperr.Validation(ErrInvalidCardExportQuery.Code, "unknown column %q", name)
+		}
+		seen[name] = true
+		selected = append(selected, column)
+	}
+	return selected, nil
+}
+
+func findCardExportColumn(name string) (cardExportColumn, bool) {
+	for _, column := range cardExportColumns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	return cardExportColumn{}, false
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvText экранирует значения, которые табличные редакторы приняли бы за формулу.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
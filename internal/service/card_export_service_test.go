@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCardExportService_ExportCSV_SelectedColumns(t *testing.T) {
+	// --- ARRANGE ---
+	mockExportRepo := new(repository.MockCardExportRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	exportService := NewCardExportService(mockExportRepo, NewBoardPermissions(mockBoardRepo))
+
+	due := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	completed := true
+	filter := models.CardExportFilter{Completed: &completed}
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleObserver, nil)
+	mockExportRepo.On("StreamByBoardID", mock.Anything, 1, filter).Return([]models.CardExportRow{
+		{ID: 5, Title: "Ship, it", ListTitle: "Done", DueAt: &due, Labels: "bug; ui"},
+		{ID: 6, Title: "=HYPERLINK(\"x\")", ListTitle: "Done"},
+	}, nil).Once()
+
+	var out bytes.Buffer
+
+	// --- ACT ---
+	err := exportService.ExportCSV(context.Background(), 1, 10, []string{"id", "title", "list", "due_at", "labels", "id"}, filter, &out)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, "id,title,list,due_at,labels\n"+
+		"5,\"Ship, it\",Done,2025-03-01T12:00:00Z,bug; ui\n"+
+		"6,\"'=HYPERLINK(\"\"x\"\")\",Done,,\n", out.String())
+	mockExportRepo.AssertExpectations(t)
+}
+
+func TestCardExportService_ExportCSV_RejectsBeforeWriting(t *testing.T) {
+	mockExportRepo := new(repository.MockCardExportRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	exportService := NewCardExportService(mockExportRepo, NewBoardPermissions(mockBoardRepo))
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 99).Return(models.BoardRole(""), errors.New("not a member"))
+
+	var out bytes.Buffer
+	err := exportService.ExportCSV(context.Background(), 1, 10, []string{"id", "secret"}, models.CardExportFilter{}, &out)
+	assert.ErrorIs(t, err, ErrInvalidCardExportQuery)
+
+	err = exportService.ExportCSV(context.Background(), 1, 99, nil, models.CardExportFilter{}, &out)
+	assert.Error(t, err)
+
+	assert.Zero(t, out.Len())
+	mockExportRepo.AssertNotCalled(t, "StreamByBoardID", mock.Anything, mock.Anything, mock.Anything)
+}
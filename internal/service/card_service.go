@@ -2,21 +2,37 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"notes-project/internal/apperr"
 	"notes-project/internal/models"
+	"notes-project/internal/ordering"
 	"notes-project/internal/repository"
+	"time"
+)
+
+var (
+	ErrCardAlreadyArchived = apperr.Conflict("card_already_archived", "card is already archived")
+	ErrCardNotArchived     = apperr.Conflict("card_not_archived", "card is not archived")
+	ErrInvalidCardDates    = apperr.Validation("invalid_dates", "start date cannot be after due date")
 )
 
 type CardService interface {
 	Create(ctx context.Context, card *models.Card, listID, userID int) error
-	Move(ctx context.Context, cardID, newListID int, newPosition float64, userID int) error
+	GetByID(ctx context.Context, cardID, userID int, includeArchived bool) (*models.Card, error)
+	Update(ctx context.Context, cardID, userID int, title, description *string) (*models.Card, error)
+	Archive(ctx context.Context, cardID, userID int) error
+	Restore(ctx context.Context, cardID, userID int) (*models.Card, error)
+	Move(ctx context.Context, cardID, newListID int, placement Placement, userID int) error
+	SetDates(ctx context.Context, cardID, userID int, startAt, dueAt *time.Time) (*models.Card, error)
+	SetCompleted(ctx context.Context, cardID, userID int, completed bool) (*models.Card, error)
+	GetDue(ctx context.Context, boardID, userID int, window time.Duration) (*models.DueCards, error)
 }
 
 type cardService struct {
 	cardRepo             repository.CardRepository
 	listRepo             repository.ListRepository
-	boardRepo            repository.BoardRepository
+	access               cardAccess
+	activity             ActivityRecorder
 	broadcaster          Broadcaster
 	invalidateBoardCache CacheInvalidator
 }
@@ -24,48 +40,295 @@ type cardService struct {
 func NewCardService(
 	cardRepo repository.CardRepository,
 	listRepo repository.ListRepository,
-	boardRepo repository.BoardRepository,
+	permissions BoardPermissions,
+	activity ActivityRecorder,
 	broadcaster Broadcaster,
 	cacheInvalidator CacheInvalidator) CardService {
 	return &cardService{
 		cardRepo:             cardRepo,
 		listRepo:             listRepo,
-		boardRepo:            boardRepo,
+		access:               cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		activity:             activity,
 		broadcaster:          broadcaster,
 		invalidateBoardCache: cacheInvalidator}
 }
 
+func (s *cardService) getListForUser(ctx context.Context, listID, userID int, minRole models.BoardRole) (*models.List, error) {
+	list, _, err := s.access.list(ctx, listID, userID, minRole)
+	return list, err
+}
+
+func (s *cardService) getCardForUser(ctx context.Context, cardID, userID int, minRole models.BoardRole) (*models.Card, *models.List, error) {
+	card, list, _, err := s.access.card(ctx, cardID, userID, minRole)
+	return card, list, err
+}
+
 func (s *cardService) Create(ctx context.Context, card *models.Card, listID, userID int) error {
-	list, err := s.listRepo.GetByID(ctx, listID)
+	list, err := s.getListForUser(ctx, listID, userID, models.RoleMember)
 	if err != nil {
-		return fmt.Errorf("list with id %d not found", listID)
+		return err
 	}
 
+	maxPos, err := s.cardRepo.GetMaxPositionForList(ctx, listID)
+	if err != nil {
+		return fmt.Errorf("could not determine card position: %w", err)
+	}
+	card.Position = ordering.After(maxPos)
+	card.ListID = listID
+
 	if err := s.cardRepo.Create(ctx, card); err != nil {
 		return err
 	}
 
 	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_CREATED", card)
+	s.recordCard(ctx, list.BoardID, userID, "card.created", card.ID, nil, card)
+	return nil
+}
+
+func (s *cardService) GetByID(ctx context.Context, cardID, userID int, includeArchived bool) (*models.Card, error) {
+	card, _, err := s.getCardForUser(ctx, cardID, userID, models.RoleObserver)
+	if err != nil {
+		return nil, err
+	}
+	if card.ArchivedAt != nil && !includeArchived {
+		return nil, ErrCardNotFound
+	}
+	return card, nil
+}
+
+func (s *cardService) Update(ctx context.Context, cardID, userID int, title, description *string) (*models.Card, error) {
+	card, list, err := s.getCardForUser(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *card
+	if title != nil {
+		card.Title = *title
+	}
+	if description != nil {
+		card.Description = *description
+	}
+	if err := s.cardRepo.Update(ctx, card); err != nil {
+		return nil, apperr.NoRows(err, ErrCardNotFound)
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_UPDATED", card)
+	s.recordCard(ctx, list.BoardID, userID, "card.updated", card.ID, before, card)
+	return card, nil
+}
+
+// SetDates задаёт оба срока карточки; nil очищает соответствующую дату.
+func (s *cardService) SetDates(ctx context.Context, cardID, userID int, startAt, dueAt *time.Time) (*models.Card, error) {
+	if startAt != nil && dueAt != nil && startAt.After(*dueAt) {
+		return nil, ErrInvalidCardDates
+	}
+	card, list, err := s.getCardForUser(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{"start_at": card.StartAt, "due_at": card.DueAt}
+	card.StartAt = startAt
+	card.DueAt = dueAt
+	if err := s.cardRepo.UpdateDates(ctx, card); err != nil {
+		return nil, err
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_UPDATED", card)
+	s.recordCard(ctx, list.BoardID, userID, "card.dates_changed", card.ID, before,
+		map[string]interface{}{"start_at": card.StartAt, "due_at": card.DueAt})
+	return card, nil
+}
+
+func (s *cardService) SetCompleted(ctx context.Context, cardID, userID int, completed bool) (*models.Card, error) {
+	card, list, err := s.getCardForUser(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{"completed": card.Completed}
+	card.Completed = completed
+	if err := s.cardRepo.SetCompleted(ctx, card); err != nil {
+		return nil, err
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_UPDATED", card)
+	s.recordCard(ctx, list.BoardID, userID, "card.completion_changed", card.ID, before,
+		map[string]interface{}{"completed": card.Completed})
+	return card, nil
+}
 
-	wsMessage := models.WebSocketMessage{Event: "CARD_CREATED", Payload: card}
-	jsonMessage, _ := json.Marshal(wsMessage)
-	s.broadcaster.BroadcastToBoard(list.BoardID, jsonMessage)
+// GetDue делит незавершённые карточки доски на просроченные и те, срок которых наступит в ближайшие window.
+func (s *cardService) GetDue(ctx context.Context, boardID, userID int, window time.Duration) (*models.DueCards, error) {
+	if _, err := s.access.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cards, err := s.cardRepo.GetDueForBoard(ctx, boardID, now.Add(window))
+	if err != nil {
+		return nil, err
+	}
+
+	due := &models.DueCards{Overdue: []models.Card{}, DueSoon: []models.Card{}}
+	for _, card := range cards {
+		if card.DueAt.After(now) {
+			due.DueSoon = append(due.DueSoon, card)
+		} else {
+			due.Overdue = append(due.Overdue, card)
+		}
+	}
+	return due, nil
+}
+
+// Archive скрывает карточку вместо удаления; окончательно её удалит задача очистки архива.
+func (s *cardService) Archive(ctx context.Context, cardID, userID int) error {
+	card, list, _, err := s.access.cardInAnyState(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cardRepo.Archive(ctx, cardID); err != nil {
+		return apperr.NoRows(err, ErrCardAlreadyArchived)
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_ARCHIVED", card)
+	s.recordCard(ctx, list.BoardID, userID, "card.archived", card.ID,
+		map[string]interface{}{"archived": false}, map[string]interface{}{"archived": true})
 	return nil
 }
 
-func (s *cardService) Move(ctx context.Context, cardID, newListID int, newPosition float64, userID int) error {
-	card, _ := s.cardRepo.GetByID(ctx, cardID)
-	oldList, _ := s.listRepo.GetByID(ctx, card.ListID)
-	newList, _ := s.listRepo.GetByID(ctx, newListID)
+// Restore возвращает карточку из архива в конец её списка.
+func (s *cardService) Restore(ctx context.Context, cardID, userID int) (*models.Card, error) {
+	card, list, _, err := s.access.cardInAnyState(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPos, err := s.cardRepo.GetMaxPositionForList(ctx, card.ListID)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine card position: %w", err)
+	}
+	position := ordering.After(maxPos)
+	if err := s.cardRepo.Restore(ctx, cardID, position); err != nil {
+		return nil, apperr.NoRows(err, ErrCardNotArchived)
+	}
+	card.ArchivedAt = nil
+	card.Position = position
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_RESTORED", card)
+	s.recordCard(ctx, list.BoardID, userID, "card.restored", card.ID,
+		map[string]interface{}{"archived": true}, map[string]interface{}{"archived": false})
+	return card, nil
+}
+
+func (s *cardService) Move(ctx context.Context, cardID, newListID int, placement Placement, userID int) error {
+	if err := placement.validate(); err != nil {
+		return err
+	}
+	card, oldList, err := s.getCardForUser(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	newList, err := s.getListForUser(ctx, newListID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
 
-	if err := s.cardRepo.Move(ctx, cardID, newListID, newPosition); err != nil {
+	newPosition, err := s.resolvePosition(ctx, cardID, newListID, placement)
+	if err != nil {
 		return err
 	}
 
+	// Метки привязаны к доске, а исполнителем может быть только участник доски, поэтому
+	// при переносе на другую доску лишнее снимается вместе с перемещением.
+	crossBoard := oldList.BoardID != newList.BoardID
+	var assignees []models.CardAssignee
+	if crossBoard {
+		assignees, err = s.cardRepo.MoveToBoard(ctx, cardID, newListID, newPosition)
+	} else {
+		err = s.cardRepo.Move(ctx, cardID, newListID, newPosition)
+	}
+	if err != nil {
+		return apperr.NoRows(err, ErrCardNotFound)
+	}
+	before := map[string]interface{}{"list_id": card.ListID, "position": card.Position}
+	card.ListID = newListID
+	card.Position = newPosition
+
+	after := map[string]interface{}{"list_id": card.ListID, "position": card.Position}
 	s.invalidateBoardCache(ctx, oldList.BoardID)
-	if oldList.BoardID != newList.BoardID {
+	broadcastEvent(s.broadcaster, oldList.BoardID, "CARD_MOVED", card)
+	s.recordCard(ctx, oldList.BoardID, userID, "card.moved", card.ID, before, after)
+	if crossBoard {
 		s.invalidateBoardCache(ctx, newList.BoardID)
+		broadcastEvent(s.broadcaster, newList.BoardID, "CARD_MOVED", card)
+		broadcastEvent(s.broadcaster, newList.BoardID, "CARD_LABELS_CHANGED", map[string]interface{}{
+			"card_id": card.ID,
+			"labels":  []models.Label{},
+		})
+		broadcastEvent(s.broadcaster, newList.BoardID, "CARD_ASSIGNEES_CHANGED", map[string]interface{}{
+			"card_id":   card.ID,
+			"assignees": assignees,
+		})
+		s.recordCard(ctx, newList.BoardID, userID, "card.moved", card.ID, before, after)
 	}
 
 	return nil
 }
+
+// resolvePosition переводит Placement в конкретную позицию внутри списка listID.
+func (s *cardService) resolvePosition(ctx context.Context, cardID, listID int, placement Placement) (float64, error) {
+	switch {
+	case placement.Position != nil:
+		return *placement.Position, nil
+	case placement.BeforeID != nil || placement.AfterID != nil:
+		anchorID, before := 0, placement.BeforeID != nil
+		if before {
+			anchorID = *placement.BeforeID
+		} else {
+			anchorID = *placement.AfterID
+		}
+		if anchorID == cardID {
+			return 0, apperr.Validation("invalid_placement", "card cannot be placed relative to itself")
+		}
+
+		anchorPosition := func(ctx context.Context) (float64, error) {
+			anchor, err := s.cardRepo.GetByID(ctx, anchorID)
+			if err != nil {
+				return 0, apperr.NoRows(err, apperr.NotFound(ErrCardNotFound.Code, "card with id %d not found", anchorID))
+			}
+			if anchor.ListID != listID {
+				return 0, apperr.Validation("invalid_placement", "card %d is not in list %d", anchorID, listID)
+			}
+			return anchor.Position, nil
+		}
+		neighbourPosition := func(ctx context.Context, position float64, before bool) (*float64, error) {
+			return s.cardRepo.GetNeighbourPosition(ctx, listID, position, before, cardID)
+		}
+		rebalance := func(ctx context.Context) error {
+			return s.cardRepo.Rebalance(ctx, listID, ordering.Step)
+		}
+		return placeNextTo(ctx, before, anchorPosition, neighbourPosition, rebalance)
+	default:
+		maxPos, err := s.cardRepo.GetMaxPositionForList(ctx, listID)
+		if err != nil {
+			return 0, fmt.Errorf("could not determine card position: %w", err)
+		}
+		return ordering.After(maxPos), nil
+	}
+}
+
+func (s *cardService) recordCard(ctx context.Context, boardID, userID int, action string, cardID int, before, after interface{}) {
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, CardID: &cardID, ActorID: userID, Action: action, EntityType: "card", EntityID: cardID,
+		Before: before, After: after,
+	})
+}
@@ -3,9 +3,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"notes-project/internal/models"
+	"notes-project/internal/ordering"
 	"notes-project/internal/repository"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -20,16 +23,36 @@ func (m *MockBroadcaster) BroadcastToBoard(boardID int, message []byte) {
 	m.Called(boardID, message)
 }
 
+func (m *MockBroadcaster) DisconnectUser(boardID, userID int) {
+	m.Called(boardID, userID)
+}
+
+type MockActivityRecorder struct {
+	mock.Mock
+}
+
+func (m *MockActivityRecorder) Record(ctx context.Context, entry ActivityEntry) {
+	m.Called(ctx, entry)
+}
+
+// newNopActivityRecorder - для тестов, которым журнал действий не важен.
+func newNopActivityRecorder() *MockActivityRecorder {
+	recorder := new(MockActivityRecorder)
+	recorder.On("Record", mock.Anything, mock.Anything).Maybe()
+	return recorder
+}
+
 func TestCardService_Move(t *testing.T) {
 	// --- ARRANGE (Подготовка) ---
 	mockCardRepo := new(repository.MockCardRepository)
 	mockListRepo := new(repository.MockListRepository)
 	mockBoardRepo := new(repository.MockBoardRepository)
 	mockBroadcaster := new(MockBroadcaster)
+	mockActivity := new(MockActivityRecorder)
 	// Для cacheInvalidator достаточно простой функции-заглушки.
 	mockCacheInvalidator := func(ctx context.Context, boardID int) {}
 
-	cardService := NewCardService(mockCardRepo, mockListRepo, mockBoardRepo, mockBroadcaster, mockCacheInvalidator)
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), mockActivity, mockBroadcaster, mockCacheInvalidator)
 
 	ctx := context.Background()
 	testUserID := 1
@@ -51,16 +74,25 @@ func TestCardService_Move(t *testing.T) {
 	mockListRepo.On("GetByID", mock.Anything, testNewListID).Return(&models.List{ID: testNewListID, BoardID: testNewBoardID}, nil).Once()
 
 	// Остальные ожидания
-	mockBoardRepo.On("IsMemberOrOwner", mock.Anything, testOldBoardID, testUserID).Return(true, nil).Once()
-	mockBoardRepo.On("IsMemberOrOwner", mock.Anything, testNewBoardID, testUserID).Return(true, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, testOldBoardID, testUserID).Return(models.RoleMember, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, testNewBoardID, testUserID).Return(models.RoleMember, nil).Once()
 	mockCardRepo.On("Move", mock.Anything, testCardID, testNewListID, 1.0).Return(nil).Once()
 	mockBroadcaster.On("BroadcastToBoard", mock.Anything, mock.Anything).Return().Once()
+	// В журнал попадает одна запись с автором перемещения и позициями до и после.
+	mockActivity.On("Record", mock.Anything, mock.MatchedBy(func(entry ActivityEntry) bool {
+		return entry.BoardID == testOldBoardID && entry.ActorID == testUserID && entry.Action == "card.moved" &&
+			entry.CardID != nil && *entry.CardID == testCardID &&
+			assert.ObjectsAreEqual(map[string]interface{}{"list_id": testOldListID, "position": 0.0}, entry.Before) &&
+			assert.ObjectsAreEqual(map[string]interface{}{"list_id": testNewListID, "position": 1.0}, entry.After)
+	})).Return().Once()
 
 	// --- ACT (Действие) ---
-	err := cardService.Move(ctx, testCardID, testNewListID, 1.0, testUserID)
+	newPosition := 1.0
+	err := cardService.Move(ctx, testCardID, testNewListID, Placement{Position: &newPosition}, testUserID)
 
 	// --- ASSERT (Проверка) ---
 	assert.NoError(t, err)
+	mockActivity.AssertExpectations(t)
 
 	// Проверяем, что ВСЕ наши ожидания были выполнены.
 	mockCardRepo.AssertExpectations(t)
@@ -68,3 +100,293 @@ func TestCardService_Move(t *testing.T) {
 	mockBoardRepo.AssertExpectations(t)
 	mockBroadcaster.AssertExpectations(t)
 }
+
+func TestCardService_Move_ToAnotherBoardDropsLabelsAndNonMembers(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(),
+		mockBroadcaster, func(ctx context.Context, boardID int) {})
+
+	remaining := []models.CardAssignee{{CardID: 10, UserID: 1, Name: "Ann"}}
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil).Once()
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil).Once()
+	mockListRepo.On("GetByID", mock.Anything, 200).Return(&models.List{ID: 200, BoardID: 2000}, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 2000, 1).Return(models.RoleMember, nil).Once()
+	mockCardRepo.On("MoveToBoard", mock.Anything, 10, 200, 1.0).Return(remaining, nil).Once()
+	var events []models.WebSocketMessage
+	mockBroadcaster.On("BroadcastToBoard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var msg models.WebSocketMessage
+		assert.NoError(t, json.Unmarshal(args.Get(1).([]byte), &msg))
+		if args.Int(0) == 2000 {
+			events = append(events, msg)
+		}
+	}).Return()
+
+	// --- ACT ---
+	position := 1.0
+	err := cardService.Move(context.Background(), 10, 200, Placement{Position: &position}, 1)
+
+	// --- ASSERT ---
+	// Доска-получатель узнаёт, что у карточки не осталось меток и чужих исполнителей.
+	assert.NoError(t, err)
+	mockCardRepo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockCardRepo.AssertExpectations(t)
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, "CARD_MOVED", events[0].Event)
+		assert.Equal(t, "CARD_LABELS_CHANGED", events[1].Event)
+		assert.Equal(t, []interface{}{}, events[1].Payload.(map[string]interface{})["labels"])
+		assert.Equal(t, "CARD_ASSIGNEES_CHANGED", events[2].Event)
+		assert.Len(t, events[2].Payload.(map[string]interface{})["assignees"], 1)
+	}
+}
+
+func TestCardService_Create_AccessDenied(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	mockCacheInvalidator := func(ctx context.Context, boardID int) {}
+
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(), mockBroadcaster, mockCacheInvalidator)
+
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 2).Return(models.BoardRole(""), nil).Once()
+
+	// --- ACT ---
+	err := cardService.Create(context.Background(), &models.Card{Title: "чужая карточка"}, 100, 2)
+
+	// --- ASSERT ---
+	// Карточка не должна создаваться, если пользователь не состоит в доске.
+	assert.Error(t, err)
+	mockCardRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+	mockListRepo.AssertExpectations(t)
+	mockBoardRepo.AssertExpectations(t)
+}
+
+func TestCardService_WritesToArchivedListOrBoard(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(),
+		mockBroadcaster, func(ctx context.Context, boardID int) {})
+
+	archivedAt := time.Now()
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	mockListRepo.On("GetByID", mock.Anything, 101).Return(&models.List{ID: 101, BoardID: 1000, ArchivedAt: &archivedAt}, nil)
+	mockListRepo.On("GetByID", mock.Anything, 200).Return(&models.List{ID: 200, BoardID: 2000, BoardArchivedAt: &archivedAt}, nil)
+	mockBoardRepo.On("GetMemberRole", mock.Anything, mock.Anything, 1).Return(models.RoleMember, nil)
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil)
+
+	// --- ACT ---
+	errCreate := cardService.Create(context.Background(), &models.Card{Title: "new"}, 101, 1)
+	errMoveToList := cardService.Move(context.Background(), 10, 101, Placement{}, 1)
+	errMoveToBoard := cardService.Move(context.Background(), 10, 200, Placement{}, 1)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, errCreate, ErrListArchived)
+	assert.ErrorIs(t, errMoveToList, ErrListArchived)
+	assert.ErrorIs(t, errMoveToBoard, ErrBoardArchived)
+	mockCardRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockCardRepo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockCardRepo.AssertNotCalled(t, "MoveToBoard", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
+
+func TestCardService_WritesToArchivedCard(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(),
+		mockBroadcaster, func(ctx context.Context, boardID int) {})
+
+	archivedAt := time.Now()
+	title := "new title"
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100, ArchivedAt: &archivedAt}, nil)
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	mockListRepo.On("GetByID", mock.Anything, 200).Return(&models.List{ID: 200, BoardID: 2000}, nil)
+	mockBoardRepo.On("GetMemberRole", mock.Anything, mock.Anything, 1).Return(models.RoleMember, nil)
+
+	tests := []struct {
+		name  string
+		write func() error
+	}{
+		{"update", func() error {
+			_, err := cardService.Update(context.Background(), 10, 1, &title, nil)
+			return err
+		}},
+		{"set dates", func() error {
+			_, err := cardService.SetDates(context.Background(), 10, 1, nil, &archivedAt)
+			return err
+		}},
+		{"set completed", func() error {
+			_, err := cardService.SetCompleted(context.Background(), 10, 1, true)
+			return err
+		}},
+		{"move within board", func() error {
+			return cardService.Move(context.Background(), 10, 100, Placement{}, 1)
+		}},
+		{"move to another board", func() error {
+			return cardService.Move(context.Background(), 10, 200, Placement{}, 1)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- ACT & ASSERT ---
+			assert.ErrorIs(t, tt.write(), ErrCardArchived)
+		})
+	}
+	mockCardRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockCardRepo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockCardRepo.AssertNotCalled(t, "MoveToBoard", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
+
+func TestCardService_Archive(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	invalidated := 0
+	mockCacheInvalidator := func(ctx context.Context, boardID int) { invalidated = boardID }
+
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(), mockBroadcaster, mockCacheInvalidator)
+
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil).Once()
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockCardRepo.On("Archive", mock.Anything, 10).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	err := cardService.Archive(context.Background(), 10, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, invalidated)
+	mockCardRepo.AssertExpectations(t)
+	mockListRepo.AssertExpectations(t)
+	mockBoardRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestCardService_Restore_PlacesCardAtEndOfList(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(),
+		mockBroadcaster, func(ctx context.Context, boardID int) {})
+
+	archivedAt := time.Now().Add(-time.Hour)
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100, Position: 1, ArchivedAt: &archivedAt}, nil).Once()
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	// Пока карточка была в архиве, её место могли занять: возвращаем её в конец списка.
+	mockCardRepo.On("GetMaxPositionForList", mock.Anything, 100).Return(5*ordering.Step, nil).Once()
+	mockCardRepo.On("Restore", mock.Anything, 10, ordering.After(5*ordering.Step)).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	card, err := cardService.Restore(context.Background(), 10, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Nil(t, card.ArchivedAt)
+	assert.Equal(t, ordering.After(5*ordering.Step), card.Position)
+	mockCardRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestCardService_GetByID_HidesArchived(t *testing.T) {
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(),
+		new(MockBroadcaster), func(ctx context.Context, boardID int) {})
+
+	archivedAt := time.Now()
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100, ArchivedAt: &archivedAt}, nil)
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleObserver, nil)
+
+	_, err := cardService.GetByID(context.Background(), 10, 1, false)
+	assert.Error(t, err)
+
+	card, err := cardService.GetByID(context.Background(), 10, 1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, card.ID)
+}
+
+func TestCardService_Move_BeforeCardRebalancesDenseList(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	mockCacheInvalidator := func(ctx context.Context, boardID int) {}
+
+	cardService := NewCardService(mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(), mockBroadcaster, mockCacheInvalidator)
+
+	anchorID := 11
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil).Once()
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil).Twice()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Twice()
+
+	// Сначала якорь стоит вплотную к соседу, поэтому список перебалансируется и расчёт повторяется.
+	dense := 1.0
+	mockCardRepo.On("GetByID", mock.Anything, anchorID).Return(&models.Card{ID: anchorID, ListID: 100, Position: 1.0000000001}, nil).Once()
+	mockCardRepo.On("GetNeighbourPosition", mock.Anything, 100, 1.0000000001, true, 10).Return(&dense, nil).Once()
+	mockCardRepo.On("Rebalance", mock.Anything, 100, ordering.Step).Return(nil).Once()
+
+	prev := 1024.0
+	mockCardRepo.On("GetByID", mock.Anything, anchorID).Return(&models.Card{ID: anchorID, ListID: 100, Position: 2048}, nil).Once()
+	mockCardRepo.On("GetNeighbourPosition", mock.Anything, 100, 2048.0, true, 10).Return(&prev, nil).Once()
+
+	mockCardRepo.On("Move", mock.Anything, 10, 100, 1536.0).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	err := cardService.Move(context.Background(), 10, 100, Placement{BeforeID: &anchorID}, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockCardRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestCardService_GetDue_SplitsOverdueAndDueSoon(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	cardService := NewCardService(mockCardRepo, new(repository.MockListRepository), NewBoardPermissions(mockBoardRepo), newNopActivityRecorder(),
+		new(MockBroadcaster), func(ctx context.Context, boardID int) {})
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleObserver, nil).Once()
+	mockCardRepo.On("GetDueForBoard", mock.Anything, 1000, mock.Anything).
+		Return([]models.Card{{ID: 1, DueAt: &past}, {ID: 2, DueAt: &future}}, nil).Once()
+
+	// --- ACT ---
+	due, err := cardService.GetDue(context.Background(), 1000, 1, 24*time.Hour)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Len(t, due.Overdue, 1)
+	assert.Equal(t, 1, due.Overdue[0].ID)
+	assert.Len(t, due.DueSoon, 1)
+	assert.Equal(t, 2, due.DueSoon[0].ID)
+}
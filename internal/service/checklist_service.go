@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/ordering"
+	"notes-project/internal/repository"
+	"strings"
+)
+
+var (
+	ErrChecklistNotFound       = apperr.NotFound("checklist_not_found", "checklist not found")
+	ErrChecklistItemNotFound   = apperr.NotFound("checklist_item_not_found", "checklist item not found")
+	ErrEmptyChecklistTitle     = apperr.Validation("empty_checklist_title", "checklist title cannot be empty")
+	ErrEmptyChecklistItemTitle = apperr.Validation("empty_checklist_item_title", "checklist item title cannot be empty")
+)
+
+type ChecklistService interface {
+	List(ctx context.Context, cardID, userID int) ([]models.Checklist, error)
+	Create(ctx context.Context, cardID, userID int, title string) (*models.Checklist, error)
+	Rename(ctx context.Context, checklistID, userID int, title string) (*models.Checklist, error)
+	Delete(ctx context.Context, checklistID, userID int) error
+	AddItem(ctx context.Context, checklistID, userID int, title string) (*models.ChecklistItem, error)
+	UpdateItem(ctx context.Context, itemID, userID int, title *string, checked *bool) (*models.ChecklistItem, error)
+	MoveItem(ctx context.Context, itemID int, placement Placement, userID int) (*models.ChecklistItem, error)
+	DeleteItem(ctx context.Context, itemID, userID int) error
+}
+
+type checklistService struct {
+	repo                 repository.ChecklistRepository
+	access               cardAccess
+	activity             ActivityRecorder
+	broadcaster          Broadcaster
+	invalidateBoardCache CacheInvalidator
+}
+
+func NewChecklistService(
+	repo repository.ChecklistRepository,
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	permissions BoardPermissions,
+	activity ActivityRecorder,
+	broadcaster Broadcaster,
+	cacheInvalidator CacheInvalidator) ChecklistService {
+	return &checklistService{
+		repo:                 repo,
+		access:               cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		activity:             activity,
+		broadcaster:          broadcaster,
+		invalidateBoardCache: cacheInvalidator,
+	}
+}
+
+func (s *checklistService) List(ctx context.Context, cardID, userID int) ([]models.Checklist, error) {
+	if _, _, _, err := s.access.card(ctx, cardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAllByCardID(ctx, cardID)
+}
+
+func (s *checklistService) Create(ctx context.Context, cardID, userID int, title string) (*models.Checklist, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrEmptyChecklistTitle
+	}
+	_, list, _, err := s.access.card(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPos, err := s.repo.GetMaxPositionForCard(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine checklist position: %w", err)
+	}
+	checklist := &models.Checklist{CardID: cardID, Title: title, Position: ordering.After(maxPos)}
+	if err := s.repo.Create(ctx, checklist); err != nil {
+		return nil, err
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "CHECKLIST_CREATED", checklist)
+	s.record(ctx, list.BoardID, cardID, userID, "checklist.created", "checklist", checklist.ID, nil, checklist)
+	return checklist, nil
+}
+
+func (s *checklistService) Rename(ctx context.Context, checklistID, userID int, title string) (*models.Checklist, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrEmptyChecklistTitle
+	}
+	checklist, list, err := s.checklistForUser(ctx, checklistID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	before := map[string]interface{}{"title": checklist.Title}
+	checklist.Title = title
+	if err := s.repo.UpdateTitle(ctx, checklist); err != nil {
+		return nil, err
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "CHECKLIST_UPDATED", checklist)
+	s.record(ctx, list.BoardID, checklist.CardID, userID, "checklist.renamed", "checklist", checklist.ID,
+		before, map[string]interface{}{"title": checklist.Title})
+	return checklist, nil
+}
+
+func (s *checklistService) Delete(ctx context.Context, checklistID, userID int) error {
+	checklist, list, err := s.checklistForUser(ctx, checklistID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, checklistID); err != nil {
+		return err
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	s.broadcastWithProgress(ctx, list.BoardID, checklist.CardID, "CHECKLIST_DELETED", "checklist", checklist)
+	s.record(ctx, list.BoardID, checklist.CardID, userID, "checklist.deleted", "checklist", checklist.ID, checklist, nil)
+	return nil
+}
+
+func (s *checklistService) AddItem(ctx context.Context, checklistID, userID int, title string) (*models.ChecklistItem, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrEmptyChecklistItemTitle
+	}
+	checklist, list, err := s.checklistForUser(ctx, checklistID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPos, err := s.repo.GetMaxItemPosition(ctx, checklistID)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine checklist item position: %w", err)
+	}
+	item := &models.ChecklistItem{ChecklistID: checklistID, Title: title, Position: ordering.After(maxPos)}
+	if err := s.repo.CreateItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	s.broadcastWithProgress(ctx, list.BoardID, checklist.CardID, "CHECKLIST_ITEM_CREATED", "item", item)
+	s.record(ctx, list.BoardID, checklist.CardID, userID, "checklist_item.created", "checklist_item", item.ID, nil, item)
+	return item, nil
+}
+
+func (s *checklistService) UpdateItem(ctx context.Context, itemID, userID int, title *string, checked *bool) (*models.ChecklistItem, error) {
+	item, checklist, list, err := s.itemForUser(ctx, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *item
+	if title != nil {
+		trimmed := strings.TrimSpace(*title)
+		if trimmed == "" {
+			return nil, ErrEmptyChecklistItemTitle
+		}
+		item.Title = trimmed
+	}
+	if checked != nil {
+		item.Checked = *checked
+	}
+	if err := s.repo.UpdateItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	s.broadcastWithProgress(ctx, list.BoardID, checklist.CardID, "CHECKLIST_ITEM_UPDATED", "item", item)
+	s.record(ctx, list.BoardID, checklist.CardID, userID, "checklist_item.updated", "checklist_item", item.ID, before, item)
+	return item, nil
+}
+
+func (s *checklistService) MoveItem(ctx context.Context, itemID int, placement Placement, userID int) (*models.ChecklistItem, error) {
+	if err := placement.validate(); err != nil {
+		return nil, err
+	}
+	item, checklist, list, err := s.itemForUser(ctx, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := s.resolveItemPosition(ctx, item, placement)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.MoveItem(ctx, itemID, position); err != nil {
+		return nil, err
+	}
+	oldPosition := item.Position
+	item.Position = position
+
+	broadcastEvent(s.broadcaster, list.BoardID, "CHECKLIST_ITEM_MOVED", map[string]interface{}{
+		"card_id": checklist.CardID,
+		"item":    item,
+	})
+	s.record(ctx, list.BoardID, checklist.CardID, userID, "checklist_item.moved", "checklist_item", item.ID,
+		map[string]interface{}{"position": oldPosition}, map[string]interface{}{"position": position})
+	return item, nil
+}
+
+func (s *checklistService) DeleteItem(ctx context.Context, itemID, userID int) error {
+	item, checklist, list, err := s.itemForUser(ctx, itemID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteItem(ctx, itemID); err != nil {
+		return err
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	s.broadcastWithProgress(ctx, list.BoardID, checklist.CardID, "CHECKLIST_ITEM_DELETED", "item", item)
+	s.record(ctx, list.BoardID, checklist.CardID, userID, "checklist_item.deleted", "checklist_item", item.ID, item, nil)
+	return nil
+}
+
+func (s *checklistService) checklistForUser(ctx context.Context, checklistID, userID int, minRole models.BoardRole) (*models.Checklist, *models.List, error) {
+	checklist, err := s.repo.GetByID(ctx, checklistID)
+	if err != nil {
+		return nil, nil, apperr.NoRows(err, ErrChecklistNotFound)
+	}
+	_, list, _, err := s.access.card(ctx, checklist.CardID, userID, minRole)
+	if err != nil {
+		return nil, nil, err
+	}
+	return checklist, list, nil
+}
+
+func (s *checklistService) itemForUser(ctx context.Context, itemID, userID int) (*models.ChecklistItem, *models.Checklist, *models.List, error) {
+	item, err := s.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, nil, nil, apperr.NoRows(err, ErrChecklistItemNotFound)
+	}
+	checklist, list, err := s.checklistForUser(ctx, item.ChecklistID, userID, models.RoleMember)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return item, checklist, list, nil
+}
+
+// resolveItemPosition переводит Placement в позицию внутри чек-листа пункта.
+func (s *checklistService) resolveItemPosition(ctx context.Context, item *models.ChecklistItem, placement Placement) (float64, error) {
+	switch {
+	case placement.Position != nil:
+		return *placement.Position, nil
+	case placement.BeforeID != nil || placement.AfterID != nil:
+		anchorID, before := 0, placement.BeforeID != nil
+		if before {
+			anchorID = *placement.BeforeID
+		} else {
+			anchorID = *placement.AfterID
+		}
+		if anchorID == item.ID {
+			return 0, apperr.Validation("invalid_placement", "checklist item cannot be placed relative to itself")
+		}
+
+		anchorPosition := func(ctx context.Context) (float64, error) {
+			anchor, err := s.repo.GetItemByID(ctx, anchorID)
+			if err != nil {
+				return 0, apperr.NoRows(err, apperr.NotFound(ErrChecklistItemNotFound.Code, "checklist item with id %d not found", anchorID))
+			}
+			if anchor.ChecklistID != item.ChecklistID {
+				return 0, apperr.Validation("invalid_placement", "checklist item %d is not in checklist %d", anchorID, item.ChecklistID)
+			}
+			return anchor.Position, nil
+		}
+		neighbourPosition := func(ctx context.Context, position float64, before bool) (*float64, error) {
+			return s.repo.GetItemNeighbourPosition(ctx, item.ChecklistID, position, before, item.ID)
+		}
+		rebalance := func(ctx context.Context) error {
+			return s.repo.RebalanceItems(ctx, item.ChecklistID, ordering.Step)
+		}
+		return placeNextTo(ctx, before, anchorPosition, neighbourPosition, rebalance)
+	default:
+		maxPos, err := s.repo.GetMaxItemPosition(ctx, item.ChecklistID)
+		if err != nil {
+			return 0, fmt.Errorf("could not determine checklist item position: %w", err)
+		}
+		return ordering.After(maxPos), nil
+	}
+}
+
+// broadcastWithProgress рассылает событие вместе с актуальным прогрессом карточки,
+// чтобы клиенты обновляли счётчик вида 3/7 без повторного запроса доски.
+func (s *checklistService) broadcastWithProgress(ctx context.Context, boardID, cardID int, event, key string, value interface{}) {
+	progress := models.ChecklistProgress{}
+	if byCard, err := s.repo.GetProgressByCardIDs(ctx, []int{cardID}); err == nil {
+		progress = byCard[cardID]
+	}
+	broadcastEvent(s.broadcaster, boardID, event, map[string]interface{}{
+		"card_id":  cardID,
+		key:        value,
+		"progress": progress,
+	})
+}
+
+func (s *checklistService) record(ctx context.Context, boardID, cardID, userID int, action, entityType string, entityID int, before, after interface{}) {
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, CardID: &cardID, ActorID: userID, Action: action, EntityType: entityType, EntityID: entityID,
+		Before: before, After: after,
+	})
+}
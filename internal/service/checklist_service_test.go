@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChecklistService_UpdateItem_BroadcastsProgress(t *testing.T) {
+	// --- ARRANGE ---
+	mockChecklistRepo := new(repository.MockChecklistRepository)
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	invalidated := 0
+	checklistService := NewChecklistService(mockChecklistRepo, mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo),
+		newNopActivityRecorder(), mockBroadcaster, func(ctx context.Context, boardID int) { invalidated = boardID })
+
+	mockChecklistRepo.On("GetItemByID", mock.Anything, 5).Return(&models.ChecklistItem{ID: 5, ChecklistID: 3}, nil).Once()
+	mockChecklistRepo.On("GetByID", mock.Anything, 3).Return(&models.Checklist{ID: 3, CardID: 10}, nil).Once()
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil).Once()
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil).Once()
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockChecklistRepo.On("UpdateItem", mock.Anything, mock.MatchedBy(func(item *models.ChecklistItem) bool {
+		return item.ID == 5 && item.Checked
+	})).Return(nil).Once()
+	mockChecklistRepo.On("GetProgressByCardIDs", mock.Anything, []int{10}).
+		Return(map[int]models.ChecklistProgress{10: {Checked: 3, Total: 7}}, nil).Once()
+
+	var msg struct {
+		Event   string `json:"event"`
+		Payload struct {
+			CardID   int                      `json:"card_id"`
+			Progress models.ChecklistProgress `json:"progress"`
+		} `json:"payload"`
+	}
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Run(func(args mock.Arguments) {
+		assert.NoError(t, json.Unmarshal(args.Get(1).([]byte), &msg))
+	}).Return().Once()
+
+	// --- ACT ---
+	checked := true
+	item, err := checklistService.UpdateItem(context.Background(), 5, 1, nil, &checked)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.True(t, item.Checked)
+	assert.Equal(t, 1000, invalidated)
+	assert.Equal(t, "CHECKLIST_ITEM_UPDATED", msg.Event)
+	assert.Equal(t, 10, msg.Payload.CardID)
+	assert.Equal(t, models.ChecklistProgress{Checked: 3, Total: 7}, msg.Payload.Progress)
+	mockChecklistRepo.AssertExpectations(t)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"strings"
+)
+
+var (
+	ErrCommentNotFound = apperr.NotFound("comment_not_found", "comment not found")
+	ErrEmptyComment    = apperr.Validation("empty_comment", "comment body cannot be empty")
+)
+
+type CommentService interface {
+	List(ctx context.Context, cardID, userID, limit, offset int) (*models.CommentPage, error)
+	Create(ctx context.Context, cardID, userID int, body string) (*models.Comment, error)
+	Update(ctx context.Context, commentID, userID int, body string) (*models.Comment, error)
+	Delete(ctx context.Context, commentID, userID int) error
+}
+
+type commentService struct {
+	repo        repository.CommentRepository
+	access      cardAccess
+	activity    ActivityRecorder
+	broadcaster Broadcaster
+}
+
+func NewCommentService(
+	repo repository.CommentRepository,
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	permissions BoardPermissions,
+	activity ActivityRecorder,
+	broadcaster Broadcaster) CommentService {
+	return &commentService{
+		repo:        repo,
+		access:      cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		activity:    activity,
+		broadcaster: broadcaster,
+	}
+}
+
+func (s *commentService) List(ctx context.Context, cardID, userID, limit, offset int) (*models.CommentPage, error) {
+	if _, _, _, err := s.access.card(ctx, cardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	comments, total, err := s.repo.GetPageByCardID(ctx, cardID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CommentPage{Comments: comments, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *commentService) Create(ctx context.Context, cardID, userID int, body string) (*models.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+	_, list, _, err := s.access.card(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{CardID: cardID, AuthorID: userID, Body: body}
+	if err := s.repo.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "COMMENT_CREATED", comment)
+	s.recordComment(ctx, list.BoardID, userID, "comment.created", comment, nil, comment)
+	return comment, nil
+}
+
+func (s *commentService) Update(ctx context.Context, commentID, userID int, body string) (*models.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+	comment, err := s.repo.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrCommentNotFound)
+	}
+	_, list, _, err := s.access.card(ctx, comment.CardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		return nil, apperr.Forbidden("not_comment_author", "only the author can edit a comment")
+	}
+
+	before := map[string]interface{}{"body": comment.Body}
+	comment.Body = body
+	if err := s.repo.Update(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "COMMENT_UPDATED", comment)
+	s.recordComment(ctx, list.BoardID, userID, "comment.updated", comment, before, map[string]interface{}{"body": comment.Body})
+	return comment, nil
+}
+
+// Delete разрешён автору комментария, а также администраторам и владельцу доски для модерации.
+func (s *commentService) Delete(ctx context.Context, commentID, userID int) error {
+	comment, err := s.repo.GetByID(ctx, commentID)
+	if err != nil {
+		return apperr.NoRows(err, ErrCommentNotFound)
+	}
+	_, list, role, err := s.access.card(ctx, comment.CardID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != userID && !role.AtLeast(models.RoleAdmin) {
+		return apperr.Forbidden("not_comment_author", "only the author or a board admin can delete a comment")
+	}
+
+	if err := s.repo.Delete(ctx, commentID); err != nil {
+		return err
+	}
+
+	broadcastEvent(s.broadcaster, list.BoardID, "COMMENT_DELETED", comment)
+	s.recordComment(ctx, list.BoardID, userID, "comment.deleted", comment, comment, nil)
+	return nil
+}
+
+func (s *commentService) recordComment(ctx context.Context, boardID, userID int, action string, comment *models.Comment, before, after interface{}) {
+	cardID := comment.CardID
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, CardID: &cardID, ActorID: userID, Action: action, EntityType: "comment", EntityID: comment.ID,
+		Before: before, After: after,
+	})
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCommentService(commentRepo *repository.MockCommentRepository, boardRepo *repository.MockBoardRepository,
+	broadcaster *MockBroadcaster) CommentService {
+	cardRepo := new(repository.MockCardRepository)
+	listRepo := new(repository.MockListRepository)
+	cardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil)
+	listRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	return NewCommentService(commentRepo, cardRepo, listRepo, NewBoardPermissions(boardRepo), newNopActivityRecorder(), broadcaster)
+}
+
+func TestCommentService_Update_OnlyAuthor(t *testing.T) {
+	// --- ARRANGE ---
+	mockCommentRepo := new(repository.MockCommentRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	commentService := newTestCommentService(mockCommentRepo, mockBoardRepo, mockBroadcaster)
+
+	mockCommentRepo.On("GetByID", mock.Anything, 5).Return(&models.Comment{ID: 5, CardID: 10, AuthorID: 1}, nil)
+	// Даже администратор доски не может редактировать чужой комментарий.
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 2).Return(models.RoleAdmin, nil).Once()
+
+	// --- ACT ---
+	_, err := commentService.Update(context.Background(), 5, 2, "исправленный текст")
+
+	// --- ASSERT ---
+	assert.Error(t, err)
+	mockCommentRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
+
+func TestCommentService_Delete_AdminCanModerate(t *testing.T) {
+	// --- ARRANGE ---
+	mockCommentRepo := new(repository.MockCommentRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	commentService := newTestCommentService(mockCommentRepo, mockBoardRepo, mockBroadcaster)
+
+	mockCommentRepo.On("GetByID", mock.Anything, 5).Return(&models.Comment{ID: 5, CardID: 10, AuthorID: 1}, nil)
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 2).Return(models.RoleAdmin, nil).Once()
+	mockCommentRepo.On("Delete", mock.Anything, 5).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	err := commentService.Delete(context.Background(), 5, 2)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockCommentRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestCommentService_Delete_RecordsActivity(t *testing.T) {
+	// --- ARRANGE ---
+	mockCommentRepo := new(repository.MockCommentRepository)
+	mockCardRepo := new(repository.MockCardRepository)
+	mockListRepo := new(repository.MockListRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	mockActivity := new(MockActivityRecorder)
+	commentService := NewCommentService(mockCommentRepo, mockCardRepo, mockListRepo, NewBoardPermissions(mockBoardRepo),
+		mockActivity, mockBroadcaster)
+
+	mockCardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil)
+	mockListRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	mockCommentRepo.On("GetByID", mock.Anything, 5).Return(&models.Comment{ID: 5, CardID: 10, AuthorID: 1}, nil)
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockCommentRepo.On("Delete", mock.Anything, 5).Return(nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+	mockActivity.On("Record", mock.Anything, mock.MatchedBy(func(entry ActivityEntry) bool {
+		return entry.BoardID == 1000 && entry.CardID != nil && *entry.CardID == 10 && entry.ActorID == 1 &&
+			entry.Action == "comment.deleted" && entry.EntityType == "comment" && entry.EntityID == 5
+	})).Return().Once()
+
+	// --- ACT ---
+	err := commentService.Delete(context.Background(), 5, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockActivity.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"log"
+	"notes-project/internal/repository"
+	"time"
+)
+
+// dueBatchSize ограничивает число карточек, о которых планировщик уведомляет за один проход.
+const dueBatchSize = 500
+
+// DueDateScheduler периодически ищет карточки, у которых подходит или уже прошёл срок,
+// и рассылает CARD_DUE_SOON/CARD_OVERDUE. Каждый порог срабатывает для карточки один раз,
+// пока срок не изменят.
+type DueDateScheduler struct {
+	cardRepo      repository.CardRepository
+	broadcaster   Broadcaster
+	interval      time.Duration
+	dueSoonWindow time.Duration
+	now           func() time.Time
+}
+
+func NewDueDateScheduler(
+	cardRepo repository.CardRepository,
+	broadcaster Broadcaster,
+	interval time.Duration,
+	dueSoonWindow time.Duration) *DueDateScheduler {
+	return &DueDateScheduler{
+		cardRepo:      cardRepo,
+		broadcaster:   broadcaster,
+		interval:      interval,
+		dueSoonWindow: dueSoonWindow,
+		now:           time.Now,
+	}
+}
+
+func (d *DueDateScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.CheckOnce(ctx)
+		}
+	}
+}
+
+func (d *DueDateScheduler) CheckOnce(ctx context.Context) {
+	now := d.now()
+
+	overdue, err := d.cardRepo.ClaimOverdue(ctx, now, dueBatchSize)
+	if err != nil {
+		log.Printf("DueDateScheduler: could not claim overdue cards: %v", err)
+	}
+	for boardID, cards := range overdue {
+		for _, card := range cards {
+			broadcastEvent(d.broadcaster, boardID, "CARD_OVERDUE", card)
+		}
+	}
+
+	dueSoon, err := d.cardRepo.ClaimDueSoon(ctx, now, now.Add(d.dueSoonWindow), dueBatchSize)
+	if err != nil {
+		log.Printf("DueDateScheduler: could not claim due soon cards: %v", err)
+	}
+	for boardID, cards := range dueSoon {
+		for _, card := range cards {
+			broadcastEvent(d.broadcaster, boardID, "CARD_DUE_SOON", card)
+		}
+	}
+}
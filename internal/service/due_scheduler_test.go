@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDueDateScheduler_CheckOnce(t *testing.T) {
+	// --- ARRANGE ---
+	mockCardRepo := new(repository.MockCardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	scheduler := NewDueDateScheduler(mockCardRepo, mockBroadcaster, time.Minute, time.Hour)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	scheduler.now = func() time.Time { return now }
+
+	mockCardRepo.On("ClaimOverdue", mock.Anything, now, dueBatchSize).
+		Return(map[int][]models.Card{1: {{ID: 10}}}, nil).Once()
+	mockCardRepo.On("ClaimDueSoon", mock.Anything, now, now.Add(time.Hour), dueBatchSize).
+		Return(map[int][]models.Card{2: {{ID: 20}}}, nil).Once()
+
+	var events []string
+	mockBroadcaster.On("BroadcastToBoard", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		var msg models.WebSocketMessage
+		assert.NoError(t, json.Unmarshal(args.Get(1).([]byte), &msg))
+		events = append(events, msg.Event)
+	}).Return().Twice()
+
+	// --- ACT ---
+	scheduler.CheckOnce(context.Background())
+
+	// --- ASSERT ---
+	assert.Equal(t, []string{"CARD_OVERDUE", "CARD_DUE_SOON"}, events)
+	mockBroadcaster.AssertCalled(t, "BroadcastToBoard", 1, mock.Anything)
+	mockBroadcaster.AssertCalled(t, "BroadcastToBoard", 2, mock.Anything)
+	mockCardRepo.AssertExpectations(t)
+}
@@ -1,5 +1,22 @@
 package service
 
+import (
+	"encoding/json"
+	"log"
+	"notes-project/internal/models"
+)
+
 type Broadcaster interface {
 	BroadcastToBoard(boardID int, message []byte)
+	// DisconnectUser закрывает все WebSocket-соединения пользователя с доской.
+	DisconnectUser(boardID, userID int)
+}
+
+func broadcastEvent(b Broadcaster, boardID int, event string, payload interface{}) {
+	message, err := json.Marshal(models.WebSocketMessage{Event: event, Payload: payload})
+	if err != nil {
+		log.Printf("could not marshal %s event for board %d: %v", event, boardID, err)
+		return
+	}
+	b.BroadcastToBoard(boardID, message)
 }
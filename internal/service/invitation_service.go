@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"notes-project/internal/apperr"
+	"notes-project/internal/mailer"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"notes-project/internal/validation"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	invitationTTL       = 7 * 24 * time.Hour
+	invitationTokenType = "board_invitation"
+)
+
+var (
+	ErrInvalidInvitationToken  = apperr.Validation("invalid_invitation_token", "invalid or expired invitation token")
+	ErrInvitationNotFound      = apperr.NotFound("invitation_not_found", "invitation not found")
+	ErrInvitationNoLongerValid = apperr.Conflict("invitation_no_longer_valid", "invitation is no longer valid")
+)
+
+type InvitationService interface {
+	Invite(ctx context.Context, boardID, inviterID int, email string, role models.BoardRole) (*models.Invitation, error)
+	ListPending(ctx context.Context, boardID, userID int) ([]models.Invitation, error)
+	Revoke(ctx context.Context, boardID, invitationID, userID int) error
+	Accept(ctx context.Context, token string, userID int) (*models.Invitation, error)
+	AcceptPendingForUser(ctx context.Context, user *models.User) error
+}
+
+type invitationService struct {
+	repo                 repository.InvitationRepository
+	boardRepo            repository.BoardRepository
+	userRepo             repository.UserRepository
+	permissions          BoardPermissions
+	mailer               mailer.Mailer
+	activity             ActivityRecorder
+	broadcaster          Broadcaster
+	invalidateBoardCache CacheInvalidator
+	secret               []byte
+	baseURL              string
+}
+
+func NewInvitationService(
+	repo repository.InvitationRepository,
+	boardRepo repository.BoardRepository,
+	userRepo repository.UserRepository,
+	permissions BoardPermissions,
+	mailer mailer.Mailer,
+	activity ActivityRecorder,
+	broadcaster Broadcaster,
+	cacheInvalidator CacheInvalidator,
+	secret []byte,
+	baseURL string) InvitationService {
+	return &invitationService{
+		repo:                 repo,
+		boardRepo:            boardRepo,
+		userRepo:             userRepo,
+		permissions:          permissions,
+		mailer:               mailer,
+		activity:             activity,
+		broadcaster:          broadcaster,
+		invalidateBoardCache: cacheInvalidator,
+		secret:               secret,
+		baseURL:              strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// hashToken - в базе хранятся только хэши токенов, чтобы утечка таблицы не давала рабочих ссылок.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *invitationService) newToken(expiresAt time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("could not generate invitation token: %w", err)
+	}
+	claims := jwt.MapClaims{
+		"typ": invitationTokenType,
+		"jti": hex.EncodeToString(nonce),
+		"exp": expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("could not sign invitation token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *invitationService) verifyToken(tokenString string) error {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return ErrInvalidInvitationToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != invitationTokenType {
+		return ErrInvalidInvitationToken
+	}
+	return nil
+}
+
+func (s *invitationService) Invite(ctx context.Context, boardID, inviterID int, email string, role models.BoardRole) (*models.Invitation, error) {
+	inviterRole, err := s.permissions.Require(ctx, boardID, inviterID, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkGrantableRole(inviterRole, role); err != nil {
+		return nil, err
+	}
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrBoardNotFound)
+	}
+
+	expiresAt := time.Now().Add(invitationTTL)
+	token, err := s.newToken(expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	invitation := &models.Invitation{
+		BoardID:   boardID,
+		Email:     validation.NormalizeEmail(email),
+		Role:      role,
+		TokenHash: hashToken(token),
+		InvitedBy: inviterID,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	msg := mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to the board %q", board.Name),
+		Body: fmt.Sprintf("You have been invited to join the board %q as %s.\n\n"+
+			"Accept the invitation: %s/invitations/accept?token=%s\n\n"+
+			"If you do not have an account yet, register with this email and the invitation will be accepted automatically.\n"+
+			"The invitation expires on %s.",
+			board.Name, role, s.baseURL, url.QueryEscape(token), expiresAt.Format(time.RFC1123)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return nil, fmt.Errorf("could not send invitation email: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (s *invitationService) ListPending(ctx context.Context, boardID, userID int) ([]models.Invitation, error) {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.repo.GetPendingForBoard(ctx, boardID)
+}
+
+func (s *invitationService) Revoke(ctx context.Context, boardID, invitationID, userID int) error {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return err
+	}
+	return apperr.NoRows(s.repo.Revoke(ctx, boardID, invitationID), ErrInvitationNotFound)
+}
+
+func (s *invitationService) Accept(ctx context.Context, token string, userID int) (*models.Invitation, error) {
+	if err := s.verifyToken(token); err != nil {
+		return nil, err
+	}
+	invitation, err := s.repo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrInvitationNotFound)
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrUserNotFound)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, apperr.Forbidden("invitation_email_mismatch", "this invitation was sent to a different email")
+	}
+
+	return s.accept(ctx, invitation.ID, user)
+}
+
+// AcceptPendingForUser принимает все действующие приглашения, отправленные на email пользователя.
+// Вызывается при подтверждении почты, поэтому ошибки отдельных приглашений только логируются.
+func (s *invitationService) AcceptPendingForUser(ctx context.Context, user *models.User) error {
+	invitations, err := s.repo.GetPendingForEmail(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	for _, invitation := range invitations {
+		if _, err := s.accept(ctx, invitation.ID, user); err != nil {
+			log.Printf("could not auto-accept invitation %d for user %d: %v", invitation.ID, user.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *invitationService) accept(ctx context.Context, invitationID int, user *models.User) (*models.Invitation, error) {
+	invitation, err := s.repo.Accept(ctx, invitationID, user.ID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrInvitationNoLongerValid)
+	}
+
+	member := models.BoardMember{UserID: user.ID, Name: user.Name, Email: user.Email, Role: invitation.Role}
+	s.invalidateBoardCache(ctx, invitation.BoardID)
+	broadcastEvent(s.broadcaster, invitation.BoardID, "MEMBER_ADDED", member)
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: invitation.BoardID, ActorID: user.ID, Action: "member.joined", EntityType: "member", EntityID: user.ID,
+		After: member,
+	})
+	return invitation, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"notes-project/internal/mailer"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenInMail = regexp.MustCompile(`token=(\S+)`)
+
+func TestInvitationService_InviteAndAccept(t *testing.T) {
+	// --- ARRANGE ---
+	mockInvitationRepo := new(repository.MockInvitationRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockUserRepo := new(repository.MockUserRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	memoryMailer := mailer.NewMemoryMailer()
+
+	invitationService := NewInvitationService(mockInvitationRepo, mockBoardRepo, mockUserRepo,
+		NewBoardPermissions(mockBoardRepo), memoryMailer, newNopActivityRecorder(), mockBroadcaster,
+		func(ctx context.Context, boardID int) {}, []byte("test-secret"), "http://app.test")
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleOwner, nil).Once()
+	mockBoardRepo.On("GetByID", mock.Anything, 1).Return(&models.Board{ID: 1, Name: "Roadmap"}, nil).Once()
+	var stored *models.Invitation
+	mockInvitationRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.Invitation)
+		stored.ID = 5
+	}).Return(nil).Once()
+
+	// --- ACT: приглашаем ещё не зарегистрированного пользователя ---
+	invitation, err := invitationService.Invite(context.Background(), 1, 10, "new@example.com", models.RoleMember)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, 5, invitation.ID)
+	messages := memoryMailer.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "new@example.com", messages[0].To)
+
+	match := tokenInMail.FindStringSubmatch(messages[0].Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	// В базе хранится только хэш токена из письма.
+	assert.Equal(t, hashToken(token), stored.TokenHash)
+
+	// --- ACT: принимаем приглашение по токену из письма ---
+	mockInvitationRepo.On("GetByTokenHash", mock.Anything, stored.TokenHash).Return(stored, nil).Twice()
+	mockUserRepo.On("GetByID", mock.Anything, 20).Return(&models.User{ID: 20, Email: "New@Example.com"}, nil).Once()
+	mockUserRepo.On("GetByID", mock.Anything, 30).Return(&models.User{ID: 30, Email: "other@example.com"}, nil).Once()
+	mockInvitationRepo.On("Accept", mock.Anything, 5, 20).Return(stored, nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1, mock.Anything).Return().Once()
+
+	_, err = invitationService.Accept(context.Background(), token, 30)
+	assert.Error(t, err, "invitation must not be accepted by a different email")
+
+	accepted, err := invitationService.Accept(context.Background(), token, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 1, accepted.BoardID)
+
+	mockInvitationRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestInvitationService_Accept_RejectsForgedToken(t *testing.T) {
+	mockInvitationRepo := new(repository.MockInvitationRepository)
+	invitationService := NewInvitationService(mockInvitationRepo, nil, nil, nil, mailer.NewMemoryMailer(), nil, nil,
+		func(ctx context.Context, boardID int) {}, []byte("test-secret"), "http://app.test")
+
+	_, err := invitationService.Accept(context.Background(), "not-a-token", 20)
+
+	assert.Error(t, err)
+	mockInvitationRepo.AssertNotCalled(t, "GetByTokenHash", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"regexp"
+	"strings"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+var (
+	ErrLabelNotFound = apperr.NotFound("label_not_found", "label not found")
+	ErrLabelExists   = apperr.Conflict("label_exists", "a label with this name already exists on the board")
+)
+
+type LabelService interface {
+	List(ctx context.Context, boardID, userID int) ([]models.Label, error)
+	Create(ctx context.Context, boardID, userID int, name, color string) (*models.Label, error)
+	Update(ctx context.Context, boardID, labelID, userID int, name, color *string) (*models.Label, error)
+	Delete(ctx context.Context, boardID, labelID, userID int) error
+	AttachToCard(ctx context.Context, cardID, labelID, userID int) ([]models.Label, error)
+	DetachFromCard(ctx context.Context, cardID, labelID, userID int) ([]models.Label, error)
+}
+
+type labelService struct {
+	repo            repository.LabelRepository
+	access          cardAccess
+	activity        ActivityRecorder
+	broadcaster     Broadcaster
+	invalidateCache CacheInvalidator
+}
+
+func NewLabelService(
+	repo repository.LabelRepository,
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	permissions BoardPermissions,
+	activity ActivityRecorder,
+	broadcaster Broadcaster,
+	invalidateCache CacheInvalidator) LabelService {
+	return &labelService{
+		repo:            repo,
+		access:          cardAccess{cardRepo: cardRepo, listRepo: listRepo, permissions: permissions},
+		activity:        activity,
+		broadcaster:     broadcaster,
+		invalidateCache: invalidateCache,
+	}
+}
+
+func (s *labelService) List(ctx context.Context, boardID, userID int) ([]models.Label, error) {
+	if _, err := s.access.permissions.Require(ctx, boardID, userID, models.RoleObserver); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAllByBoardID(ctx, boardID)
+}
+
+func (s *labelService) Create(ctx context.Context, boardID, userID int, name, color string) (*models.Label, error) {
+	name = strings.TrimSpace(name)
+	if err := validateLabel(name, color); err != nil {
+		return nil, err
+	}
+	if _, err := s.access.permissions.RequireActive(ctx, boardID, userID, models.RoleMember); err != nil {
+		return nil, err
+	}
+
+	label := &models.Label{BoardID: boardID, Name: name, Color: strings.ToLower(color)}
+	if err := s.repo.Create(ctx, label); err != nil {
+		if errors.Is(err, repository.ErrDuplicateLabel) {
+			return nil, ErrLabelExists
+		}
+		return nil, err
+	}
+
+	s.invalidateCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "LABEL_CREATED", label)
+	s.recordLabel(ctx, boardID, nil, userID, "label.created", label.ID, nil, label)
+	return label, nil
+}
+
+func (s *labelService) Update(ctx context.Context, boardID, labelID, userID int, name, color *string) (*models.Label, error) {
+	if _, err := s.access.permissions.RequireActive(ctx, boardID, userID, models.RoleMember); err != nil {
+		return nil, err
+	}
+	label, err := s.boardLabel(ctx, boardID, labelID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *label
+	if name != nil {
+		label.Name = strings.TrimSpace(*name)
+	}
+	if color != nil {
+		label.Color = strings.ToLower(*color)
+	}
+	if err := validateLabel(label.Name, label.Color); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, label); err != nil {
+		if errors.Is(err, repository.ErrDuplicateLabel) {
+			return nil, ErrLabelExists
+		}
+		return nil, err
+	}
+
+	s.invalidateCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "LABEL_UPDATED", label)
+	s.recordLabel(ctx, boardID, nil, userID, "label.updated", label.ID, before, label)
+	return label, nil
+}
+
+func (s *labelService) Delete(ctx context.Context, boardID, labelID, userID int) error {
+	if _, err := s.access.permissions.RequireActive(ctx, boardID, userID, models.RoleMember); err != nil {
+		return err
+	}
+	label, err := s.boardLabel(ctx, boardID, labelID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, labelID); err != nil {
+		return err
+	}
+
+	s.invalidateCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "LABEL_DELETED", label)
+	s.recordLabel(ctx, boardID, nil, userID, "label.deleted", label.ID, label, nil)
+	return nil
+}
+
+func (s *labelService) AttachToCard(ctx context.Context, cardID, labelID, userID int) ([]models.Label, error) {
+	return s.changeCardLabels(ctx, cardID, labelID, userID, "card.label_added", s.repo.Attach)
+}
+
+func (s *labelService) DetachFromCard(ctx context.Context, cardID, labelID, userID int) ([]models.Label, error) {
+	return s.changeCardLabels(ctx, cardID, labelID, userID, "card.label_removed", s.repo.Detach)
+}
+
+// changeCardLabels проверяет, что метка принадлежит доске карточки, применяет change
+// и рассылает актуальный набор меток карточки.
+func (s *labelService) changeCardLabels(ctx context.Context, cardID, labelID, userID int, action string,
+	change func(ctx context.Context, cardID, labelID int) error) ([]models.Label, error) {
+	_, list, _, err := s.access.card(ctx, cardID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	label, err := s.boardLabel(ctx, list.BoardID, labelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(ctx, cardID, labelID); err != nil {
+		return nil, err
+	}
+
+	labels, err := s.repo.GetByCardID(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "CARD_LABELS_CHANGED", map[string]interface{}{
+		"card_id": cardID,
+		"labels":  labels,
+	})
+	s.recordLabel(ctx, list.BoardID, &cardID, userID, action, labelID, nil, label)
+	return labels, nil
+}
+
+func (s *labelService) recordLabel(ctx context.Context, boardID int, cardID *int, userID int, action string, labelID int, before, after interface{}) {
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, CardID: cardID, ActorID: userID, Action: action, EntityType: "label", EntityID: labelID,
+		Before: before, After: after,
+	})
+}
+
+func (s *labelService) boardLabel(ctx context.Context, boardID, labelID int) (*models.Label, error) {
+	label, err := s.repo.GetByID(ctx, labelID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrLabelNotFound)
+	}
+	if label.BoardID != boardID {
+		return nil, apperr.NotFound(ErrLabelNotFound.Code, "label with id %d not found on board %d", labelID, boardID)
+	}
+	return label, nil
+}
+
+func validateLabel(name, color string) error {
+	if name == "" {
+		return apperr.Validation("empty_label_name", "label name cannot be empty")
+	}
+	if !labelColorPattern.MatchString(color) {
+		return apperr.Validation("invalid_label_color", "label color must be a hex value like #ff0000")
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestLabelService(labelRepo *repository.MockLabelRepository, boardRepo *repository.MockBoardRepository,
+	broadcaster *MockBroadcaster) LabelService {
+	cardRepo := new(repository.MockCardRepository)
+	listRepo := new(repository.MockListRepository)
+	cardRepo.On("GetByID", mock.Anything, 10).Return(&models.Card{ID: 10, ListID: 100}, nil)
+	listRepo.On("GetByID", mock.Anything, 100).Return(&models.List{ID: 100, BoardID: 1000}, nil)
+	return NewLabelService(labelRepo, cardRepo, listRepo, NewBoardPermissions(boardRepo), newNopActivityRecorder(), broadcaster,
+		func(ctx context.Context, boardID int) {})
+}
+
+func TestLabelService_AttachToCard(t *testing.T) {
+	// --- ARRANGE ---
+	mockLabelRepo := new(repository.MockLabelRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	labelService := newTestLabelService(mockLabelRepo, mockBoardRepo, mockBroadcaster)
+
+	label := models.Label{ID: 7, BoardID: 1000, Name: "bug", Color: "#ff0000"}
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockLabelRepo.On("GetByID", mock.Anything, 7).Return(&label, nil).Once()
+	mockLabelRepo.On("Attach", mock.Anything, 10, 7).Return(nil).Once()
+	mockLabelRepo.On("GetByCardID", mock.Anything, 10).Return([]models.Label{label}, nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+
+	// --- ACT ---
+	labels, err := labelService.AttachToCard(context.Background(), 10, 7, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Label{label}, labels)
+	mockLabelRepo.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestLabelService_AttachToCard_LabelFromAnotherBoard(t *testing.T) {
+	// --- ARRANGE ---
+	mockLabelRepo := new(repository.MockLabelRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	labelService := newTestLabelService(mockLabelRepo, mockBoardRepo, mockBroadcaster)
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+	mockLabelRepo.On("GetByID", mock.Anything, 7).Return(&models.Label{ID: 7, BoardID: 2000}, nil).Once()
+
+	// --- ACT ---
+	_, err := labelService.AttachToCard(context.Background(), 10, 7, 1)
+
+	// --- ASSERT ---
+	assert.Error(t, err)
+	mockLabelRepo.AssertNotCalled(t, "Attach", mock.Anything, mock.Anything, mock.Anything)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
+
+func TestLabelService_DuplicateName(t *testing.T) {
+	// --- ARRANGE ---
+	mockLabelRepo := new(repository.MockLabelRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	labelService := newTestLabelService(mockLabelRepo, mockBoardRepo, mockBroadcaster)
+	name := "bug"
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil)
+	mockBoardRepo.On("GetByID", mock.Anything, 1000).Return(&models.Board{ID: 1000}, nil)
+	mockLabelRepo.On("Create", mock.Anything, mock.Anything).Return(repository.ErrDuplicateLabel).Once()
+	mockLabelRepo.On("GetByID", mock.Anything, 7).Return(&models.Label{ID: 7, BoardID: 1000, Name: "feature", Color: "#00ff00"}, nil).Once()
+	mockLabelRepo.On("Update", mock.Anything, mock.Anything).Return(repository.ErrDuplicateLabel).Once()
+
+	// --- ACT ---
+	_, errCreate := labelService.Create(context.Background(), 1000, 1, "bug", "#ff0000")
+	_, errUpdate := labelService.Update(context.Background(), 1000, 7, 1, &name, nil)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, errCreate, ErrLabelExists)
+	assert.ErrorIs(t, errUpdate, ErrLabelExists)
+	mockLabelRepo.AssertExpectations(t)
+	mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+}
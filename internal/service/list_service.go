@@ -2,66 +2,229 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"notes-project/internal/apperr"
 	"notes-project/internal/models"
+	"notes-project/internal/ordering"
 	"notes-project/internal/repository"
 )
 
 type ListService interface {
 	Create(ctx context.Context, list *models.List, boardID, userID int) error
+	Update(ctx context.Context, listID, userID int, title string) (*models.List, error)
+	Move(ctx context.Context, listID int, placement Placement, userID int) error
+	Archive(ctx context.Context, listID, userID int) error
+	Restore(ctx context.Context, listID, userID int) (*models.List, error)
 }
 
 type CacheInvalidator func(ctx context.Context, boardID int)
 
 type listService struct {
 	listRepo             repository.ListRepository
-	boardRepo            repository.BoardRepository
+	permissions          BoardPermissions
+	activity             ActivityRecorder
 	broadcaster          Broadcaster
 	invalidateBoardCache CacheInvalidator
 }
 
 func NewListService(
 	listRepo repository.ListRepository,
-	boardRepo repository.BoardRepository,
+	permissions BoardPermissions,
+	activity ActivityRecorder,
 	broadcaster Broadcaster,
 	cacheInvalidator CacheInvalidator) ListService {
 	return &listService{
 		listRepo:             listRepo,
-		boardRepo:            boardRepo,
+		permissions:          permissions,
+		activity:             activity,
 		broadcaster:          broadcaster,
 		invalidateBoardCache: cacheInvalidator}
 }
 
-func (s *listService) checkBoardPermissions(ctx context.Context, boardID, userID int) error {
-	hasAccess, err := s.boardRepo.IsMemberOrOwner(ctx, boardID, userID)
+var (
+	ErrListAlreadyArchived = apperr.Conflict("list_already_archived", "list is already archived")
+	ErrListNotArchived     = apperr.Conflict("list_not_archived", "list is not archived")
+)
+
+// getListForUser загружает список и проверяет, что роль пользователя на его доске не ниже minRole.
+func (s *listService) getListForUser(ctx context.Context, listID, userID int, minRole models.BoardRole) (*models.List, error) {
+	list, err := s.listRepo.GetByID(ctx, listID)
 	if err != nil {
-		return fmt.Errorf("could not verify board permissions: %w", err)
+		return nil, apperr.NoRows(err, ErrListNotFound)
 	}
-	if !hasAccess {
-		return fmt.Errorf("access denied to this board")
+	if _, err := s.permissions.Require(ctx, list.BoardID, userID, minRole); err != nil {
+		return nil, err
 	}
-	return nil
+	return list, nil
 }
 
 func (s *listService) Create(ctx context.Context, list *models.List, boardID, userID int) error {
-	if err := s.checkBoardPermissions(ctx, boardID, userID); err != nil {
+	if _, err := s.permissions.RequireActive(ctx, boardID, userID, models.RoleMember); err != nil {
 		return err
 	}
 	maxPos, err := s.listRepo.GetMaxPositionForBoard(ctx, boardID)
 	if err != nil {
 		return fmt.Errorf("could not determine list position: %w", err)
 	}
-	list.Position = maxPos + 1.0
+	list.Position = ordering.After(maxPos)
 	list.BoardID = boardID
 	if err := s.listRepo.Create(ctx, list); err != nil {
 		return err
 	}
 
 	s.invalidateBoardCache(ctx, boardID)
+	broadcastEvent(s.broadcaster, boardID, "LIST_CREATED", list)
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: boardID, ActorID: userID, Action: "list.created", EntityType: "list", EntityID: list.ID, After: list,
+	})
+	return nil
+}
+
+func (s *listService) Update(ctx context.Context, listID, userID int, title string) (*models.List, error) {
+	list, err := s.getListForUser(ctx, listID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkListWritable(list); err != nil {
+		return nil, err
+	}
+
+	before := *list
+	list.Title = title
+	if err := s.listRepo.Update(ctx, list); err != nil {
+		return nil, apperr.NoRows(err, ErrListNotFound)
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "LIST_UPDATED", list)
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: list.BoardID, ActorID: userID, Action: "list.updated", EntityType: "list", EntityID: list.ID,
+		Before: before, After: list,
+	})
+	return list, nil
+}
 
-	wsMessage := models.WebSocketMessage{Event: "LIST_CREATED", Payload: list}
-	jsonMessage, _ := json.Marshal(wsMessage)
-	s.broadcaster.BroadcastToBoard(boardID, jsonMessage)
+func (s *listService) Move(ctx context.Context, listID int, placement Placement, userID int) error {
+	if err := placement.validate(); err != nil {
+		return err
+	}
+	list, err := s.getListForUser(ctx, listID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	if err := checkListWritable(list); err != nil {
+		return err
+	}
+
+	newPosition, err := s.resolvePosition(ctx, list, placement)
+	if err != nil {
+		return err
+	}
+
+	if err := s.listRepo.Move(ctx, listID, newPosition); err != nil {
+		return apperr.NoRows(err, ErrListNotFound)
+	}
+	oldPosition := list.Position
+	list.Position = newPosition
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "LIST_MOVED", list)
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: list.BoardID, ActorID: userID, Action: "list.moved", EntityType: "list", EntityID: list.ID,
+		Before: map[string]interface{}{"position": oldPosition},
+		After:  map[string]interface{}{"position": newPosition},
+	})
 	return nil
 }
+
+// resolvePosition переводит Placement в конкретную позицию внутри доски списка.
+func (s *listService) resolvePosition(ctx context.Context, list *models.List, placement Placement) (float64, error) {
+	switch {
+	case placement.Position != nil:
+		return *placement.Position, nil
+	case placement.BeforeID != nil || placement.AfterID != nil:
+		anchorID, before := 0, placement.BeforeID != nil
+		if before {
+			anchorID = *placement.BeforeID
+		} else {
+			anchorID = *placement.AfterID
+		}
+		if anchorID == list.ID {
+			return 0, apperr.Validation("invalid_placement", "list cannot be placed relative to itself")
+		}
+
+		anchorPosition := func(ctx context.Context) (float64, error) {
+			anchor, err := s.listRepo.GetByID(ctx, anchorID)
+			if err != nil {
+				return 0, apperr.NoRows(err, apperr.NotFound(ErrListNotFound.Code, "list with id %d not found", anchorID))
+			}
+			if anchor.BoardID != list.BoardID {
+				return 0, apperr.Validation("invalid_placement", "list %d is not on board %d", anchorID, list.BoardID)
+			}
+			return anchor.Position, nil
+		}
+		neighbourPosition := func(ctx context.Context, position float64, before bool) (*float64, error) {
+			return s.listRepo.GetNeighbourPosition(ctx, list.BoardID, position, before, list.ID)
+		}
+		rebalance := func(ctx context.Context) error {
+			return s.listRepo.Rebalance(ctx, list.BoardID, ordering.Step)
+		}
+		return placeNextTo(ctx, before, anchorPosition, neighbourPosition, rebalance)
+	default:
+		maxPos, err := s.listRepo.GetMaxPositionForBoard(ctx, list.BoardID)
+		if err != nil {
+			return 0, fmt.Errorf("could not determine list position: %w", err)
+		}
+		return ordering.After(maxPos), nil
+	}
+}
+
+func (s *listService) Archive(ctx context.Context, listID, userID int) error {
+	list, err := s.getListForUser(ctx, listID, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	if list.BoardArchivedAt != nil {
+		return ErrBoardArchived
+	}
+
+	if err := s.listRepo.Archive(ctx, listID); err != nil {
+		return apperr.NoRows(err, ErrListAlreadyArchived)
+	}
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "LIST_ARCHIVED", list)
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: list.BoardID, ActorID: userID, Action: "list.archived", EntityType: "list", EntityID: list.ID, Before: list,
+	})
+	return nil
+}
+
+// Restore возвращает список из архива в конец доски. На архивную доску списки не возвращаются.
+func (s *listService) Restore(ctx context.Context, listID, userID int) (*models.List, error) {
+	list, err := s.getListForUser(ctx, listID, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if list.BoardArchivedAt != nil {
+		return nil, ErrBoardArchived
+	}
+
+	maxPos, err := s.listRepo.GetMaxPositionForBoard(ctx, list.BoardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine list position: %w", err)
+	}
+	position := ordering.After(maxPos)
+	if err := s.listRepo.Restore(ctx, listID, position); err != nil {
+		return nil, apperr.NoRows(err, ErrListNotArchived)
+	}
+	list.ArchivedAt = nil
+	list.Position = position
+
+	s.invalidateBoardCache(ctx, list.BoardID)
+	broadcastEvent(s.broadcaster, list.BoardID, "LIST_RESTORED", list)
+	s.activity.Record(ctx, ActivityEntry{
+		BoardID: list.BoardID, ActorID: userID, Action: "list.restored", EntityType: "list", EntityID: list.ID, After: list,
+	})
+	return list, nil
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/ordering"
+	"notes-project/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestListService(listRepo *repository.MockListRepository, boardRepo *repository.MockBoardRepository,
+	broadcaster *MockBroadcaster) ListService {
+	return NewListService(listRepo, NewBoardPermissions(boardRepo), newNopActivityRecorder(), broadcaster,
+		func(ctx context.Context, boardID int) {})
+}
+
+var errInvalidPlacement = apperr.Validation("invalid_placement", "")
+
+func TestListService_Update(t *testing.T) {
+	archivedAt := time.Now()
+	tests := []struct {
+		name       string
+		list       models.List
+		role       models.BoardRole
+		updateErr  error
+		wantUpdate bool
+		wantErr    error
+	}{
+		{name: "member renames list", list: models.List{ID: 100, BoardID: 1000}, role: models.RoleMember, wantUpdate: true},
+		{name: "observer cannot rename", list: models.List{ID: 100, BoardID: 1000}, role: models.RoleObserver,
+			wantErr: ErrBoardRoleTooLow},
+		{name: "archived list", list: models.List{ID: 100, BoardID: 1000, ArchivedAt: &archivedAt}, role: models.RoleMember,
+			wantErr: ErrListArchived},
+		{name: "archived board", list: models.List{ID: 100, BoardID: 1000, BoardArchivedAt: &archivedAt}, role: models.RoleMember,
+			wantErr: ErrBoardArchived},
+		{name: "list deleted meanwhile", list: models.List{ID: 100, BoardID: 1000}, role: models.RoleMember,
+			updateErr: fmt.Errorf("list with id 100 not found: %w", sql.ErrNoRows), wantUpdate: true, wantErr: ErrListNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- ARRANGE ---
+			mockListRepo := new(repository.MockListRepository)
+			mockBoardRepo := new(repository.MockBoardRepository)
+			mockBroadcaster := new(MockBroadcaster)
+			listService := newTestListService(mockListRepo, mockBoardRepo, mockBroadcaster)
+
+			list := tt.list
+			mockListRepo.On("GetByID", mock.Anything, 100).Return(&list, nil).Once()
+			mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(tt.role, nil).Once()
+			if tt.wantUpdate {
+				mockListRepo.On("Update", mock.Anything, mock.MatchedBy(func(l *models.List) bool {
+					return l.ID == 100 && l.Title == "Done"
+				})).Return(tt.updateErr).Once()
+			}
+			if tt.wantErr == nil {
+				mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+			}
+
+			// --- ACT ---
+			updated, err := listService.Update(context.Background(), 100, 1, "Done")
+
+			// --- ASSERT ---
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Done", updated.Title)
+			}
+			if !tt.wantUpdate {
+				mockListRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+			mockListRepo.AssertExpectations(t)
+			mockBroadcaster.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListService_Move(t *testing.T) {
+	self, anchor, foreign := 100, 101, 201
+	position := 500.0
+	tests := []struct {
+		name         string
+		placement    Placement
+		archived     bool
+		setup        func(listRepo *repository.MockListRepository)
+		wantPosition float64
+		wantErr      error
+	}{
+		{name: "explicit position", placement: Placement{Position: &position}, wantPosition: 500},
+		{name: "end of board by default", placement: Placement{},
+			setup: func(listRepo *repository.MockListRepository) {
+				listRepo.On("GetMaxPositionForBoard", mock.Anything, 1000).Return(4096.0, nil).Once()
+			},
+			wantPosition: 4096 + ordering.Step},
+		{name: "after anchor", placement: Placement{AfterID: &anchor},
+			setup: func(listRepo *repository.MockListRepository) {
+				next := 3072.0
+				listRepo.On("GetByID", mock.Anything, anchor).Return(&models.List{ID: anchor, BoardID: 1000, Position: 2048}, nil).Once()
+				listRepo.On("GetNeighbourPosition", mock.Anything, 1000, 2048.0, false, self).Return(&next, nil).Once()
+			},
+			wantPosition: 2560},
+		{name: "dense neighbours are rebalanced first", placement: Placement{BeforeID: &anchor},
+			setup: func(listRepo *repository.MockListRepository) {
+				dense, spread := 2048.0-ordering.MinGap/2, 1024.0
+				listRepo.On("GetByID", mock.Anything, anchor).Return(&models.List{ID: anchor, BoardID: 1000, Position: 2048}, nil).Once()
+				listRepo.On("GetNeighbourPosition", mock.Anything, 1000, 2048.0, true, self).Return(&dense, nil).Once()
+				listRepo.On("Rebalance", mock.Anything, 1000, ordering.Step).Return(nil).Once()
+				listRepo.On("GetByID", mock.Anything, anchor).Return(&models.List{ID: anchor, BoardID: 1000, Position: 2048}, nil).Once()
+				listRepo.On("GetNeighbourPosition", mock.Anything, 1000, 2048.0, true, self).Return(&spread, nil).Once()
+			},
+			wantPosition: 1536},
+		{name: "anchor on another board", placement: Placement{AfterID: &foreign},
+			setup: func(listRepo *repository.MockListRepository) {
+				listRepo.On("GetByID", mock.Anything, foreign).Return(&models.List{ID: foreign, BoardID: 2000}, nil).Once()
+			},
+			wantErr: errInvalidPlacement},
+		{name: "relative to itself", placement: Placement{BeforeID: &self}, wantErr: errInvalidPlacement},
+		{name: "both anchor and position", placement: Placement{AfterID: &anchor, Position: &position},
+			wantErr: errInvalidPlacement},
+		{name: "archived list", placement: Placement{Position: &position}, archived: true, wantErr: ErrListArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- ARRANGE ---
+			mockListRepo := new(repository.MockListRepository)
+			mockBoardRepo := new(repository.MockBoardRepository)
+			mockBroadcaster := new(MockBroadcaster)
+			listService := newTestListService(mockListRepo, mockBoardRepo, mockBroadcaster)
+
+			list := &models.List{ID: self, BoardID: 1000, Position: 1024}
+			if tt.archived {
+				archivedAt := time.Now()
+				list.ArchivedAt = &archivedAt
+			}
+			mockListRepo.On("GetByID", mock.Anything, self).Return(list, nil).Maybe()
+			mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Maybe()
+			if tt.setup != nil {
+				tt.setup(mockListRepo)
+			}
+			if tt.wantErr == nil {
+				mockListRepo.On("Move", mock.Anything, self, tt.wantPosition).Return(nil).Once()
+				mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+			}
+
+			// --- ACT ---
+			err := listService.Move(context.Background(), self, tt.placement, 1)
+
+			// --- ASSERT ---
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockListRepo.AssertNotCalled(t, "Move", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockListRepo.AssertExpectations(t)
+			mockBroadcaster.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListService_Archive(t *testing.T) {
+	archivedAt := time.Now()
+	tests := []struct {
+		name        string
+		list        models.List
+		archiveErr  error
+		wantArchive bool
+		wantErr     error
+	}{
+		{name: "archives active list", list: models.List{ID: 100, BoardID: 1000}, wantArchive: true},
+		{name: "already archived", list: models.List{ID: 100, BoardID: 1000, ArchivedAt: &archivedAt},
+			archiveErr:  fmt.Errorf("list with id 100 not found or already archived: %w", sql.ErrNoRows),
+			wantArchive: true, wantErr: ErrListAlreadyArchived},
+		{name: "archived board", list: models.List{ID: 100, BoardID: 1000, BoardArchivedAt: &archivedAt},
+			wantErr: ErrBoardArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- ARRANGE ---
+			mockListRepo := new(repository.MockListRepository)
+			mockBoardRepo := new(repository.MockBoardRepository)
+			mockBroadcaster := new(MockBroadcaster)
+			listService := newTestListService(mockListRepo, mockBoardRepo, mockBroadcaster)
+
+			list := tt.list
+			mockListRepo.On("GetByID", mock.Anything, 100).Return(&list, nil).Once()
+			mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+			if tt.wantArchive {
+				mockListRepo.On("Archive", mock.Anything, 100).Return(tt.archiveErr).Once()
+			}
+			if tt.wantErr == nil {
+				mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+			}
+
+			// --- ACT ---
+			err := listService.Archive(context.Background(), 100, 1)
+
+			// --- ASSERT ---
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			if !tt.wantArchive {
+				mockListRepo.AssertNotCalled(t, "Archive", mock.Anything, mock.Anything)
+			}
+			mockListRepo.AssertExpectations(t)
+			mockBroadcaster.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListService_Restore(t *testing.T) {
+	archivedAt := time.Now()
+	tests := []struct {
+		name        string
+		list        models.List
+		restoreErr  error
+		wantRestore bool
+		wantErr     error
+	}{
+		{name: "restores to the end of the board", list: models.List{ID: 100, BoardID: 1000, ArchivedAt: &archivedAt},
+			wantRestore: true},
+		{name: "list is not archived", list: models.List{ID: 100, BoardID: 1000},
+			restoreErr:  fmt.Errorf("list with id 100 not found or not archived: %w", sql.ErrNoRows),
+			wantRestore: true, wantErr: ErrListNotArchived},
+		{name: "archived board", list: models.List{ID: 100, BoardID: 1000, ArchivedAt: &archivedAt, BoardArchivedAt: &archivedAt},
+			wantErr: ErrBoardArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --- ARRANGE ---
+			mockListRepo := new(repository.MockListRepository)
+			mockBoardRepo := new(repository.MockBoardRepository)
+			mockBroadcaster := new(MockBroadcaster)
+			listService := newTestListService(mockListRepo, mockBoardRepo, mockBroadcaster)
+
+			list := tt.list
+			mockListRepo.On("GetByID", mock.Anything, 100).Return(&list, nil).Once()
+			mockBoardRepo.On("GetMemberRole", mock.Anything, 1000, 1).Return(models.RoleMember, nil).Once()
+			if tt.wantRestore {
+				mockListRepo.On("GetMaxPositionForBoard", mock.Anything, 1000).Return(3072.0, nil).Once()
+				mockListRepo.On("Restore", mock.Anything, 100, 3072+ordering.Step).Return(tt.restoreErr).Once()
+			}
+			if tt.wantErr == nil {
+				mockBroadcaster.On("BroadcastToBoard", 1000, mock.Anything).Return().Once()
+			}
+
+			// --- ACT ---
+			restored, err := listService.Restore(context.Background(), 100, 1)
+
+			// --- ASSERT ---
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockBroadcaster.AssertNotCalled(t, "BroadcastToBoard", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, restored.ArchivedAt)
+				assert.Equal(t, 3072+ordering.Step, restored.Position)
+			}
+			if !tt.wantRestore {
+				mockListRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+			}
+			mockListRepo.AssertExpectations(t)
+			mockBroadcaster.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+)
+
+var (
+	ErrBoardAccessDenied = apperr.Forbidden("board_access_denied", "access denied to this board")
+	ErrBoardRoleTooLow   = apperr.Forbidden("board_role_too_low", "your board role is not allowed to perform this action")
+)
+
+// BoardPermissions - единая точка проверки прав на доске, которой пользуются все сервисы.
+// Чтение доступно наблюдателям, изменение содержимого - участникам, управление
+// участниками - администраторам, удаление доски - только владельцу.
+type BoardPermissions interface {
+	Require(ctx context.Context, boardID, userID int, minRole models.BoardRole) (models.BoardRole, error)
+	// RequireActive вдобавок к Require проверяет, что доска не в архиве: её содержимое менять нельзя.
+	RequireActive(ctx context.Context, boardID, userID int, minRole models.BoardRole) (models.BoardRole, error)
+}
+
+type boardPermissions struct {
+	boardRepo repository.BoardRepository
+}
+
+func NewBoardPermissions(boardRepo repository.BoardRepository) BoardPermissions {
+	return &boardPermissions{boardRepo: boardRepo}
+}
+
+func (p *boardPermissions) Require(ctx context.Context, boardID, userID int, minRole models.BoardRole) (models.BoardRole, error) {
+	role, err := p.boardRepo.GetMemberRole(ctx, boardID, userID)
+	if err != nil {
+		return "", fmt.Errorf("could not verify board permissions: %w", err)
+	}
+	if role == "" {
+		return "", ErrBoardAccessDenied
+	}
+	if !role.AtLeast(minRole) {
+		return role, apperr.Forbidden(ErrBoardRoleTooLow.Code,
+			"board role %q is not allowed to perform this action, %q required", role, minRole)
+	}
+	return role, nil
+}
+
+func (p *boardPermissions) RequireActive(ctx context.Context, boardID, userID int, minRole models.BoardRole) (models.BoardRole, error) {
+	role, err := p.Require(ctx, boardID, userID, minRole)
+	if err != nil {
+		return role, err
+	}
+	board, err := p.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return "", apperr.NoRows(err, ErrBoardNotFound)
+	}
+	if board.ArchivedAt != nil {
+		return "", ErrBoardArchived
+	}
+	return role, nil
+}
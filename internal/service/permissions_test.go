@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBoardPermissions_Require(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    models.BoardRole
+		minRole models.BoardRole
+		wantErr bool
+	}{
+		{name: "not a member", role: "", minRole: models.RoleObserver, wantErr: true},
+		{name: "observer can read", role: models.RoleObserver, minRole: models.RoleObserver},
+		{name: "observer cannot write", role: models.RoleObserver, minRole: models.RoleMember, wantErr: true},
+		{name: "member can write", role: models.RoleMember, minRole: models.RoleMember},
+		{name: "member cannot invite", role: models.RoleMember, minRole: models.RoleAdmin, wantErr: true},
+		{name: "admin can invite", role: models.RoleAdmin, minRole: models.RoleAdmin},
+		{name: "admin cannot delete board", role: models.RoleAdmin, minRole: models.RoleOwner, wantErr: true},
+		{name: "owner can do everything", role: models.RoleOwner, minRole: models.RoleOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBoardRepo := new(repository.MockBoardRepository)
+			mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 2).Return(tt.role, nil).Once()
+
+			_, err := NewBoardPermissions(mockBoardRepo).Require(context.Background(), 1, 2, tt.minRole)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockBoardRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"notes-project/internal/apperr"
+	"notes-project/internal/ordering"
+)
+
+// Placement описывает, куда поставить карточку или список: перед соседом, после соседа
+// или на явно заданную позицию. Если ничего не задано, элемент добавляется в конец.
+type Placement struct {
+	BeforeID *int
+	AfterID  *int
+	Position *float64
+}
+
+func (p Placement) validate() error {
+	set := 0
+	for _, isSet := range []bool{p.BeforeID != nil, p.AfterID != nil, p.Position != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return apperr.Validation("invalid_placement", "only one of before, after or position can be set")
+	}
+	return nil
+}
+
+// placeNextTo вычисляет позицию рядом с якорем. Если соседи стоят слишком плотно,
+// вызывает rebalance и повторяет расчёт с перечитанной позицией якоря.
+func placeNextTo(
+	ctx context.Context,
+	before bool,
+	anchorPosition func(ctx context.Context) (float64, error),
+	neighbourPosition func(ctx context.Context, position float64, before bool) (*float64, error),
+	rebalance func(ctx context.Context) error,
+) (float64, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		anchor, err := anchorPosition(ctx)
+		if err != nil {
+			return 0, err
+		}
+		neighbour, err := neighbourPosition(ctx, anchor, before)
+		if err != nil {
+			return 0, err
+		}
+
+		var position float64
+		var ok bool
+		if before {
+			position, ok = ordering.Between(neighbour, &anchor)
+		} else {
+			position, ok = ordering.Between(&anchor, neighbour)
+		}
+		if ok {
+			return position, nil
+		}
+
+		if err := rebalance(ctx); err != nil {
+			return 0, fmt.Errorf("could not rebalance positions: %w", err)
+		}
+	}
+	return 0, fmt.Errorf("could not find a free position")
+}
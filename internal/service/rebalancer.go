@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log"
+	"notes-project/internal/ordering"
+	"notes-project/internal/repository"
+	"time"
+)
+
+// rebalanceBatchSize ограничивает число списков и досок, обрабатываемых за один проход.
+const rebalanceBatchSize = 100
+
+// PositionRebalancer периодически разрежает позиции карточек и списков, которые
+// после многих вставок в одно место стали стоять слишком плотно.
+type PositionRebalancer struct {
+	cardRepo             repository.CardRepository
+	listRepo             repository.ListRepository
+	invalidateBoardCache CacheInvalidator
+	interval             time.Duration
+}
+
+func NewPositionRebalancer(
+	cardRepo repository.CardRepository,
+	listRepo repository.ListRepository,
+	cacheInvalidator CacheInvalidator,
+	interval time.Duration) *PositionRebalancer {
+	return &PositionRebalancer{
+		cardRepo:             cardRepo,
+		listRepo:             listRepo,
+		invalidateBoardCache: cacheInvalidator,
+		interval:             interval,
+	}
+}
+
+func (r *PositionRebalancer) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RebalanceOnce(ctx)
+		}
+	}
+}
+
+func (r *PositionRebalancer) RebalanceOnce(ctx context.Context) {
+	listIDs, err := r.cardRepo.GetDenseListIDs(ctx, ordering.RebalanceGap, rebalanceBatchSize)
+	if err != nil {
+		log.Printf("Rebalancer: could not find dense lists: %v", err)
+	}
+	for _, listID := range listIDs {
+		if err := r.cardRepo.Rebalance(ctx, listID, ordering.Step); err != nil {
+			log.Printf("Rebalancer: could not rebalance cards in list %d: %v", listID, err)
+			continue
+		}
+		if list, err := r.listRepo.GetByID(ctx, listID); err == nil {
+			r.invalidateBoardCache(ctx, list.BoardID)
+		}
+	}
+
+	boardIDs, err := r.listRepo.GetDenseBoardIDs(ctx, ordering.RebalanceGap, rebalanceBatchSize)
+	if err != nil {
+		log.Printf("Rebalancer: could not find dense boards: %v", err)
+	}
+	for _, boardID := range boardIDs {
+		if err := r.listRepo.Rebalance(ctx, boardID, ordering.Step); err != nil {
+			log.Printf("Rebalancer: could not rebalance lists on board %d: %v", boardID, err)
+			continue
+		}
+		r.invalidateBoardCache(ctx, boardID)
+	}
+
+	if len(listIDs) > 0 || len(boardIDs) > 0 {
+		log.Printf("Rebalancer: rebalanced %d list(s) and %d board(s)", len(listIDs), len(boardIDs))
+	}
+}
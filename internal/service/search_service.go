@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	maxSearchQueryLen  = 200
+)
+
+type SearchService interface {
+	Search(ctx context.Context, userID int, filters models.SearchFilters) ([]models.SearchResult, error)
+}
+
+type searchService struct {
+	repo repository.SearchRepository
+}
+
+func NewSearchService(repo repository.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+// Search проверяет фильтры и выполняет поиск. Права доступа проверяются в самом запросе:
+// в выдачу попадают только доски, где пользователь владелец или участник.
+func (s *searchService) Search(ctx context.Context, userID int, filters models.SearchFilters) ([]models.SearchResult, error) {
+	filters.Query = strings.TrimSpace(filters.Query)
+	if filters.Query == "" {
+		return nil, apperr.Validation("invalid_search", "search query must not be empty")
+	}
+	if utf8.RuneCountInString(filters.Query) > maxSearchQueryLen {
+		return nil, apperr.Validation("invalid_search", "search query must be at most %d characters", maxSearchQueryLen)
+	}
+	if filters.DueFrom != nil && filters.DueTo != nil && filters.DueFrom.After(*filters.DueTo) {
+		return nil, apperr.Validation("invalid_search", "due_from cannot be after due_to")
+	}
+	switch {
+	case filters.Limit == 0:
+		filters.Limit = DefaultSearchLimit
+	case filters.Limit < 0 || filters.Limit > MaxSearchLimit:
+		return nil, apperr.Validation("invalid_search", "limit must be between 1 and %d", MaxSearchLimit)
+	}
+	return s.repo.Search(ctx, userID, filters)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchService_Search_NormalizesFilters(t *testing.T) {
+	// --- ARRANGE ---
+	mockSearchRepo := new(repository.MockSearchRepository)
+	searchService := NewSearchService(mockSearchRepo)
+	labelID := 3
+
+	mockSearchRepo.On("Search", mock.Anything, 1, mock.MatchedBy(func(f models.SearchFilters) bool {
+		return f.Query == "release notes" && f.Limit == DefaultSearchLimit && f.CardOnly()
+	})).Return([]models.SearchResult{{Type: "card", ID: 10}}, nil).Once()
+
+	// --- ACT ---
+	results, err := searchService.Search(context.Background(), 1, models.SearchFilters{Query: "  release notes ", LabelID: &labelID})
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	mockSearchRepo.AssertExpectations(t)
+}
+
+func TestSearchService_Search_RejectsInvalidFilters(t *testing.T) {
+	mockSearchRepo := new(repository.MockSearchRepository)
+	searchService := NewSearchService(mockSearchRepo)
+	from := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	_, err := searchService.Search(context.Background(), 1, models.SearchFilters{Query: "   "})
+	assert.Error(t, err)
+	_, err = searchService.Search(context.Background(), 1, models.SearchFilters{Query: "x", DueFrom: &from, DueTo: &to})
+	assert.Error(t, err)
+	_, err = searchService.Search(context.Background(), 1, models.SearchFilters{Query: "x", Limit: MaxSearchLimit + 1})
+	assert.Error(t, err)
+
+	mockSearchRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenDenylist хранит отозванные access-токены до истечения их срока: отдельные токены по jti
+// и целые сессии по семейству refresh-токенов.
+type TokenDenylist interface {
+	RevokeToken(ctx context.Context, tokenID string, until time.Time) error
+	RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, tokenID, familyID string) (bool, error)
+}
+
+type redisTokenDenylist struct {
+	rdb *redis.Client
+}
+
+func NewRedisTokenDenylist(rdb *redis.Client) TokenDenylist {
+	return &redisTokenDenylist{rdb: rdb}
+}
+
+func (d *redisTokenDenylist) RevokeToken(ctx context.Context, tokenID string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := d.rdb.Set(ctx, "auth:revoked:jti:"+tokenID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("could not revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeFamily держит запись не дольше ttl: к этому времени истекут все access-токены семейства.
+func (d *redisTokenDenylist) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	if err := d.rdb.Set(ctx, "auth:revoked:family:"+familyID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("could not revoke token family: %w", err)
+	}
+	return nil
+}
+
+func (d *redisTokenDenylist) IsRevoked(ctx context.Context, tokenID, familyID string) (bool, error) {
+	n, err := d.rdb.Exists(ctx, "auth:revoked:jti:"+tokenID, "auth:revoked:family:"+familyID).Result()
+	if err != nil {
+		return false, fmt.Errorf("could not check token denylist: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const accessTokenType = "access"
+
+var (
+	ErrInvalidAccessToken  = apperr.Unauthorized("invalid_token", "invalid token")
+	ErrAccessTokenRevoked  = apperr.Unauthorized("token_revoked", "token has been revoked")
+	ErrInvalidRefreshToken = apperr.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+	// ErrRefreshTokenReused - предъявлен уже сменённый refresh-токен. Скорее всего, его украли,
+	// поэтому вся сессия отзывается и пользователю нужно войти заново.
+	ErrRefreshTokenReused = apperr.Unauthorized("refresh_token_reused", "refresh token reuse detected, session revoked")
+)
+
+// AccessTokenAuthenticator проверяет access-токены; им пользуется AuthMiddleware.
+type AccessTokenAuthenticator interface {
+	Authenticate(ctx context.Context, accessToken string) (*models.AccessClaims, error)
+}
+
+type TokenService interface {
+	AccessTokenAuthenticator
+	Issue(ctx context.Context, userID int) (*models.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error)
+	Logout(ctx context.Context, claims *models.AccessClaims) error
+	LogoutAll(ctx context.Context, userID int) error
+}
+
+type tokenService struct {
+	repo       repository.RefreshTokenRepository
+	denylist   TokenDenylist
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenService(
+	repo repository.RefreshTokenRepository,
+	denylist TokenDenylist,
+	secret []byte,
+	accessTTL time.Duration,
+	refreshTTL time.Duration) TokenService {
+	return &tokenService{
+		repo:       repo,
+		denylist:   denylist,
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// Issue открывает новую сессию: новое семейство refresh-токенов и первую пару токенов.
+func (s *tokenService) Issue(ctx context.Context, userID int) (*models.TokenPair, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	token := &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: s.now().Add(s.refreshTTL),
+	}
+	if err := s.repo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+	return s.pair(userID, familyID, refreshToken)
+}
+
+// Refresh меняет refresh-токен на новую пару. Старый токен после этого недействителен.
+func (s *tokenService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	current, err := s.repo.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		// Только отсутствующий токен означает повторный вход; сбой базы не должен разлогинивать клиента.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("could not load refresh token: %w", err)
+	}
+	if current.RevokedAt != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.RotatedAt != nil {
+		return nil, s.revokeStolenFamily(ctx, current)
+	}
+	if !s.now().Before(current.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	nextToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	next := &models.RefreshToken{
+		UserID:    current.UserID,
+		FamilyID:  current.FamilyID,
+		TokenHash: hashRefreshToken(nextToken),
+		ExpiresAt: s.now().Add(s.refreshTTL),
+	}
+	rotated, err := s.repo.Rotate(ctx, current.ID, next)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Токен сменили между чтением и обновлением - это тоже повторное использование.
+		return nil, s.revokeStolenFamily(ctx, current)
+	}
+	return s.pair(current.UserID, current.FamilyID, nextToken)
+}
+
+func (s *tokenService) revokeStolenFamily(ctx context.Context, token *models.RefreshToken) error {
+	log.Printf("Auth: refresh token reuse for user %d, revoking family %s", token.UserID, token.FamilyID)
+	if err := s.revokeFamily(ctx, token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout завершает сессию, которой принадлежит access-токен: сам токен попадает в denylist,
+// а семейство refresh-токенов отзывается.
+func (s *tokenService) Logout(ctx context.Context, claims *models.AccessClaims) error {
+	if err := s.denylist.RevokeToken(ctx, claims.TokenID, claims.ExpiresAt); err != nil {
+		return err
+	}
+	return s.revokeFamily(ctx, claims.FamilyID)
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах.
+func (s *tokenService) LogoutAll(ctx context.Context, userID int) error {
+	families, err := s.repo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, familyID := range families {
+		if err := s.denylist.RevokeFamily(ctx, familyID, s.accessTTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *tokenService) revokeFamily(ctx context.Context, familyID string) error {
+	if err := s.repo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return s.denylist.RevokeFamily(ctx, familyID, s.accessTTL)
+}
+
+// Authenticate проверяет подпись, тип и срок access-токена, затем denylist.
+// Ошибка denylist возвращается как есть, чтобы её можно было отличить от отказа в доступе.
+func (s *tokenService) Authenticate(ctx context.Context, accessToken string) (*models.AccessClaims, error) {
+	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	}, jwt.WithTimeFunc(s.now))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidAccessToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != accessTokenType {
+		return nil, ErrInvalidAccessToken
+	}
+	userID, ok := claims["sub"].(float64)
+	tokenID, _ := claims["jti"].(string)
+	familyID, _ := claims["fam"].(string)
+	expiresAt, err := claims.GetExpirationTime()
+	if !ok || tokenID == "" || familyID == "" || err != nil || expiresAt == nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	revoked, err := s.denylist.IsRevoked(ctx, tokenID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrAccessTokenRevoked
+	}
+	return &models.AccessClaims{
+		UserID:    int(userID),
+		TokenID:   tokenID,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt.Time,
+	}, nil
+}
+
+func (s *tokenService) pair(userID int, familyID, refreshToken string) (*models.TokenPair, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"typ": accessTokenType,
+		"jti": tokenID,
+		"fam": familyID,
+		"iat": now.Unix(),
+		"exp": now.Add(s.accessTTL).Unix(),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+	}, nil
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("could not generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashRefreshToken - токены случайные и длинные, поэтому хватает SHA-256 без соли.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTokenDenylist struct {
+	mock.Mock
+}
+
+func (m *MockTokenDenylist) RevokeToken(ctx context.Context, tokenID string, until time.Time) error {
+	args := m.Called(ctx, tokenID, until)
+	return args.Error(0)
+}
+
+func (m *MockTokenDenylist) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	args := m.Called(ctx, familyID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenDenylist) IsRevoked(ctx context.Context, tokenID, familyID string) (bool, error) {
+	args := m.Called(ctx, tokenID, familyID)
+	return args.Bool(0), args.Error(1)
+}
+
+var testTokenNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestTokenService(repo repository.RefreshTokenRepository, denylist TokenDenylist) *tokenService {
+	s := NewTokenService(repo, denylist, []byte("test-secret"), 15*time.Minute, 24*time.Hour).(*tokenService)
+	s.now = func() time.Time { return testTokenNow }
+	return s
+}
+
+func TestTokenService_IssueAndAuthenticate(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	tokenService := newTestTokenService(mockRepo, mockDenylist)
+
+	var stored *models.RefreshToken
+	mockRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.RefreshToken)
+	}).Return(nil).Once()
+	mockDenylist.On("IsRevoked", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Once()
+
+	// --- ACT ---
+	pair, err := tokenService.Issue(context.Background(), 7)
+	assert.NoError(t, err)
+	claims, err := tokenService.Authenticate(context.Background(), pair.AccessToken)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, 7, claims.UserID)
+	assert.Equal(t, stored.FamilyID, claims.FamilyID)
+	assert.Equal(t, testTokenNow.Add(15*time.Minute), claims.ExpiresAt.UTC())
+	assert.Equal(t, 900, pair.ExpiresIn)
+	// В базе лежит только хеш refresh-токена.
+	assert.Equal(t, hashRefreshToken(pair.RefreshToken), stored.TokenHash)
+	assert.NotEqual(t, pair.RefreshToken, stored.TokenHash)
+	assert.Equal(t, testTokenNow.Add(24*time.Hour), stored.ExpiresAt)
+	mockDenylist.AssertCalled(t, "IsRevoked", mock.Anything, claims.TokenID, claims.FamilyID)
+}
+
+func TestTokenService_Authenticate_Rejects(t *testing.T) {
+	mockDenylist := new(MockTokenDenylist)
+	tokenService := newTestTokenService(new(repository.MockRefreshTokenRepository), mockDenylist)
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		assert.NoError(t, err)
+		return token
+	}
+	valid := jwt.MapClaims{"sub": 7, "typ": "access", "jti": "j1", "fam": "f1", "exp": testTokenNow.Add(time.Minute).Unix()}
+
+	// Токен приглашения подписан тем же секретом, но для входа не годится.
+	_, err := tokenService.Authenticate(context.Background(), sign(jwt.MapClaims{"typ": "invitation", "jti": "x",
+		"exp": testTokenNow.Add(time.Hour).Unix()}))
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+
+	// Старый формат без jti и семейства.
+	_, err = tokenService.Authenticate(context.Background(), sign(jwt.MapClaims{"sub": 7, "exp": testTokenNow.Add(time.Hour).Unix()}))
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+
+	expired := jwt.MapClaims{"sub": 7, "typ": "access", "jti": "j1", "fam": "f1", "exp": testTokenNow.Add(-time.Minute).Unix()}
+	_, err = tokenService.Authenticate(context.Background(), sign(expired))
+	assert.ErrorIs(t, err, ErrInvalidAccessToken)
+
+	mockDenylist.On("IsRevoked", mock.Anything, "j1", "f1").Return(true, nil).Once()
+	_, err = tokenService.Authenticate(context.Background(), sign(valid))
+	assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+	outage := errors.New("redis is down")
+	mockDenylist.On("IsRevoked", mock.Anything, "j1", "f1").Return(false, outage).Once()
+	_, err = tokenService.Authenticate(context.Background(), sign(valid))
+	assert.ErrorIs(t, err, outage)
+}
+
+func TestTokenService_Refresh_Rotates(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	tokenService := newTestTokenService(mockRepo, new(MockTokenDenylist))
+	current := &models.RefreshToken{ID: 3, UserID: 7, FamilyID: "fam", ExpiresAt: testTokenNow.Add(time.Hour)}
+
+	mockRepo.On("GetByHash", mock.Anything, hashRefreshToken("old-token")).Return(current, nil).Once()
+	mockRepo.On("Rotate", mock.Anything, int64(3), mock.MatchedBy(func(next *models.RefreshToken) bool {
+		return next.UserID == 7 && next.FamilyID == "fam" && next.TokenHash != hashRefreshToken("old-token")
+	})).Return(true, nil).Once()
+
+	// --- ACT ---
+	pair, err := tokenService.Refresh(context.Background(), "old-token")
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.NotEqual(t, "old-token", pair.RefreshToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTokenService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	tokenService := newTestTokenService(mockRepo, mockDenylist)
+	rotatedAt := testTokenNow.Add(-time.Minute)
+	stolen := &models.RefreshToken{ID: 3, UserID: 7, FamilyID: "fam", ExpiresAt: testTokenNow.Add(time.Hour), RotatedAt: &rotatedAt}
+
+	mockRepo.On("GetByHash", mock.Anything, hashRefreshToken("stolen")).Return(stolen, nil).Once()
+	mockRepo.On("RevokeFamily", mock.Anything, "fam").Return(nil).Once()
+	mockDenylist.On("RevokeFamily", mock.Anything, "fam", 15*time.Minute).Return(nil).Once()
+
+	// --- ACT ---
+	_, err := tokenService.Refresh(context.Background(), "stolen")
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	mockRepo.AssertExpectations(t)
+	mockDenylist.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTokenService_Refresh_LostRaceCountsAsReuse(t *testing.T) {
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	tokenService := newTestTokenService(mockRepo, mockDenylist)
+	current := &models.RefreshToken{ID: 3, UserID: 7, FamilyID: "fam", ExpiresAt: testTokenNow.Add(time.Hour)}
+
+	mockRepo.On("GetByHash", mock.Anything, mock.Anything).Return(current, nil).Once()
+	mockRepo.On("Rotate", mock.Anything, int64(3), mock.Anything).Return(false, nil).Once()
+	mockRepo.On("RevokeFamily", mock.Anything, "fam").Return(nil).Once()
+	mockDenylist.On("RevokeFamily", mock.Anything, "fam", mock.Anything).Return(nil).Once()
+
+	_, err := tokenService.Refresh(context.Background(), "token")
+
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	mockDenylist.AssertExpectations(t)
+}
+
+func TestTokenService_Refresh_Invalid(t *testing.T) {
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	tokenService := newTestTokenService(mockRepo, new(MockTokenDenylist))
+	revokedAt := testTokenNow.Add(-time.Hour)
+
+	mockRepo.On("GetByHash", mock.Anything, hashRefreshToken("unknown")).
+		Return(nil, fmt.Errorf("refreshTokenRepository.GetByHash: %w", sql.ErrNoRows)).Once()
+	mockRepo.On("GetByHash", mock.Anything, hashRefreshToken("expired")).
+		Return(&models.RefreshToken{ID: 1, ExpiresAt: testTokenNow}, nil).Once()
+	mockRepo.On("GetByHash", mock.Anything, hashRefreshToken("revoked")).
+		Return(&models.RefreshToken{ID: 2, ExpiresAt: testTokenNow.Add(time.Hour), RevokedAt: &revokedAt}, nil).Once()
+
+	for _, token := range []string{"unknown", "expired", "revoked"} {
+		_, err := tokenService.Refresh(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken, token)
+	}
+}
+
+func TestTokenService_Refresh_DatabaseErrorIsNotUnauthorized(t *testing.T) {
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	tokenService := newTestTokenService(mockRepo, new(MockTokenDenylist))
+	outage := errors.New("connection refused")
+	mockRepo.On("GetByHash", mock.Anything, hashRefreshToken("valid")).Return(nil, outage).Once()
+
+	_, err := tokenService.Refresh(context.Background(), "valid")
+
+	// Клиент не должен терять сессию из-за недоступной базы: это 500, а не 401.
+	assert.ErrorIs(t, err, outage)
+	assert.NotErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestTokenService_Logout(t *testing.T) {
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	tokenService := newTestTokenService(mockRepo, mockDenylist)
+	claims := &models.AccessClaims{UserID: 7, TokenID: "j1", FamilyID: "fam", ExpiresAt: testTokenNow.Add(time.Minute)}
+
+	mockDenylist.On("RevokeToken", mock.Anything, "j1", claims.ExpiresAt).Return(nil).Once()
+	mockRepo.On("RevokeFamily", mock.Anything, "fam").Return(nil).Once()
+	mockDenylist.On("RevokeFamily", mock.Anything, "fam", 15*time.Minute).Return(nil).Once()
+
+	err := tokenService.Logout(context.Background(), claims)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockDenylist.AssertExpectations(t)
+}
+
+func TestTokenService_LogoutAll(t *testing.T) {
+	mockRepo := new(repository.MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	tokenService := newTestTokenService(mockRepo, mockDenylist)
+
+	mockRepo.On("RevokeAllForUser", mock.Anything, 7).Return([]string{"phone", "laptop"}, nil).Once()
+	mockDenylist.On("RevokeFamily", mock.Anything, "phone", 15*time.Minute).Return(nil).Once()
+	mockDenylist.On("RevokeFamily", mock.Anything, "laptop", 15*time.Minute).Return(nil).Once()
+
+	err := tokenService.LogoutAll(context.Background(), 7)
+
+	assert.NoError(t, err)
+	mockDenylist.AssertExpectations(t)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"notes-project/internal/models"
+	"strings"
+	"time"
+)
+
+// Поля JSON-выгрузки доски Trello (меню доски -> Print and export -> Export as JSON),
+// которые переносятся при импорте. Чек-листы, вложения и участники Trello не переносятся:
+// email участников в выгрузку не попадают.
+type trelloBoard struct {
+	Name   string        `json:"name"`
+	Lists  []trelloList  `json:"lists"`
+	Cards  []trelloCard  `json:"cards"`
+	Labels []trelloLabel `json:"labels"`
+}
+
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloCard struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Desc        string     `json:"desc"`
+	IDList      string     `json:"idList"`
+	Closed      bool       `json:"closed"`
+	Pos         float64    `json:"pos"`
+	Start       *time.Time `json:"start"`
+	Due         *time.Time `json:"due"`
+	DueComplete bool       `json:"dueComplete"`
+	IDLabels    []string   `json:"idLabels"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// trelloColors - цвета меток Trello. Оттенки вроде green_dark сводятся к основному цвету.
+var trelloColors = map[string]string{
+	"green":  "#61bd4f",
+	"yellow": "#f2d600",
+	"orange": "#ff9f1a",
+	"red":    "#eb5a46",
+	"purple": "#c377e0",
+	"blue":   "#0079bf",
+	"sky":    "#00c2e0",
+	"lime":   "#51e898",
+	"pink":   "#ff78cb",
+	"black":  "#344563",
+}
+
+const trelloDefaultColor = "#b3bac5"
+
+func parseTrelloBoard(document []byte) (*models.BoardExport, error) {
+	var trello trelloBoard
+	if err := json.Unmarshal(document, &trello); err != nil {
+		return nil, fmt.Errorf("invalid Trello export: %w", err)
+	}
+	if trello.Name == "" || trello.Lists == nil {
+		return nil, fmt.Errorf("unrecognised import format: expected a board export or a Trello JSON export")
+	}
+
+	data := &models.BoardExport{
+		Format:  models.BoardExportFormat,
+		Version: models.BoardExportVersion,
+		Name:    trello.Name,
+	}
+
+	// У меток Trello имя необязательно: безымянную метку называем по цвету.
+	labelNames := make(map[string]string, len(trello.Labels))
+	for _, label := range trello.Labels {
+		name := strings.TrimSpace(label.Name)
+		if name == "" {
+			name = label.Color
+		}
+		if name == "" {
+			name = "label"
+		}
+		labelNames[label.ID] = name
+		data.Labels = append(data.Labels, models.ExportedLabel{Name: name, Color: trelloColor(label.Color)})
+	}
+
+	listIndex := make(map[string]int, len(trello.Lists))
+	for _, list := range trello.Lists {
+		listIndex[list.ID] = len(data.Lists)
+		data.Lists = append(data.Lists, models.ExportedList{
+			Title:    list.Name,
+			Position: list.Pos,
+			Archived: list.Closed,
+			Cards:    []models.ExportedCard{},
+		})
+	}
+
+	for _, card := range trello.Cards {
+		i, ok := listIndex[card.IDList]
+		if !ok {
+			return nil, fmt.Errorf("card %q belongs to unknown list %q", card.Name, card.IDList)
+		}
+		var labels []string
+		for _, labelID := range card.IDLabels {
+			if name, ok := labelNames[labelID]; ok {
+				labels = append(labels, name)
+			}
+		}
+		data.Lists[i].Cards = append(data.Lists[i].Cards, models.ExportedCard{
+			Title:       card.Name,
+			Description: card.Desc,
+			Position:    card.Pos,
+			StartAt:     card.Start,
+			DueAt:       card.Due,
+			Completed:   card.DueComplete,
+			Archived:    card.Closed,
+			Labels:      labels,
+		})
+	}
+	return data, nil
+}
+
+func trelloColor(color string) string {
+	base, _, _ := strings.Cut(color, "_")
+	if hex, ok := trelloColors[base]; ok {
+		return hex
+	}
+	return trelloDefaultColor
+}
@@ -2,79 +2,254 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"notes-project/internal/apperr"
 	"notes-project/internal/models"
 	"notes-project/internal/repository"
-	"os"
-	"time"
+	"notes-project/internal/storage"
+	"notes-project/internal/validation"
+	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrNotSystemAdmin = apperr.Forbidden("admin_required", "admin access required")
+	ErrUserNotFound   = apperr.NotFound("user_not_found", "user not found")
+	// ErrOwnAdminFlag - администратор не может снять флаг сам с себя, чтобы случайно не остаться без администраторов.
+	ErrOwnAdminFlag       = apperr.Validation("own_admin_flag", "admins cannot change their own admin flag")
+	ErrEmailTaken         = apperr.Conflict("email_taken", "email is already registered")
+	ErrInvalidCredentials = apperr.Unauthorized("invalid_credentials", "invalid credentials")
+)
+
 type UserService interface {
 	Register(ctx context.Context, user *models.User) error
-	Login(ctx context.Context, email, password string) (string, error)
-	GetAll(ctx context.Context) ([]models.User, error)
-	GetByID(ctx context.Context, id int) (*models.User, error)
-	Update(ctx context.Context, user *models.User) error
-	Delete(ctx context.Context, id int) error
+	Login(ctx context.Context, email, password string) (*models.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error)
+	Logout(ctx context.Context, claims *models.AccessClaims) error
+	LogoutAll(ctx context.Context, userID int) error
+	GetAll(ctx context.Context, actorID int) ([]models.User, error)
+	GetByID(ctx context.Context, actorID, userID int) (user *models.User, full bool, err error)
+	Update(ctx context.Context, actorID, userID int, update models.UserUpdate) (*models.User, error)
+	Delete(ctx context.Context, actorID, userID int) (*models.UserDeletion, error)
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo            repository.UserRepository
+	accounts        AccountService
+	tokens          TokenService
+	store           storage.BlobStore
+	broadcaster     Broadcaster
+	invalidateCache CacheInvalidator
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(
+	repo repository.UserRepository,
+	accounts AccountService,
+	tokens TokenService,
+	store storage.BlobStore,
+	broadcaster Broadcaster,
+	invalidateCache CacheInvalidator) UserService {
+	return &userService{
+		repo:            repo,
+		accounts:        accounts,
+		tokens:          tokens,
+		store:           store,
+		broadcaster:     broadcaster,
+		invalidateCache: invalidateCache,
+	}
 }
 
+// Register проверяет все поля сразу и возвращает validation.Errors со списком ошибок.
 func (s *userService) Register(ctx context.Context, user *models.User) error {
+	user.Email = validation.NormalizeEmail(user.Email)
+	user.Name = strings.TrimSpace(user.Name)
+	v := validation.New()
+	v.Email("email", user.Email)
+	v.Password("password", user.Password)
+	v.Name("name", user.Name)
+	v.Age("age", user.Age)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 	user.PasswordHash = string(hashedPassword)
+	// Администратором нельзя зарегистрироваться, флаг выставляется только отдельно.
+	user.IsAdmin = false
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return ErrEmailTaken
+		}
+		return err
+	}
 
-	return s.repo.Create(ctx, user)
+	// Приглашения на этот email принимаются только после подтверждения почты, см. VerifyEmail.
+	// Аккаунт создан и без письма: ссылку можно запросить повторно.
+	if err := s.accounts.SendVerification(ctx, user); err != nil {
+		log.Printf("could not send verification email to user %d: %v", user.ID, err)
+	}
+	return nil
 }
 
-func (s *userService) Login(ctx context.Context, email, password string) (string, error) {
-	user, err := s.repo.GetByEmail(ctx, email)
+func (s *userService) Login(ctx context.Context, email, password string) (*models.TokenPair, error) {
+	user, err := s.repo.GetByEmail(ctx, validation.NormalizeEmail(email))
 	if err != nil {
-		return "", fmt.Errorf("invalid credentials")
+		return nil, apperr.NoRows(err, ErrInvalidCredentials)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", fmt.Errorf("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.tokens.Issue(ctx, user.ID)
+}
+
+func (s *userService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	return s.tokens.Refresh(ctx, refreshToken)
+}
+
+func (s *userService) Logout(ctx context.Context, claims *models.AccessClaims) error {
+	return s.tokens.Logout(ctx, claims)
+}
+
+// LogoutAll завершает сессии пользователя на всех устройствах, включая текущую.
+func (s *userService) LogoutAll(ctx context.Context, userID int) error {
+	return s.tokens.LogoutAll(ctx, userID)
+}
+
+// GetAll отдаёт все аккаунты вместе с email, поэтому доступен только администраторам.
+func (s *userService) GetAll(ctx context.Context, actorID int) ([]models.User, error) {
+	if _, err := s.authorize(ctx, actorID, 0); err != nil {
+		return nil, err
 	}
+	return s.repo.GetAll(ctx)
+}
 
-	claims := jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+// GetByID возвращает пользователя и признак full: сам пользователь и администраторы видят
+// профиль целиком, остальным отдаётся только публичная часть.
+func (s *userService) GetByID(ctx context.Context, actorID, userID int) (*models.User, bool, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if actorID == userID {
+		return user, true, nil
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secretKey := os.Getenv("JWT_SECRET_KEY")
-	tokenString, err := token.SignedString([]byte(secretKey))
+	actor, err := s.getUser(ctx, actorID)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, false, err
 	}
-
-	return tokenString, nil
+	return user, actor.IsAdmin, nil
 }
 
-func (s *userService) GetAll(ctx context.Context) ([]models.User, error) {
-	return s.repo.GetAll(ctx)
+func (s *userService) Update(ctx context.Context, actorID, userID int, update models.UserUpdate) (*models.User, error) {
+	actor, err := s.authorize(ctx, actorID, userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Поля проверяются до любых изменений, чтобы запрос не применился наполовину.
+	v := validation.New()
+	if update.Name != nil {
+		user.Name = strings.TrimSpace(*update.Name)
+		v.Name("name", user.Name)
+	}
+	if update.Age != nil {
+		user.Age = update.Age
+		v.Age("age", user.Age)
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if update.IsAdmin != nil && *update.IsAdmin != user.IsAdmin {
+		if !actor.IsAdmin {
+			return nil, ErrNotSystemAdmin
+		}
+		if actorID == userID {
+			return nil, ErrOwnAdminFlag
+		}
+		if err := s.repo.SetAdmin(ctx, userID, *update.IsAdmin); err != nil {
+			return nil, apperr.NoRows(err, ErrUserNotFound)
+		}
+		user.IsAdmin = *update.IsAdmin
+	}
+	if update.Name == nil && update.Age == nil {
+		return user, nil
+	}
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, apperr.NoRows(err, ErrUserNotFound)
+	}
+	return user, nil
 }
 
-func (s *userService) GetByID(ctx context.Context, id int) (*models.User, error) {
-	return s.repo.GetByID(ctx, id)
+// Delete удаляет аккаунт: свой - любой пользователь, чужой - только администратор.
+// Сессии удалённого пользователя отзываются до удаления, его доски с другими участниками
+// переходят преемнику, а доски без участников удаляются.
+func (s *userService) Delete(ctx context.Context, actorID, userID int) (*models.UserDeletion, error) {
+	if _, err := s.authorize(ctx, actorID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.tokens.LogoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	deletion, err := s.repo.Delete(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range deletion.TransferredBoards {
+		s.invalidateCache(ctx, transfer.BoardID)
+		broadcastEvent(s.broadcaster, transfer.BoardID, "BOARD_TRANSFERRED", map[string]interface{}{
+			"previous_owner_id": userID,
+			"owner_id":          transfer.NewOwnerID,
+		})
+		s.broadcaster.DisconnectUser(transfer.BoardID, userID)
+	}
+	for _, boardID := range deletion.MemberBoards {
+		s.invalidateCache(ctx, boardID)
+		broadcastEvent(s.broadcaster, boardID, "MEMBER_REMOVED", map[string]interface{}{"user_id": userID})
+		s.broadcaster.DisconnectUser(boardID, userID)
+	}
+	for _, boardID := range deletion.DeletedBoards {
+		s.invalidateCache(ctx, boardID)
+	}
+	deleteBlobs(ctx, s.store, deletion.BlobKeys)
+	return deletion, nil
 }
 
-func (s *userService) Update(ctx context.Context, user *models.User) error {
-	return s.repo.Update(ctx, user)
+// authorize пропускает действие над аккаунтом userID, если его выполняет сам пользователь
+// или администратор. userID = 0 означает действие, доступное только администраторам.
+func (s *userService) authorize(ctx context.Context, actorID, userID int) (*models.User, error) {
+	actor, err := s.getUser(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != userID && !actor.IsAdmin {
+		return nil, ErrNotSystemAdmin
+	}
+	return actor, nil
 }
 
-func (s *userService) Delete(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+func (s *userService) getUser(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrUserNotFound)
+	}
+	return user, nil
 }
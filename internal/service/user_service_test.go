@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"notes-project/internal/validation"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestUserService(repo repository.UserRepository, tokens TokenService, broadcaster Broadcaster) UserService {
+	return NewUserService(repo, nil, tokens, nil, broadcaster, func(ctx context.Context, boardID int) {})
+}
+
+func TestUserService_GetAll_RequiresAdmin(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+
+	mockRepo.On("GetByID", mock.Anything, 10).Return(&models.User{ID: 10}, nil)
+
+	// --- ACT ---
+	users, err := userService.GetAll(context.Background(), 10)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrNotSystemAdmin)
+	assert.Nil(t, users)
+	mockRepo.AssertNotCalled(t, "GetAll", mock.Anything)
+}
+
+func TestUserService_GetByID_FullOnlyForSelfOrAdmin(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1, Email: "one@example.com"}, nil)
+	mockRepo.On("GetByID", mock.Anything, 2).Return(&models.User{ID: 2}, nil)
+	mockRepo.On("GetByID", mock.Anything, 3).Return(&models.User{ID: 3, IsAdmin: true}, nil)
+
+	// --- ACT ---
+	_, self, errSelf := userService.GetByID(context.Background(), 1, 1)
+	_, other, errOther := userService.GetByID(context.Background(), 2, 1)
+	_, admin, errAdmin := userService.GetByID(context.Background(), 3, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, errSelf)
+	assert.NoError(t, errOther)
+	assert.NoError(t, errAdmin)
+	assert.True(t, self)
+	assert.False(t, other)
+	assert.True(t, admin)
+}
+
+func TestUserService_GetByID_NotFound(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+	mockRepo.On("GetByID", mock.Anything, 5).Return(nil, sql.ErrNoRows)
+
+	_, _, err := userService.GetByID(context.Background(), 1, 5)
+
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserService_Update_OtherUserRequiresAdmin(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+	name := "Mallory"
+
+	mockRepo.On("GetByID", mock.Anything, 2).Return(&models.User{ID: 2}, nil)
+
+	// --- ACT ---
+	_, err := userService.Update(context.Background(), 2, 1, models.UserUpdate{Name: &name})
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrNotSystemAdmin)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUserService_Update_AdminFlag(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+	grant, revoke := true, false
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1}, nil)
+	mockRepo.On("GetByID", mock.Anything, 3).Return(&models.User{ID: 3, IsAdmin: true}, nil)
+	mockRepo.On("SetAdmin", mock.Anything, 1, true).Return(nil).Once()
+
+	// --- ACT ---
+	_, errSelfGrant := userService.Update(context.Background(), 1, 1, models.UserUpdate{IsAdmin: &grant})
+	_, errOwnRevoke := userService.Update(context.Background(), 3, 3, models.UserUpdate{IsAdmin: &revoke})
+	user, err := userService.Update(context.Background(), 3, 1, models.UserUpdate{IsAdmin: &grant})
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, errSelfGrant, ErrNotSystemAdmin)
+	assert.ErrorIs(t, errOwnRevoke, ErrOwnAdminFlag)
+	assert.NoError(t, err)
+	assert.True(t, user.IsAdmin)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUserService_Delete_RevokesSessionsAndDisconnectsFromBoards(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	mockTokenRepo := new(repository.MockRefreshTokenRepository)
+	mockDenylist := new(MockTokenDenylist)
+	mockBroadcaster := new(MockBroadcaster)
+	userService := newTestUserService(mockRepo, newTestTokenService(mockTokenRepo, mockDenylist), mockBroadcaster)
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1}, nil)
+	mockTokenRepo.On("RevokeAllForUser", mock.Anything, 1).Return([]string{"fam-1"}, nil).Once()
+	mockDenylist.On("RevokeFamily", mock.Anything, "fam-1", 15*time.Minute).Return(nil).Once()
+	mockRepo.On("Delete", mock.Anything, 1).Return(&models.UserDeletion{
+		TransferredBoards: []models.BoardTransfer{{BoardID: 7, NewOwnerID: 2}},
+		DeletedBoards:     []int{8},
+		MemberBoards:      []int{9},
+	}, nil).Once()
+	mockBroadcaster.On("BroadcastToBoard", 7, mock.Anything).Return().Once()
+	mockBroadcaster.On("DisconnectUser", 7, 1).Return().Once()
+	mockBroadcaster.On("BroadcastToBoard", 9, mock.Anything).Return().Once()
+	mockBroadcaster.On("DisconnectUser", 9, 1).Return().Once()
+
+	// --- ACT ---
+	deletion, err := userService.Delete(context.Background(), 1, 1)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8}, deletion.DeletedBoards)
+	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+	mockDenylist.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestUserService_Delete_OtherUserRequiresAdmin(t *testing.T) {
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+	mockRepo.On("GetByID", mock.Anything, 2).Return(&models.User{ID: 2}, nil)
+
+	_, err := userService.Delete(context.Background(), 2, 1)
+
+	assert.ErrorIs(t, err, ErrNotSystemAdmin)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestUserService_Register_ValidatesAllFields(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+	age := 200
+
+	// --- ACT ---
+	err := userService.Register(context.Background(), &models.User{Name: "  ", Age: &age, Email: "not-an-email", Password: "123"})
+
+	// --- ASSERT ---
+	var fieldErrs validation.Errors
+	assert.ErrorAs(t, err, &fieldErrs)
+	assert.Len(t, fieldErrs, 4)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUserService_Register_DuplicateEmail(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "alice@example.com" && u.Name == "Alice" && u.PasswordHash != ""
+	})).Return(repository.ErrDuplicateEmail).Once()
+
+	// --- ACT ---
+	err := userService.Register(context.Background(),
+		&models.User{Name: " Alice ", Email: " Alice@Example.com", Password: "correct horse"})
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrEmailTaken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Update_InvalidFieldsChangeNothing(t *testing.T) {
+	// --- ARRANGE ---
+	mockRepo := new(repository.MockUserRepository)
+	userService := newTestUserService(mockRepo, nil, nil)
+	grant, age := true, 0
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(&models.User{ID: 1}, nil)
+	mockRepo.On("GetByID", mock.Anything, 3).Return(&models.User{ID: 3, IsAdmin: true}, nil)
+
+	// --- ACT ---
+	_, err := userService.Update(context.Background(), 3, 1, models.UserUpdate{Age: &age, IsAdmin: &grant})
+
+	// --- ASSERT ---
+	var fieldErrs validation.Errors
+	assert.ErrorAs(t, err, &fieldErrs)
+	mockRepo.AssertNotCalled(t, "SetAdmin", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	webhookBatchSize      = 100
+	webhookConcurrency    = 8
+	webhookEnqueueTimeout = 5 * time.Second
+	webhookBaseBackoff    = 30 * time.Second
+	webhookMaxBackoff     = 6 * time.Hour
+	maxWebhookErrorLen    = 500
+)
+
+// Заголовки исходящего запроса. Подпись считается как HMAC-SHA256 секрета подписки
+// от строки "<timestamp>.<тело запроса>" и передаётся в виде "sha256=<hex>".
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// SignWebhookPayload возвращает значение заголовка X-Webhook-Signature. Метка времени
+// входит в подпись, чтобы получатель мог отбрасывать старые повторённые запросы.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookBroadcaster оборачивает Broadcaster: каждое событие доски уходит в WebSocket
+// как раньше и дополнительно ставится в очередь вебхуков этой доски.
+type WebhookBroadcaster struct {
+	next Broadcaster
+	repo repository.WebhookRepository
+}
+
+func NewWebhookBroadcaster(next Broadcaster, repo repository.WebhookRepository) *WebhookBroadcaster {
+	return &WebhookBroadcaster{next: next, repo: repo}
+}
+
+func (b *WebhookBroadcaster) BroadcastToBoard(boardID int, message []byte) {
+	b.next.BroadcastToBoard(boardID, message)
+
+	var envelope struct {
+		Event string `json:"event"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil || envelope.Event == "" {
+		log.Printf("Webhooks: skipping message without event for board %d", boardID)
+		return
+	}
+
+	// Контекст запроса здесь недоступен, а постановка в очередь не должна зависеть от того,
+	// успел ли клиент дождаться ответа.
+	ctx, cancel := context.WithTimeout(context.Background(), webhookEnqueueTimeout)
+	defer cancel()
+	if _, err := b.repo.EnqueueDeliveries(ctx, boardID, envelope.Event, message); err != nil {
+		log.Printf("Webhooks: could not enqueue %s for board %d: %v", envelope.Event, boardID, err)
+	}
+}
+
+func (b *WebhookBroadcaster) DisconnectUser(boardID, userID int) {
+	b.next.DisconnectUser(boardID, userID)
+}
+
+// WebhookDispatcher периодически забирает из очереди доставки, которым пора уходить, и отправляет их.
+// Неудачная попытка откладывается с экспоненциально растущей паузой; после maxAttempts попыток
+// доставка переходит в dead и больше не отправляется сама.
+type WebhookDispatcher struct {
+	repo        repository.WebhookRepository
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	now         func() time.Time
+}
+
+// NewWebhookDispatcher не следует редиректам: ответ 3xx считается неудачной попыткой.
+// Соединяться можно только с публичными адресами: проверка при сохранении вебхука не спасает
+// от DNS rebinding, поэтому адрес проверяется ещё раз уже после разрешения имени.
+func NewWebhookDispatcher(
+	repo repository.WebhookRepository,
+	timeout time.Duration,
+	interval time.Duration,
+	maxAttempts int) *WebhookDispatcher {
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+	return &WebhookDispatcher{
+		repo: repo,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		now:         time.Now,
+	}
+}
+
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !webhookAddrAllowed(addr) {
+		return fmt.Errorf("webhook address %s is not allowed", addr)
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.DeliverOnce(ctx)
+		}
+	}
+}
+
+// DeliverOnce отправляет одну пачку доставок. Аренда с запасом перекрывает таймаут запроса,
+// так что доставку, которую ещё отправляют, другой экземпляр не возьмёт.
+func (d *WebhookDispatcher) DeliverOnce(ctx context.Context) {
+	now := d.now()
+	deliveries, err := d.repo.ClaimDeliveries(ctx, now, now.Add(2*d.client.Timeout+time.Minute), webhookBatchSize)
+	if err != nil {
+		log.Printf("WebhookDispatcher: could not claim deliveries: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, webhookConcurrency)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery models.ClaimedWebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			attempt := d.attempt(ctx, delivery)
+			if err := d.repo.RecordAttempt(ctx, delivery.ID, attempt); err != nil {
+				log.Printf("WebhookDispatcher: could not record attempt for delivery %d: %v", delivery.ID, err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery models.ClaimedWebhookDelivery) models.WebhookAttempt {
+	statusCode, err := d.send(ctx, delivery)
+	at := d.now()
+	result := models.WebhookAttempt{At: at, NextAttemptAt: at}
+	if statusCode != 0 {
+		result.StatusCode = &statusCode
+	}
+	if err == nil {
+		result.Status = models.WebhookDeliveryDelivered
+		return result
+	}
+
+	message := err.Error()
+	if len(message) > maxWebhookErrorLen {
+		message = message[:maxWebhookErrorLen]
+	}
+	result.Error = &message
+
+	attempts := delivery.Attempts + 1
+	if attempts >= d.maxAttempts {
+		result.Status = models.WebhookDeliveryDead
+		log.Printf("WebhookDispatcher: delivery %d to webhook %d is dead after %d attempts: %s",
+			delivery.ID, delivery.WebhookID, attempts, message)
+		return result
+	}
+	result.Status = models.WebhookDeliveryPending
+	result.NextAttemptAt = at.Add(webhookBackoff(attempts))
+	return result
+}
+
+// send возвращает код ответа (0, если ответа не было) и ошибку, если доставка не удалась.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery models.ClaimedWebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid request: %w", err)
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notes-project-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Тело ответа не нужно, но дочитываем его, чтобы соединение вернулось в пул.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff - пауза перед попыткой номер attempts+1: 30s, 1m, 2m, ... но не больше 6h.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
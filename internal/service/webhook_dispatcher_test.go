@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testWebhookPayload = `{"event":"CARD_MOVED","payload":{"id":5}}`
+
+// newTestWebhookDispatcher снимает запрет на внутренние адреса: тестовый получатель слушает 127.0.0.1.
+func newTestWebhookDispatcher(repo repository.WebhookRepository, now time.Time) *WebhookDispatcher {
+	dispatcher := NewWebhookDispatcher(repo, time.Second, time.Minute, 3)
+	dispatcher.client.Transport = http.DefaultTransport
+	dispatcher.now = func() time.Time { return now }
+	return dispatcher
+}
+
+func claimedDelivery(url string, attempts int) models.ClaimedWebhookDelivery {
+	return models.ClaimedWebhookDelivery{
+		ID: 42, WebhookID: 7, Event: "CARD_MOVED", Payload: []byte(testWebhookPayload),
+		Attempts: attempts, URL: url, Secret: "receiver-secret-123",
+	}
+}
+
+func TestWebhookDispatcher_DeliverOnce_SignsAndDelivers(t *testing.T) {
+	// --- ARRANGE ---
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	dispatcher := newTestWebhookDispatcher(mockWebhookRepo, now)
+	mockWebhookRepo.On("ClaimDeliveries", mock.Anything, now, mock.Anything, webhookBatchSize).
+		Return([]models.ClaimedWebhookDelivery{claimedDelivery(receiver.URL, 0)}, nil).Once()
+	mockWebhookRepo.On("RecordAttempt", mock.Anything, int64(42), mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryDelivered && *a.StatusCode == http.StatusNoContent && a.Error == nil
+	})).Return(nil).Once()
+
+	// --- ACT ---
+	dispatcher.DeliverOnce(context.Background())
+
+	// --- ASSERT ---
+	mockWebhookRepo.AssertExpectations(t)
+	assert.Equal(t, testWebhookPayload, string(body))
+	assert.Equal(t, "CARD_MOVED", received.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "42", received.Header.Get(WebhookDeliveryHeader))
+
+	// Получатель проверяет подпись по своему экземпляру секрета.
+	timestamp, err := strconv.ParseInt(received.Header.Get(WebhookTimestampHeader), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Unix(), timestamp)
+	assert.Equal(t, SignWebhookPayload("receiver-secret-123", timestamp, body), received.Header.Get(WebhookSignatureHeader))
+	assert.NotEqual(t, SignWebhookPayload("other-secret-value", timestamp, body), received.Header.Get(WebhookSignatureHeader))
+}
+
+func TestWebhookDispatcher_DeliverOnce_RetriesWithBackoff(t *testing.T) {
+	// --- ARRANGE ---
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	dispatcher := newTestWebhookDispatcher(mockWebhookRepo, now)
+	mockWebhookRepo.On("ClaimDeliveries", mock.Anything, now, mock.Anything, webhookBatchSize).
+		Return([]models.ClaimedWebhookDelivery{claimedDelivery(receiver.URL, 1)}, nil).Once()
+	// Вторая неудачная попытка: следующая через 2 * 30s.
+	mockWebhookRepo.On("RecordAttempt", mock.Anything, int64(42), mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryPending && *a.StatusCode == http.StatusInternalServerError &&
+			a.Error != nil && a.NextAttemptAt.Equal(now.Add(time.Minute))
+	})).Return(nil).Once()
+
+	// --- ACT ---
+	dispatcher.DeliverOnce(context.Background())
+
+	// --- ASSERT ---
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_DeliverOnce_DeadLetter(t *testing.T) {
+	// --- ARRANGE ---
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	dispatcher := newTestWebhookDispatcher(mockWebhookRepo, now)
+	mockWebhookRepo.On("ClaimDeliveries", mock.Anything, now, mock.Anything, webhookBatchSize).
+		Return([]models.ClaimedWebhookDelivery{claimedDelivery(receiver.URL, 2)}, nil).Once()
+	// Третья попытка из трёх: редирект не выполняется, доставка уходит в dead.
+	mockWebhookRepo.On("RecordAttempt", mock.Anything, int64(42), mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryDead && *a.StatusCode == http.StatusFound
+	})).Return(nil).Once()
+
+	// --- ACT ---
+	dispatcher.DeliverOnce(context.Background())
+
+	// --- ASSERT ---
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_DeliverOnce_UnreachableReceiver(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	dispatcher := newTestWebhookDispatcher(mockWebhookRepo, now)
+	mockWebhookRepo.On("ClaimDeliveries", mock.Anything, now, mock.Anything, webhookBatchSize).
+		Return([]models.ClaimedWebhookDelivery{claimedDelivery(url, 0)}, nil).Once()
+	mockWebhookRepo.On("RecordAttempt", mock.Anything, int64(42), mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryPending && a.StatusCode == nil && a.Error != nil &&
+			a.NextAttemptAt.Equal(now.Add(webhookBaseBackoff))
+	})).Return(nil).Once()
+
+	dispatcher.DeliverOnce(context.Background())
+
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookDispatcher_DeliverOnce_RefusesInternalAddress(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	dispatcher := NewWebhookDispatcher(mockWebhookRepo, time.Second, time.Minute, 3)
+	dispatcher.now = func() time.Time { return now }
+	mockWebhookRepo.On("ClaimDeliveries", mock.Anything, now, mock.Anything, webhookBatchSize).
+		Return([]models.ClaimedWebhookDelivery{claimedDelivery(receiver.URL, 0)}, nil).Once()
+	mockWebhookRepo.On("RecordAttempt", mock.Anything, int64(42), mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryPending && a.StatusCode == nil && a.Error != nil
+	})).Return(nil).Once()
+
+	dispatcher.DeliverOnce(context.Background())
+
+	// Адрес проверяется при соединении, поэтому запрос не уходит, даже если вебхук уже сохранён.
+	assert.False(t, called)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{address: "203.0.113.10:443", allowed: true},
+		{address: "[2001:db8::1]:443", allowed: true},
+		{address: "127.0.0.1:80"},
+		{address: "10.0.0.5:80"},
+		{address: "169.254.169.254:80"},
+		{address: "0.1.2.3:80"},
+		{address: "100.64.0.1:80"},
+		{address: "192.0.0.170:80"},
+		{address: "198.19.255.1:80"},
+		{address: "240.0.0.1:80"},
+		{address: "[64:ff9b::a9fe:a9fe]:80"},
+		{address: "[::ffff:100.64.0.1]:80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := webhookDialControl("tcp", tt.address, nil)
+
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, 4*time.Minute, webhookBackoff(4))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(30))
+}
+
+func TestWebhookBroadcaster_ForwardsAndEnqueues(t *testing.T) {
+	// --- ARRANGE ---
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	mockBroadcaster := new(MockBroadcaster)
+	broadcaster := NewWebhookBroadcaster(mockBroadcaster, mockWebhookRepo)
+	message := []byte(testWebhookPayload)
+
+	mockBroadcaster.On("BroadcastToBoard", 1, message).Return().Once()
+	mockWebhookRepo.On("EnqueueDeliveries", mock.Anything, 1, "CARD_MOVED", message).Return(int64(2), nil).Once()
+
+	// --- ACT ---
+	broadcaster.BroadcastToBoard(1, message)
+
+	// --- ASSERT ---
+	mockBroadcaster.AssertExpectations(t)
+	mockWebhookRepo.AssertExpectations(t)
+}
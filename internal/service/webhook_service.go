@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"notes-project/internal/apperr"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const minWebhookSecretLen = 16
+
+// Имена событий совпадают с полем event сообщений WebSocket, например CARD_MOVED.
+var webhookEventPattern = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+
+var (
+	ErrWebhookNotFound  = apperr.NotFound("webhook_not_found", "webhook not found")
+	ErrDeliveryNotFound = apperr.NotFound("delivery_not_found", "delivery not found")
+)
+
+type WebhookService interface {
+	List(ctx context.Context, boardID, userID int) ([]models.Webhook, error)
+	Create(ctx context.Context, boardID, userID int, rawURL, secret string, events []string) (*models.Webhook, error)
+	Update(ctx context.Context, boardID, webhookID, userID int, rawURL *string, events *[]string, active *bool) (*models.Webhook, error)
+	Delete(ctx context.Context, boardID, webhookID, userID int) error
+	ListDeliveries(ctx context.Context, boardID, webhookID, userID int, cursor string, limit int) (*models.WebhookDeliveryPage, error)
+	Redeliver(ctx context.Context, boardID, webhookID, userID int, deliveryID int64) error
+}
+
+type webhookService struct {
+	repo        repository.WebhookRepository
+	permissions BoardPermissions
+	lookupIP    func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+func NewWebhookService(repo repository.WebhookRepository, permissions BoardPermissions) WebhookService {
+	return &webhookService{
+		repo:        repo,
+		permissions: permissions,
+		lookupIP: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+}
+
+// Вебхуки отправляют содержимое доски во внешние системы, поэтому управлять ими могут только администраторы.
+func (s *webhookService) List(ctx context.Context, boardID, userID int) ([]models.Webhook, error) {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	webhooks, err := s.repo.GetAllByBoardID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Create создаёт подписку. Если секрет не передан, он генерируется; в ответе секрет
+// возвращается один раз, дальше он нужен только получателю для проверки подписи.
+func (s *webhookService) Create(ctx context.Context, boardID, userID int, rawURL, secret string, events []string) (*models.Webhook, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(events)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(secret) < minWebhookSecretLen {
+		return nil, apperr.Validation("invalid_webhook_secret", "webhook secret must be at least %d characters", minWebhookSecretLen)
+	}
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := s.checkWebhookHost(ctx, rawURL); err != nil {
+		return nil, err
+	}
+
+	webhook := &models.Webhook{
+		BoardID:   boardID,
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedBy: &userID,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, boardID, webhookID, userID int, rawURL *string, events *[]string, active *bool) (*models.Webhook, error) {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	webhook, err := s.boardWebhook(ctx, boardID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawURL != nil {
+		webhook.URL = strings.TrimSpace(*rawURL)
+		if err := validateWebhookURL(webhook.URL); err != nil {
+			return nil, err
+		}
+		if err := s.checkWebhookHost(ctx, webhook.URL); err != nil {
+			return nil, err
+		}
+	}
+	if events != nil {
+		if webhook.Events, err = normalizeWebhookEvents(*events); err != nil {
+			return nil, err
+		}
+	}
+	if active != nil {
+		webhook.Active = *active
+	}
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		return nil, apperr.NoRows(err, ErrWebhookNotFound)
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, boardID, webhookID, userID int) error {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return err
+	}
+	if _, err := s.boardWebhook(ctx, boardID, webhookID); err != nil {
+		return err
+	}
+	return apperr.NoRows(s.repo.Delete(ctx, webhookID), ErrWebhookNotFound)
+}
+
+// ListDeliveries постранично отдаёт журнал доставок, от новых к старым. Курсор устроен так же,
+// как в журнале действий.
+func (s *webhookService) ListDeliveries(ctx context.Context, boardID, webhookID, userID int, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	beforeID, err := parseActivityCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if _, err := s.boardWebhook(ctx, boardID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.GetDeliveriesPage(ctx, webhookID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &models.WebhookDeliveryPage{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		next := strconv.FormatInt(page.Deliveries[limit-1].ID, 10)
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// Redeliver возвращает доставку в очередь, в том числе из состояния dead.
+func (s *webhookService) Redeliver(ctx context.Context, boardID, webhookID, userID int, deliveryID int64) error {
+	if _, err := s.permissions.Require(ctx, boardID, userID, models.RoleAdmin); err != nil {
+		return err
+	}
+	if _, err := s.boardWebhook(ctx, boardID, webhookID); err != nil {
+		return err
+	}
+	return apperr.NoRows(s.repo.Redeliver(ctx, webhookID, deliveryID), ErrDeliveryNotFound)
+}
+
+func (s *webhookService) boardWebhook(ctx context.Context, boardID, webhookID int) (*models.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, webhookID)
+	if err != nil {
+		return nil, apperr.NoRows(err, ErrWebhookNotFound)
+	}
+	if webhook.BoardID != boardID {
+		return nil, apperr.NotFound(ErrWebhookNotFound.Code, "webhook with id %d not found on board %d", webhookID, boardID)
+	}
+	return webhook, nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return apperr.Validation("invalid_webhook_url", "webhook url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// checkWebhookHost не даёт направить вебхук во внутреннюю сеть: все адреса хоста должны быть
+// публичными. Диспетчер повторяет проверку при соединении, см. webhookDialControl.
+func (s *webhookService) checkWebhookHost(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return apperr.Validation("invalid_webhook_url", "webhook url must be an absolute http or https URL")
+	}
+	host := parsed.Hostname()
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else if addrs, err = s.lookupIP(ctx, host); err != nil || len(addrs) == 0 {
+		return apperr.Validation("invalid_webhook_url", "webhook host %q could not be resolved", host)
+	}
+	for _, addr := range addrs {
+		if !webhookAddrAllowed(addr) {
+			return errForbiddenWebhookAddr
+		}
+	}
+	return nil
+}
+
+var errForbiddenWebhookAddr = apperr.Validation("forbidden_webhook_url",
+	"webhook url must not point to a loopback, private, link-local, reserved or multicast address")
+
+// deniedWebhookPrefixes - служебные диапазоны, которые netip не относит к частным, но которые
+// в облачных сетях часто ведут во внутреннюю инфраструктуру. NAT64 запрещён целиком, потому
+// что через него можно адресовать любой внутренний IPv4.
+var deniedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // «эта сеть»
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // служебные адреса IETF
+	netip.MustParsePrefix("198.18.0.0/15"),  // стенды для нагрузочного тестирования
+	netip.MustParsePrefix("240.0.0.0/4"),    // зарезервированные и широковещательный адрес
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // локальный NAT64
+}
+
+// webhookAddrAllowed отсекает адреса, через которые вебхук мог бы достучаться до внутренних
+// сервисов, включая метаданные облака на 169.254.169.254.
+func webhookAddrAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range deniedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// normalizeWebhookEvents убирает повторы; пустой список означает подписку на все события.
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	normalized := make([]string, 0, len(events))
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		event = strings.ToUpper(strings.TrimSpace(event))
+		if !webhookEventPattern.MatchString(event) {
+			return nil, apperr.Validation("invalid_webhook_event", "invalid webhook event %q", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	return normalized, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("could not generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
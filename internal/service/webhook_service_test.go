@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"notes-project/internal/models"
+	"notes-project/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestWebhookService разрешает любой хост в публичный адрес, чтобы тесты не ходили в DNS.
+func newTestWebhookService(repo repository.WebhookRepository, permissions BoardPermissions) WebhookService {
+	webhookService := NewWebhookService(repo, permissions).(*webhookService)
+	webhookService.lookupIP = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		if host == "internal.example.com" {
+			return []netip.Addr{netip.MustParseAddr("203.0.113.10"), netip.MustParseAddr("10.0.0.5")}, nil
+		}
+		if host == "cgnat.example.com" {
+			return []netip.Addr{netip.MustParseAddr("100.100.100.200")}, nil
+		}
+		return []netip.Addr{netip.MustParseAddr("203.0.113.10")}, nil
+	}
+	return webhookService
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	// --- ARRANGE ---
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	webhookService := newTestWebhookService(mockWebhookRepo, NewBoardPermissions(mockBoardRepo))
+
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil)
+	mockWebhookRepo.On("Create", mock.Anything, mock.MatchedBy(func(w *models.Webhook) bool {
+		return w.BoardID == 1 && w.Active && len(w.Secret) == 64 &&
+			assert.ObjectsAreEqual([]string{"CARD_MOVED", "CARD_CREATED"}, []string(w.Events))
+	})).Return(nil).Once()
+
+	// --- ACT ---
+	webhook, err := webhookService.Create(context.Background(), 1, 10, " https://ci.example.com/hook ", "",
+		[]string{"card_moved", "CARD_CREATED", "CARD_MOVED"})
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ci.example.com/hook", webhook.URL)
+	assert.NotEmpty(t, webhook.Secret)
+	mockWebhookRepo.AssertExpectations(t)
+}
+
+func TestWebhookService_Create_Validates(t *testing.T) {
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	webhookService := newTestWebhookService(mockWebhookRepo, NewBoardPermissions(new(repository.MockBoardRepository)))
+
+	_, err := webhookService.Create(context.Background(), 1, 10, "ftp://example.com", "", nil)
+	assert.ErrorContains(t, err, "http or https")
+
+	_, err = webhookService.Create(context.Background(), 1, 10, "https://example.com", "short", nil)
+	assert.ErrorContains(t, err, "secret")
+
+	_, err = webhookService.Create(context.Background(), 1, 10, "https://example.com", "", []string{"card moved"})
+	assert.ErrorContains(t, err, "invalid webhook event")
+
+	mockWebhookRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Create_RejectsInternalAddresses(t *testing.T) {
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	webhookService := newTestWebhookService(mockWebhookRepo, NewBoardPermissions(mockBoardRepo))
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleAdmin, nil)
+
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.1/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+		"http://224.0.0.1/hook",
+		"http://0.1.2.3/hook",
+		"http://100.64.0.1/hook",
+		"http://192.0.0.170/hook",
+		"http://198.18.0.1/hook",
+		"http://240.0.0.1/hook",
+		"http://255.255.255.255/hook",
+		"http://[64:ff9b::a00:1]/hook",
+		"https://internal.example.com/hook",
+		"https://cgnat.example.com/hook",
+	} {
+		_, err := webhookService.Create(context.Background(), 1, 10, rawURL, "", nil)
+		assert.ErrorIs(t, err, errForbiddenWebhookAddr, rawURL)
+	}
+
+	mockWebhookRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_Create_RequiresAdmin(t *testing.T) {
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	webhookService := newTestWebhookService(mockWebhookRepo, NewBoardPermissions(mockBoardRepo))
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleMember, nil)
+
+	_, err := webhookService.Create(context.Background(), 1, 10, "https://example.com", "", nil)
+
+	assert.Error(t, err)
+	mockWebhookRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestWebhookService_List_HidesSecrets(t *testing.T) {
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	webhookService := newTestWebhookService(mockWebhookRepo, NewBoardPermissions(mockBoardRepo))
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleOwner, nil)
+	mockWebhookRepo.On("GetAllByBoardID", mock.Anything, 1).
+		Return([]models.Webhook{{ID: 1, BoardID: 1, Secret: "top-secret-value-1"}}, nil)
+
+	webhooks, err := webhookService.List(context.Background(), 1, 10)
+
+	assert.NoError(t, err)
+	assert.Empty(t, webhooks[0].Secret)
+}
+
+func TestWebhookService_Redeliver_OtherBoard(t *testing.T) {
+	mockWebhookRepo := new(repository.MockWebhookRepository)
+	mockBoardRepo := new(repository.MockBoardRepository)
+	webhookService := newTestWebhookService(mockWebhookRepo, NewBoardPermissions(mockBoardRepo))
+	mockBoardRepo.On("GetMemberRole", mock.Anything, 1, 10).Return(models.RoleOwner, nil)
+	mockWebhookRepo.On("GetByID", mock.Anything, 5).Return(&models.Webhook{ID: 5, BoardID: 2}, nil)
+
+	err := webhookService.Redeliver(context.Background(), 1, 5, 10, 42)
+
+	assert.ErrorContains(t, err, "not found on board 1")
+	mockWebhookRepo.AssertNotCalled(t, "Redeliver", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_PutOpenDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "cards/1/abc", strings.NewReader("data"), 4, "text/plain"))
+
+	file, err := store.Open(ctx, "cards/1/abc")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	file.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	require.NoError(t, store.Delete(ctx, "cards/1/abc"))
+	_, err = store.Open(ctx, "cards/1/abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStore_KeyCannotEscapeRoot(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	require.NoError(t, err)
+
+	path, err := store.path("../../etc/passwd")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(path, root))
+}
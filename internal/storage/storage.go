@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound возвращается, если объекта с таким ключом нет в хранилище.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore хранит содержимое вложений. Реализации: LocalStore для разработки
+// и одиночных инсталляций, S3Store для S3-совместимых хранилищ (AWS, MinIO).
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open возвращает поток с поддержкой Seek, чтобы http.ServeContent мог отдавать Range-запросы.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
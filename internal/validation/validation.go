@@ -0,0 +1,115 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"notes-project/internal/apperr"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxEmailLength    = 254
+	MinPasswordLength = 8
+	// MaxPasswordLength - bcrypt учитывает только первые 72 байта, длиннее пароль не принимается.
+	MaxPasswordLength = 72
+	MaxNameLength     = 100
+	MinAge            = 1
+	MaxAge            = 150
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors - все ошибки одного запроса. Проверка не останавливается на первой ошибке,
+// чтобы клиент мог показать их сразу у всех полей.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fieldErr := range e {
+		parts[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Is относит ошибки валидации к виду apperr.ErrValidation.
+func (e Errors) Is(target error) bool {
+	return target == apperr.ErrValidation
+}
+
+type Validator struct {
+	errs Errors
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+func (v *Validator) Add(field, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: message})
+}
+
+// Err возвращает Errors, если что-то не прошло проверку, иначе nil.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// NormalizeEmail приводит адрес к виду, в котором он хранится и ищется: без пробелов по краям
+// и в нижнем регистре.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Email ожидает уже нормализованный адрес вида user@example.com, без имени и угловых скобок.
+func (v *Validator) Email(field, email string) {
+	if email == "" {
+		v.Add(field, "is required")
+		return
+	}
+	if len(email) > MaxEmailLength {
+		v.Add(field, fmt.Sprintf("must be at most %d characters long", MaxEmailLength))
+		return
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		v.Add(field, "must be a valid email address")
+	}
+}
+
+func (v *Validator) Password(field, password string) {
+	switch {
+	case len(password) < MinPasswordLength:
+		v.Add(field, fmt.Sprintf("must be at least %d characters long", MinPasswordLength))
+	case len(password) > MaxPasswordLength:
+		v.Add(field, fmt.Sprintf("must be at most %d bytes long", MaxPasswordLength))
+	case strings.TrimSpace(password) == "":
+		v.Add(field, "must not consist of whitespace only")
+	}
+}
+
+// Name ожидает значение без пробелов по краям. Длина считается в символах, а не в байтах.
+func (v *Validator) Name(field, name string) {
+	length := utf8.RuneCountInString(name)
+	switch {
+	case length == 0:
+		v.Add(field, "is required")
+	case length > MaxNameLength:
+		v.Add(field, fmt.Sprintf("must be at most %d characters long", MaxNameLength))
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		v.Add(field, "must not contain control characters")
+	}
+}
+
+// Age пропускает nil: возраст указывать необязательно.
+func (v *Validator) Age(field string, age *int) {
+	if age != nil && (*age < MinAge || *age > MaxAge) {
+		v.Add(field, fmt.Sprintf("must be between %d and %d", MinAge, MaxAge))
+	}
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "alice@example.com", NormalizeEmail("  Alice@Example.COM "))
+}
+
+func TestValidator_Email(t *testing.T) {
+	for email, ok := range map[string]bool{
+		"alice@example.com":               true,
+		"alice+boards@mail.example.co.uk": true,
+		"":                                false,
+		"alice":                           false,
+		"alice@localhost":                 false,
+		"Alice <alice@example.com>":       false,
+		"alice@@example.com":              false,
+		strings.Repeat("a", 250) + "@example.com": false,
+	} {
+		v := New()
+		v.Email("email", email)
+		assert.Equal(t, ok, v.Err() == nil, email)
+	}
+}
+
+func TestValidator_Password(t *testing.T) {
+	for password, ok := range map[string]bool{
+		"correct horse":         true,
+		"short":                 false,
+		"        ":              false,
+		strings.Repeat("x", 72): true,
+		strings.Repeat("x", 73): false,
+	} {
+		v := New()
+		v.Password("password", password)
+		assert.Equal(t, ok, v.Err() == nil, password)
+	}
+}
+
+func TestValidator_CollectsAllFieldErrors(t *testing.T) {
+	// --- ARRANGE ---
+	age := 0
+	v := New()
+
+	// --- ACT ---
+	v.Email("email", "nope")
+	v.Password("password", "1")
+	v.Name("name", "")
+	v.Age("age", &age)
+	v.Age("other_age", nil)
+	err := v.Err()
+
+	// --- ASSERT ---
+	var fieldErrs Errors
+	assert.True(t, errors.As(err, &fieldErrs))
+	fields := []string{}
+	for _, fieldErr := range fieldErrs {
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.Equal(t, []string{"email", "password", "name", "age"}, fields)
+}
+
+func TestValidator_Name_CountsRunes(t *testing.T) {
+	v := New()
+	v.Name("name", strings.Repeat("я", MaxNameLength))
+	assert.NoError(t, v.Err())
+
+	v.Name("name", strings.Repeat("я", MaxNameLength+1))
+	assert.Error(t, v.Err())
+}
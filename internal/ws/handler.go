@@ -3,8 +3,9 @@ package ws
 import (
 	"log"
 	"net/http"
-	"strconv"
 
+	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
 	"notes-project/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -37,17 +38,21 @@ func (h *WsHandler) RegisterWsRoutes(rg *gin.RouterGroup) {
 }
 
 func (h *WsHandler) ServeWs(c *gin.Context) {
-	userID, exists := c.Get("userId")
-	if !exists {
+	userID, err := reqctx.CurrentUser(c)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	boardID, err := strconv.Atoi(c.Param("boardId"))
+	boardID, err := reqctx.PathInt(c, "boardId")
 	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	_, err = h.boardService.GetByID(c.Request.Context(), boardID, userID.(int))
+	role, err := h.boardService.GetRole(c.Request.Context(), boardID, userID)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		c.Error(err)
 		return
 	}
 
@@ -58,9 +63,11 @@ func (h *WsHandler) ServeWs(c *gin.Context) {
 	}
 
 	client := &Client{
-		hub:  h.hub,
-		conn: conn,
-		send: make(chan []byte, 256),
+		hub:      h.hub,
+		conn:     conn,
+		send:     make(chan []byte, 256),
+		userID:   userID,
+		readOnly: role == models.RoleObserver,
 	}
 
 	h.hub.register <- &subscription{client: client, boardID: boardID}
@@ -94,5 +101,10 @@ func (c *Client) readPump(boardID int) {
 		if _, _, err := c.conn.ReadMessage(); err != nil {
 			break
 		}
+		// Наблюдатели подключаются только для чтения: попытка что-то отправить закрывает соединение.
+		if c.readOnly {
+			log.Printf("Read-only client of user %d sent a message to board %d, closing connection", c.userID, boardID)
+			break
+		}
 	}
 }
@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"notes-project/internal/handlers"
+	"notes-project/internal/models"
+	"notes-project/internal/reqctx"
+	"notes-project/internal/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBoardService struct {
+	service.BoardService
+	mock.Mock
+}
+
+func (m *MockBoardService) GetRole(ctx context.Context, boardID, userID int) (models.BoardRole, error) {
+	args := m.Called(ctx, boardID, userID)
+	return args.Get(0).(models.BoardRole), args.Error(1)
+}
+
+func serveWs(userID int, path string, boards service.BoardService) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(handlers.ErrorMiddleware())
+	r.Use(func(c *gin.Context) {
+		if userID != 0 {
+			reqctx.SetUser(c, &models.AccessClaims{UserID: userID})
+		}
+	})
+	NewWsHandler(nil, boards).RegisterWsRoutes(r.Group("/api"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestServeWs_RejectsBeforeUpgrade(t *testing.T) {
+	// --- ARRANGE ---
+	mockService := new(MockBoardService)
+	mockService.On("GetRole", mock.Anything, 2, 1).Return(models.BoardRole(""), service.ErrBoardAccessDenied)
+
+	// --- ACT & ASSERT ---
+	assert.Equal(t, http.StatusUnauthorized, serveWs(0, "/api/boards/1/ws", mockService))
+	assert.Equal(t, http.StatusBadRequest, serveWs(1, "/api/boards/abc/ws", mockService))
+	assert.Equal(t, http.StatusForbidden, serveWs(1, "/api/boards/2/ws", mockService))
+	mockService.AssertNumberOfCalls(t, "GetRole", 1)
+}
@@ -6,9 +6,11 @@ import (
 )
 
 type Client struct {
-	hub  *Hub
-	conn Conn
-	send chan []byte
+	hub      *Hub
+	conn     Conn
+	send     chan []byte
+	userID   int
+	readOnly bool
 }
 
 type Hub struct {
@@ -16,9 +18,15 @@ type Hub struct {
 	broadcast  chan broadcastMessage
 	register   chan *subscription
 	unregister chan *subscription
+	disconnect chan userOnBoard
 	mu         sync.Mutex
 }
 
+type userOnBoard struct {
+	boardID int
+	userID  int
+}
+
 type broadcastMessage struct {
 	boardID int
 	message []byte
@@ -35,6 +43,7 @@ func NewHub() *Hub {
 		broadcast:  make(chan broadcastMessage),
 		register:   make(chan *subscription),
 		unregister: make(chan *subscription),
+		disconnect: make(chan userOnBoard),
 	}
 }
 
@@ -64,6 +73,22 @@ func (h *Hub) Run() {
 			h.mu.Unlock()
 			log.Printf("Client unregistered from board %d", sub.boardID)
 
+		case target := <-h.disconnect:
+			h.mu.Lock()
+			if clients, ok := h.clients[target.boardID]; ok {
+				for client := range clients {
+					if client.userID == target.userID {
+						close(client.send)
+						delete(clients, client)
+					}
+				}
+				if len(clients) == 0 {
+					delete(h.clients, target.boardID)
+				}
+			}
+			h.mu.Unlock()
+			log.Printf("User %d disconnected from board %d", target.userID, target.boardID)
+
 		case msg := <-h.broadcast:
 			h.mu.Lock()
 			if clients, ok := h.clients[msg.boardID]; ok {
@@ -87,3 +112,7 @@ func (h *Hub) BroadcastToBoard(boardID int, message []byte) {
 		message: message,
 	}
 }
+
+func (h *Hub) DisconnectUser(boardID, userID int) {
+	h.disconnect <- userOnBoard{boardID: boardID, userID: userID}
+}